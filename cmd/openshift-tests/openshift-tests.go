@@ -75,7 +75,9 @@ func main() {
 		newImagesCommand(),
 		newRunTestCommand(),
 		newRunMonitorCommand(),
+		newReplayInvariantsCommand(),
 		newTestFailureRiskAnalysisCommand(),
+		newMergeResultsCommand(),
 		cmd.NewRunResourceWatchCommand(),
 		monitor_cmd.NewTimelineCommand(genericclioptions.IOStreams{
 			In:     os.Stdin,
@@ -133,6 +135,55 @@ func newRunMonitorCommand() *cobra.Command {
 	return cmd
 }
 
+func newReplayInvariantsCommand() *cobra.Command {
+	replayOpt := &testginkgo.ReplayInvariantsOptions{
+		Out:    os.Stdout,
+		ErrOut: os.Stderr,
+	}
+	suites := append(staticSuites.TestSuites(), upgradeSuites.TestSuites()...)
+
+	cmd := &cobra.Command{
+		Use:   "replay-invariants SUITE",
+		Short: "Rerun the invariants of a suite against recorded artifacts",
+		Long: templates.LongDesc(`
+		Rerun the synthetic tests of a suite against the artifacts of an earlier run
+
+		This command loads the events and resources recorded by the monitor during a run of the
+		suite and evaluates the suite's invariants against them without a cluster. Invariants that
+		need to read from the cluster are skipped, and invariants that depend on the platform or
+		job type report that the platform could not be identified.
+
+		Pass --recalculate-intervals when replaying an event journal, which does not contain the
+		intervals calculated at the end of a run.
+
+		`) + testginkgo.SuitesString(suites, "\n\nAvailable test suites:\n\n"),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			suite, err := (&testginkgo.Options{ErrOut: os.Stderr}).SelectSuite(suites, args)
+			if err != nil {
+				return err
+			}
+			return replayOpt.Run(suite)
+		},
+	}
+	cmd.Flags().StringVar(&replayOpt.EventsFilename,
+		"events", replayOpt.EventsFilename,
+		"An e2e-events or e2e-events-journal file written by a previous run.")
+	cmd.MarkFlagRequired("events")
+	cmd.Flags().StringSliceVar(&replayOpt.ResourceFilenames,
+		"resources", replayOpt.ResourceFilenames,
+		"The resource-*.zip files written by a previous run.")
+	cmd.Flags().BoolVar(&replayOpt.RecalculateIntervals,
+		"recalculate-intervals", replayOpt.RecalculateIntervals,
+		"Calculate the derived intervals from the events and resources before evaluating the invariants.")
+	cmd.Flags().StringVar(&replayOpt.JUnitDir,
+		"junit-dir", replayOpt.JUnitDir,
+		"The directory to write the JUnit results to.")
+	return cmd
+}
+
 const sippyDefaultURL = "https://sippy.dptools.openshift.org/api/jobs/runs/risk_analysis"
 
 func newTestFailureRiskAnalysisCommand() *cobra.Command {
@@ -167,6 +218,38 @@ The resulting analysis is then also written to the junit artifacts directory.
 	return cmd
 }
 
+func newMergeResultsCommand() *cobra.Command {
+	mergeOpt := &testginkgo.MergeResultsOptions{
+		Out:    os.Stdout,
+		ErrOut: os.Stderr,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "merge-results DIR...",
+		Short: "Merge the results of the shards of a suite",
+		Long: templates.LongDesc(`
+		Merge the results of the shards of a suite into one suite result
+
+		A suite run with --shard=INDEX/COUNT writes the results of only its share of the tests.
+		This command reads the junit_e2e_*.xml and test-failures-summary files from the
+		--junit-dir of every shard and writes a single JUnit result and failure summary to
+		--junit-dir, which must be a different directory.
+
+		`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return mergeOpt.Run(args)
+		},
+	}
+	cmd.Flags().StringVar(&mergeOpt.JUnitDir,
+		"junit-dir", mergeOpt.JUnitDir,
+		"The directory to write the merged results to.")
+	cmd.MarkFlagRequired("junit-dir")
+	return cmd
+}
+
 type imagesOptions struct {
 	Repository string
 	Upstream   bool
@@ -527,7 +610,15 @@ func bindTestOptions(opt *testginkgo.Options, flags *pflag.FlagSet) {
 	flags.StringVarP(&opt.OutFile, "output-file", "o", opt.OutFile, "Write all test output to this file.")
 	flags.IntVar(&opt.Count, "count", opt.Count, "Run each test a specified number of times. Defaults to 1 or the suite's preferred value. -1 will run forever.")
 	flags.BoolVar(&opt.FailFast, "fail-fast", opt.FailFast, "If a test fails, exit immediately.")
+	flags.BoolVar(&opt.Resume, "resume", opt.Resume, "Skip the tests that finished in an interrupted run with the same --junit-dir, and include their results in the reports of this run.  The cluster is only monitored from the start of this run, so the timelines and invariants do not cover the interrupted run, whose events are left in its e2e-events-journal file.")
 	flags.DurationVar(&opt.Timeout, "timeout", opt.Timeout, "Set the maximum time a test can run before being aborted. This is read from the suite by default, but will be 10 minutes otherwise.")
 	flags.BoolVar(&opt.IncludeSuccessOutput, "include-success", opt.IncludeSuccessOutput, "Print output from successful tests.")
 	flags.IntVar(&opt.Parallelism, "max-parallel-tests", opt.Parallelism, "Maximum number of tests running in parallel. 0 defaults to test suite recommended value, which is different in each suite.")
+	flags.StringVar(&opt.Shard, "shard", opt.Shard, "Run only the tests of one shard, as INDEX/COUNT like 2/5, to split a suite across processes.  Tests are split by the hash of their name, or evenly by time with --test-durations.  Combine the results with merge-results.")
+	flags.StringVar(&opt.TestDurationsFile, "test-durations", opt.TestDurationsFile, "JUnit XML of an earlier run, a directory of them, or JSON mapping test names to seconds.  The longest tests start first, and the predicted and actual time of the tests is reported.")
+	flags.StringSliceVar(&opt.MonitorEventsOptions.RecorderSelectors, "monitor", opt.MonitorEventsOptions.RecorderSelectors, fmt.Sprintf("Monitors to enable (name) or disable (-name) on top of the suite's defaults, '*' and '-*' toggle every monitor. Available monitors: %s.", strings.Join(opt.MonitorEventsOptions.Recorders.Names(), ", ")))
+	flags.StringSliceVar(&opt.MonitorEventsOptions.TrackedResources, "monitor-resource", opt.MonitorEventsOptions.TrackedResources, "Additional resources, as resource.version.group (deployments.v1.apps), whose creates, deletes, spec changes, status.conditions changes and final state are recorded by the monitor.")
+	flags.StringSliceVar(&opt.MonitorEventsOptions.TimelineDefinitions, "timeline-definitions", opt.MonitorEventsOptions.TimelineDefinitions, "YAML files, or directories of them, defining more timelines to write to the artifact directory.  See the timeline command.")
+	flags.StringVar(&opt.OTLPTraceFile, "otlp-file", opt.OTLPTraceFile, "Write the run, its tests and notable monitor intervals as an OTLP/JSON trace to this file.")
+	flags.StringVar(&opt.OTLPEndpoint, "otlp-endpoint", opt.OTLPEndpoint, "Send the run as a trace to this OTLP/HTTP collector, for instance http://localhost:4318.")
 }
@@ -373,6 +373,9 @@ func CreateEventIntervalsForAlerts(ctx context.Context, alerts prometheustypes.V
 				Condition: monitorapi.Condition{
 					Locator: string(locator),
 					Message: alert.Metric.String(),
+					StructuredMessage: monitorapi.NewMessage(alert.Metric.String()).
+						WithAnnotation(monitorapi.AnnotationAlertState, string(alert.Metric["alertstate"])).
+						WithAnnotation(monitorapi.AnnotationSeverity, string(alert.Metric["severity"])),
 				},
 			}
 			switch {
@@ -7,13 +7,11 @@ import (
 	"sync"
 	"time"
 
-	configclientset "github.com/openshift/client-go/config/clientset/versioned"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
-	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
@@ -30,33 +28,47 @@ func GetMonitorRESTConfig() (*rest.Config, error) {
 }
 
 // Start begins monitoring the cluster referenced by the default kube configuration until
-// context is finished.
+// context is finished.  The recorders in NewDefaultRecorderRegistry are started along with
+// additionalEventIntervalRecorders.
 func Start(ctx context.Context, restConfig *rest.Config, additionalEventIntervalRecorders []StartEventIntervalRecorderFunc) (*Monitor, error) {
 	m := NewMonitorWithInterval(time.Second)
-	client, err := kubernetes.NewForConfig(restConfig)
-	if err != nil {
+	if err := StartRecorders(ctx, m, restConfig, withDefaultRecorders(additionalEventIntervalRecorders)); err != nil {
 		return nil, err
 	}
-	configClient, err := configclientset.NewForConfig(restConfig)
-	if err != nil {
+	return m, nil
+}
+
+// StartWithJournal is like Start, but recorded intervals are appended to journal as they happen instead of being
+// held in memory until the end of the run.
+func StartWithJournal(ctx context.Context, restConfig *rest.Config, journal EventJournal, additionalEventIntervalRecorders []StartEventIntervalRecorderFunc) (*Monitor, error) {
+	m := NewMonitorWithJournal(time.Second, journal)
+	if err := StartRecorders(ctx, m, restConfig, withDefaultRecorders(additionalEventIntervalRecorders)); err != nil {
 		return nil, err
 	}
+	return m, nil
+}
 
-	for _, additionalEventIntervalRecorder := range additionalEventIntervalRecorders {
-		if err := additionalEventIntervalRecorder(ctx, m, restConfig); err != nil {
-			return nil, err
+// StartRecorders starts exactly the provided recorders against m, followed by sampling.  Use a RecorderRegistry to
+// choose them by name.
+func StartRecorders(ctx context.Context, m *Monitor, restConfig *rest.Config, recorders []StartEventIntervalRecorderFunc) error {
+	for _, recorder := range recorders {
+		if err := recorder(ctx, m, restConfig); err != nil {
+			return err
 		}
 	}
 
-	startPodMonitoring(ctx, m, client)
-	startNodeMonitoring(ctx, m, client)
-	startEventMonitoring(ctx, m, client)
-
-	// add interval creation at the same point where we add the monitors
-	startClusterOperatorMonitoring(ctx, m, configClient)
-
 	m.StartSampling(ctx)
-	return m, nil
+	return nil
+}
+
+func withDefaultRecorders(additionalEventIntervalRecorders []StartEventIntervalRecorderFunc) []StartEventIntervalRecorderFunc {
+	registry := NewDefaultRecorderRegistry()
+	// the default registry only holds recorders that are enabled by default
+	recorders, err := registry.Recorders(registry.Names())
+	if err != nil {
+		panic(err)
+	}
+	return append(append([]StartEventIntervalRecorderFunc{}, additionalEventIntervalRecorders...), recorders...)
 }
 
 func findContainerStatus(status []corev1.ContainerStatus, name string, position int) *corev1.ContainerStatus {
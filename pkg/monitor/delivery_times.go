@@ -0,0 +1,172 @@
+package monitor
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// deliveryTimes records when a watch delivered every object, so the handler of the object can report how long the
+// object waited for it.  Objects from the initial list, or handed out again by a resync, were not delivered by a watch
+// and have no lag.  A nil deliveryTimes records nothing.
+type deliveryTimes struct {
+	lock sync.Mutex
+	// delivered is keyed by UID, then resource version
+	delivered map[types.UID]map[string]time.Time
+}
+
+func newDeliveryTimes() *deliveryTimes {
+	return &deliveryTimes{
+		delivered: map[types.UID]map[string]time.Time{},
+	}
+}
+
+// metadataOf returns the metadata of obj, or of the object a tombstone stands for.
+func metadataOf(obj interface{}) (metav1.Object, bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	metadata, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, false
+	}
+	return metadata, true
+}
+
+// wrap returns a ListerWatcher whose watches record when they deliver each object.  The watches are read as objects
+// arrive, so a handler that falls behind shows up as lag instead of holding up the watch.
+func (d *deliveryTimes) wrap(lw cache.ListerWatcher) cache.ListerWatcher {
+	if d == nil {
+		return lw
+	}
+	return &deliveryTimingListWatcher{ListerWatcher: lw, times: d}
+}
+
+// lag returns how long ago a watch delivered obj, and forgets it.
+func (d *deliveryTimes) lag(obj interface{}) time.Duration {
+	if d == nil {
+		return 0
+	}
+	metadata, ok := metadataOf(obj)
+	if !ok {
+		return 0
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	versions := d.delivered[metadata.GetUID()]
+	delivered, ok := versions[metadata.GetResourceVersion()]
+	if !ok {
+		return 0
+	}
+	delete(versions, metadata.GetResourceVersion())
+	if len(versions) == 0 {
+		delete(d.delivered, metadata.GetUID())
+	}
+	return time.Since(delivered)
+}
+
+// forget drops every delivery of a deleted object, including those its handler never asked about.
+func (d *deliveryTimes) forget(obj interface{}) {
+	if d == nil {
+		return
+	}
+	metadata, ok := metadataOf(obj)
+	if !ok {
+		return
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(d.delivered, metadata.GetUID())
+}
+
+func (d *deliveryTimes) record(now time.Time, obj runtime.Object) {
+	metadata, ok := metadataOf(obj)
+	if !ok {
+		return
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	versions, ok := d.delivered[metadata.GetUID()]
+	if !ok {
+		versions = map[string]time.Time{}
+		d.delivered[metadata.GetUID()] = versions
+	}
+	versions[metadata.GetResourceVersion()] = now
+}
+
+type deliveryTimingListWatcher struct {
+	cache.ListerWatcher
+	times *deliveryTimes
+}
+
+func (w *deliveryTimingListWatcher) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	source, err := w.ListerWatcher.Watch(options)
+	if err != nil {
+		return nil, err
+	}
+	timed := &deliveryTimingWatch{
+		source: source,
+		times:  w.times,
+		result: make(chan watch.Event),
+		stopCh: make(chan struct{}),
+	}
+	go timed.run()
+	return timed, nil
+}
+
+// deliveryTimingWatch reads every event from source as it arrives, and queues it until it is read from the result.
+type deliveryTimingWatch struct {
+	source   watch.Interface
+	times    *deliveryTimes
+	result   chan watch.Event
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+func (w *deliveryTimingWatch) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		w.source.Stop()
+	})
+}
+
+func (w *deliveryTimingWatch) ResultChan() <-chan watch.Event {
+	return w.result
+}
+
+func (w *deliveryTimingWatch) run() {
+	defer close(w.result)
+	in := w.source.ResultChan()
+	var pending []watch.Event
+	for in != nil || len(pending) > 0 {
+		var out chan<- watch.Event
+		var next watch.Event
+		if len(pending) > 0 {
+			out = w.result
+			next = pending[0]
+		}
+		select {
+		case event, ok := <-in:
+			if !ok {
+				in = nil
+				continue
+			}
+			switch event.Type {
+			case watch.Added, watch.Modified, watch.Deleted:
+				w.times.record(time.Now(), event.Object)
+			}
+			pending = append(pending, event)
+		case out <- next:
+			pending[0] = watch.Event{}
+			pending = pending[1:]
+		case <-w.stopCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newDeliveredPod(uid, resourceVersion string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: types.UID(uid), ResourceVersion: resourceVersion}}
+}
+
+func TestDeliveryTimes(t *testing.T) {
+	source := watch.NewFake()
+	listed := newDeliveredPod("a", "1")
+	delivered := newDeliveryTimes()
+	lw := delivered.wrap(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return &corev1.PodList{Items: []corev1.Pod{*listed}}, nil
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return source, nil
+		},
+	})
+	w, err := lw.Watch(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the watch is read while nothing handles its events
+	source.Modify(newDeliveredPod("a", "2"))
+	source.Delete(newDeliveredPod("a", "3"))
+	time.Sleep(50 * time.Millisecond)
+
+	first := (<-w.ResultChan()).Object
+	if lag := delivered.lag(first); lag < 50*time.Millisecond {
+		t.Errorf("expected the lag of the first event to cover the wait, got %s", lag)
+	}
+	if lag := delivered.lag(first); lag != 0 {
+		t.Errorf("expected an event to be timed once, got %s", lag)
+	}
+	if event := <-w.ResultChan(); event.Type != watch.Deleted || delivered.lag(event.Object) == 0 {
+		t.Errorf("expected the delete to be timed, got %v", event)
+	}
+	if lag := delivered.lag(listed); lag != 0 {
+		t.Errorf("expected no lag for a listed object, got %s", lag)
+	}
+
+	// a deleted object drops the deliveries no handler asked about, even when it comes as a tombstone
+	source.Modify(newDeliveredPod("a", "4"))
+	source.Modify(newDeliveredPod("a", "5"))
+	<-w.ResultChan()
+	<-w.ResultChan()
+	delivered.forget(cache.DeletedFinalStateUnknown{Key: "pod", Obj: newDeliveredPod("a", "5")})
+	if len(delivered.delivered) != 0 {
+		t.Errorf("expected the deleted object to be forgotten, got %v", delivered.delivered)
+	}
+
+	w.Stop()
+	if _, ok := <-w.ResultChan(); ok {
+		t.Errorf("expected the result to be closed when the watch stops")
+	}
+	if lag := (*deliveryTimes)(nil).lag(listed); lag != 0 {
+		t.Errorf("expected no lag without delivery times, got %s", lag)
+	}
+}
@@ -26,6 +26,9 @@ func startEventMonitoring(ctx context.Context, m Recorder, client kubernetes.Int
 	// map event UIDs to the last resource version we observed, used to skip recording resources
 	// we've already recorded.
 	processedEventUIDs := map[types.UID]string{}
+	// lag is timed from the watch delivering an event rather than from its timestamps, which say when it first
+	// happened and are kept when only the count changes
+	delivered := newDeliveryTimes()
 
 	listWatch := cache.NewListWatchFromClient(client.CoreV1().RESTClient(), "events", "", fields.Everything())
 	customStore := &cache.FakeCustomStore{
@@ -49,9 +52,12 @@ func startEventMonitoring(ctx context.Context, m Recorder, client kubernetes.Int
 			if !ok {
 				return nil
 			}
+			lag := delivered.lag(event)
 			if processedEventUIDs[event.UID] != event.ResourceVersion {
-				recordAddOrUpdateEvent(ctx, m, client, reMatchFirstQuote, significantlyBeforeNow, event)
+				recordAddOrUpdateEvent(ctx, m, client, reMatchFirstQuote, significantlyBeforeNow, event, lag)
 				processedEventUIDs[event.UID] = event.ResourceVersion
+			} else {
+				recordNotification(m, "events", NotificationDuplicate, 0)
 			}
 			return nil
 		},
@@ -60,14 +66,22 @@ func startEventMonitoring(ctx context.Context, m Recorder, client kubernetes.Int
 			if !ok {
 				return nil
 			}
+			lag := delivered.lag(event)
 			if processedEventUIDs[event.UID] != event.ResourceVersion {
-				recordAddOrUpdateEvent(ctx, m, client, reMatchFirstQuote, significantlyBeforeNow, event)
+				recordAddOrUpdateEvent(ctx, m, client, reMatchFirstQuote, significantlyBeforeNow, event, lag)
 				processedEventUIDs[event.UID] = event.ResourceVersion
+			} else {
+				recordNotification(m, "events", NotificationDuplicate, 0)
 			}
 			return nil
 		},
+		// events are only recorded as they are created and updated, but their deliveries are still dropped
+		DeleteFunc: func(obj interface{}) error {
+			delivered.forget(obj)
+			return nil
+		},
 	}
-	reflector := cache.NewReflector(listWatch, &corev1.Event{}, customStore, 0)
+	reflector := cache.NewReflector(delivered.wrap(listWatch), &corev1.Event{}, customStore, 0)
 	go reflector.Run(ctx.Done())
 }
 
@@ -77,7 +91,8 @@ func recordAddOrUpdateEvent(
 	client kubernetes.Interface,
 	reMatchFirstQuote *regexp.Regexp,
 	significantlyBeforeNow time.Time,
-	obj *corev1.Event) {
+	obj *corev1.Event,
+	lag time.Duration) {
 
 	m.RecordResource("events", obj)
 
@@ -101,6 +116,7 @@ func recordAddOrUpdateEvent(
 		t = obj.CreationTimestamp.Time
 	}
 	if t.Before(significantlyBeforeNow) {
+		recordNotification(m, "events", NotificationDropped, 0)
 		if osEvent {
 			fmt.Printf("OS update event filtered for being too old: %s - %s - %s (now: %s)\n",
 				obj.Reason, obj.InvolvedObject.Name, obj.LastTimestamp.Format(time.RFC3339),
@@ -158,6 +174,7 @@ func recordAddOrUpdateEvent(
 	if obj.Type == corev1.EventTypeWarning {
 		condition.Level = monitorapi.Warning
 	}
+	recordNotification(m, "events", NotificationHandled, lag)
 	m.RecordAt(t, condition)
 
 }
@@ -0,0 +1,97 @@
+package intervalcreation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// nodeConditionsWithIntervals are the node conditions the node monitor already builds intervals for, by sampling
+// whether every node is ready.
+var nodeConditionsWithIntervals = sets.NewString("Ready")
+
+// IntervalsFromEvents_ConditionChanges builds an interval for every span of time a status.conditions entry was
+// abnormal (see monitorapi.IsAbnormalCondition), from the condition changes recorded for nodes and for the resources
+// the monitor was asked to track.  Clusteroperators have their own intervals, see
+// IntervalsFromEvents_OperatorAvailable, and so does the Ready condition of nodes.
+func IntervalsFromEvents_ConditionChanges(intervals monitorapi.Intervals, _ monitorapi.ResourcesMap, beginning, end time.Time) monitorapi.Intervals {
+	type abnormalCondition struct {
+		locator string
+		status  string
+		reason  string
+		message string
+		from    time.Time
+	}
+	ret := monitorapi.Intervals{}
+	toInterval := func(conditionType string, condition abnormalCondition, to time.Time) monitorapi.EventInterval {
+		message := fmt.Sprintf("condition/%s status/%s: %s", conditionType, condition.status, condition.message)
+		if len(condition.reason) > 0 {
+			message = fmt.Sprintf("condition/%s status/%s reason/%s: %s", conditionType, condition.status, condition.reason, condition.message)
+		}
+		return monitorapi.EventInterval{
+			Condition: monitorapi.Condition{
+				Level:   monitorapi.Warning,
+				Locator: condition.locator,
+				Message: message,
+			},
+			From: condition.from,
+			To:   to,
+		}
+	}
+
+	// keyed by locator, then condition type
+	open := map[string]map[string]abnormalCondition{}
+	// keep the output in the order conditions were first seen
+	order := []string{}
+	for _, event := range intervals {
+		// condition changes are instants, anything longer was calculated
+		if !event.To.IsZero() && !event.To.Equal(event.From) {
+			continue
+		}
+		if _, ok := monitorapi.OperatorFromLocator(event.Locator); ok {
+			continue
+		}
+		currentCondition := monitorapi.GetOperatorConditionStatus(event.Message)
+		if currentCondition == nil || len(currentCondition.Type) == 0 || len(currentCondition.Status) == 0 {
+			continue
+		}
+		conditionType, status := string(currentCondition.Type), string(currentCondition.Status)
+		if _, ok := monitorapi.NodeFromLocator(event.Locator); ok && nodeConditionsWithIntervals.Has(conditionType) {
+			continue
+		}
+
+		byType, ok := open[event.Locator]
+		if !ok {
+			byType = map[string]abnormalCondition{}
+			open[event.Locator] = byType
+			order = append(order, event.Locator)
+		}
+		last, wasAbnormal := byType[conditionType]
+		if wasAbnormal {
+			if last.status == status {
+				// only the reason changed, the condition is still abnormal
+				continue
+			}
+			ret = append(ret, toInterval(conditionType, last, event.From))
+			delete(byType, conditionType)
+		}
+		if monitorapi.IsAbnormalCondition(conditionType, status) {
+			byType[conditionType] = abnormalCondition{
+				locator: event.Locator,
+				status:  status,
+				reason:  currentCondition.Reason,
+				message: currentCondition.Message,
+				from:    event.From,
+			}
+		}
+	}
+
+	for _, locator := range order {
+		for _, conditionType := range sets.StringKeySet(open[locator]).List() {
+			ret = append(ret, toInterval(conditionType, open[locator][conditionType], end))
+		}
+	}
+	return ret
+}
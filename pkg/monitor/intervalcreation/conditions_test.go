@@ -0,0 +1,94 @@
+package intervalcreation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestIntervalsFromEvents_ConditionChanges(t *testing.T) {
+	change := func(locator, message, at string) monitorapi.EventInterval {
+		return monitorapi.EventInterval{
+			Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: locator, Message: message},
+			From:      timeFor(at),
+			To:        timeFor(at),
+		}
+	}
+	const pool = "machineconfigpool/worker uid/a"
+	intervals := monitorapi.Intervals{
+		change(pool, "condition/Updated status/True reason/AllUpdated changed: ", "2022-01-01T00:00:00Z"),
+		change(pool, "condition/Updating status/False changed: ", "2022-01-01T00:00:00Z"),
+		change(pool, "condition/Updated status/False changed: ", "2022-01-01T00:01:00Z"),
+		change(pool, "condition/Updating status/True reason/Rolling changed: rolling out rendered-worker-2", "2022-01-01T00:01:00Z"),
+		change(pool, "condition/Updated status/True changed: ", "2022-01-01T00:05:00Z"),
+		change(pool, "condition/Degraded status/True reason/NodeDegraded changed: node worker-0 is degraded", "2022-01-01T00:06:00Z"),
+		// the readiness of nodes has its own intervals
+		change("node/worker-0", "condition/Ready status/False reason/KubeletNotReady roles/worker changed", "2022-01-01T00:02:00Z"),
+		change("node/worker-0", "condition/Ready status/True reason/KubeletReady roles/worker changed", "2022-01-01T00:03:00Z"),
+		change("node/worker-0", "condition/MemoryPressure status/True reason/KubeletHasInsufficientMemory roles/worker changed", "2022-01-01T00:02:00Z"),
+		change("node/worker-0", "condition/MemoryPressure status/False reason/KubeletHasSufficientMemory roles/worker changed", "2022-01-01T00:04:00Z"),
+		// clusteroperators have their own intervals
+		change("clusteroperator/machine-config", "condition/Degraded status/True changed: ", "2022-01-01T00:02:00Z"),
+		// calculated intervals are ignored
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Warning, Locator: pool, Message: "condition/Updated status/False: "},
+			From:      timeFor("2022-01-01T00:01:00Z"),
+			To:        timeFor("2022-01-01T00:05:00Z"),
+		},
+	}
+
+	got := IntervalsFromEvents_ConditionChanges(intervals, nil, timeFor("2022-01-01T00:00:00Z"), timeFor("2022-01-01T00:10:00Z"))
+	var gotStrings []string
+	for _, interval := range got {
+		gotStrings = append(gotStrings, interval.String())
+	}
+	want := monitorapi.Intervals{
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Warning, Locator: pool, Message: "condition/Updated status/False: "},
+			From:      timeFor("2022-01-01T00:01:00Z"),
+			To:        timeFor("2022-01-01T00:05:00Z"),
+		},
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Warning, Locator: "node/worker-0", Message: "condition/MemoryPressure status/True reason/KubeletHasInsufficientMemory: "},
+			From:      timeFor("2022-01-01T00:02:00Z"),
+			To:        timeFor("2022-01-01T00:04:00Z"),
+		},
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Warning, Locator: pool, Message: "condition/Degraded status/True reason/NodeDegraded: node worker-0 is degraded"},
+			From:      timeFor("2022-01-01T00:06:00Z"),
+			To:        timeFor("2022-01-01T00:10:00Z"),
+		},
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Warning, Locator: pool, Message: "condition/Updating status/True reason/Rolling: rolling out rendered-worker-2"},
+			From:      timeFor("2022-01-01T00:01:00Z"),
+			To:        timeFor("2022-01-01T00:10:00Z"),
+		},
+	}
+	var wantStrings []string
+	for _, interval := range want {
+		wantStrings = append(wantStrings, interval.String())
+	}
+	if !reflect.DeepEqual(gotStrings, wantStrings) {
+		t.Errorf("expected\n%v\ngot\n%v", wantStrings, gotStrings)
+	}
+}
+
+func TestIntervalsFromEvents_ConditionChangesOfHealthyDeployment(t *testing.T) {
+	const deployment = "deployment/console ns/openshift-console uid/b"
+	intervals := monitorapi.Intervals{}
+	for _, message := range []string{
+		"condition/Available status/True reason/MinimumReplicasAvailable changed: Deployment has minimum availability.",
+		`condition/Progressing status/True reason/NewReplicaSetAvailable changed: ReplicaSet "console-1" has successfully progressed.`,
+	} {
+		intervals = append(intervals, monitorapi.EventInterval{
+			Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: deployment, Message: message},
+			From:      timeFor("2022-01-01T00:00:00Z"),
+			To:        timeFor("2022-01-01T00:00:00Z"),
+		})
+	}
+
+	if got := IntervalsFromEvents_ConditionChanges(intervals, nil, timeFor("2022-01-01T00:00:00Z"), timeFor("2022-01-01T00:10:00Z")); len(got) != 0 {
+		t.Errorf("expected no intervals for a healthy deployment, got %v", got)
+	}
+}
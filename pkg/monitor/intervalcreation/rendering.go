@@ -14,6 +14,47 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
+// TimelineRenderer writes timelines of a run.  TimelineNames are the names of those timelines, a name ending in *
+// stands for every name with that prefix.
+type TimelineRenderer interface {
+	WriteRunData(artifactDir string, recordedResources monitorapi.ResourcesMap, events monitorapi.Intervals, timeSuffix string) error
+	TimelineNames() []string
+}
+
+// NewDefaultTimelineRenderers returns the renderers of the timelines written for every run.
+func NewDefaultTimelineRenderers() []TimelineRenderer {
+	return []TimelineRenderer{
+		// these produce the various intervals.  Different intervals focused on inspecting different problem spaces.
+		NewSpyglassEventIntervalRenderer("everything", BelongsInEverything),
+		NewSpyglassEventIntervalRenderer("spyglass", BelongsInSpyglass),
+		// TODO add visualization of individual apiserver containers and their readiness on this page
+		NewSpyglassEventIntervalRenderer("kube-apiserver", BelongsInKubeAPIServer),
+		NewSpyglassEventIntervalRenderer("operators", BelongsInOperatorRollout),
+		NewPodEventIntervalRenderer(),
+		NewIngressServicePodIntervalRenderer(),
+		NewNodeEventIntervalRenderer(),
+		NewTimelineViewerRenderer("everything", BelongsInEverything),
+	}
+}
+
+// isTimelineNameTaken returns true if name is one of the TimelineNames of renderers.
+func isTimelineNameTaken(name string, renderers []TimelineRenderer) bool {
+	for _, renderer := range renderers {
+		for _, taken := range renderer.TimelineNames() {
+			if prefix := strings.TrimSuffix(taken, "*"); prefix != taken {
+				if strings.HasPrefix(name, prefix) {
+					return true
+				}
+				continue
+			}
+			if name == taken {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 type filenameBaseFunc func(timeSuffix string) string
 
 type eventIntervalRenderer struct {
@@ -42,6 +83,10 @@ func NewNonSpyglassEventIntervalRenderer(name string, filter monitorapi.EventInt
 	}
 }
 
+func (r eventIntervalRenderer) TimelineNames() []string {
+	return []string{r.name}
+}
+
 func (r eventIntervalRenderer) WriteRunData(artifactDir string, _ monitorapi.ResourcesMap, events monitorapi.Intervals, timeSuffix string) error {
 	filenameBase := r.filenameBaseFn(timeSuffix)
 	return r.writeEventData(artifactDir, filenameBase, events, timeSuffix)
@@ -49,7 +94,8 @@ func (r eventIntervalRenderer) WriteRunData(artifactDir string, _ monitorapi.Res
 
 func (r eventIntervalRenderer) writeEventData(artifactDir, filenameBase string, events monitorapi.Intervals, timeSuffix string) error {
 	errs := []error{}
-	interestingEvents := events.Filter(r.filter)
+	// upgrade phases are drawn behind every timeline
+	interestingEvents := events.Filter(monitorapi.Or(r.filter, monitorapi.IsUpgradePhase))
 
 	if err := monitorserialization.EventsIntervalsToFile(filepath.Join(artifactDir, fmt.Sprintf("%s.json", filenameBase)), interestingEvents); err != nil {
 		errs = append(errs, err)
@@ -126,12 +172,12 @@ func BelongsInKubeAPIServer(eventInterval monitorapi.EventInterval) bool {
 }
 
 func IsPodLifecycle(eventInterval monitorapi.EventInterval) bool {
-	return strings.Contains(eventInterval.Message, "constructed/true")
+	return monitorapi.IsConstructed(eventInterval)
 }
 
 func IsOriginalPodEvent(eventInterval monitorapi.EventInterval) bool {
 	// constructed events are not original
-	if strings.Contains(eventInterval.Message, "constructed/true") {
+	if monitorapi.IsConstructed(eventInterval) {
 		return false
 	}
 	return strings.Contains(eventInterval.Locator, "pod/")
@@ -139,7 +185,7 @@ func IsOriginalPodEvent(eventInterval monitorapi.EventInterval) bool {
 
 func isPlatformPodEvent(eventInterval monitorapi.EventInterval) bool {
 	// only include pod events that were created in CreatePodIntervalsFromInstants
-	if !strings.Contains(eventInterval.Message, "constructed/true") {
+	if !monitorapi.IsConstructed(eventInterval) {
 		return false
 	}
 	pod := monitorapi.PodFrom(eventInterval.Locator)
@@ -147,8 +193,7 @@ func isPlatformPodEvent(eventInterval monitorapi.EventInterval) bool {
 		return false
 	}
 
-	locatorParts := monitorapi.LocatorParts(eventInterval.Locator)
-	namespace := monitorapi.NamespaceFrom(locatorParts)
+	namespace := monitorapi.NamespaceFrom(monitorapi.LocatorFrom(eventInterval))
 	if strings.HasPrefix(namespace, "openshift-") {
 		return true
 	}
@@ -174,14 +219,12 @@ var kubeAPIServerDependentNamespaces = sets.NewString(
 )
 
 func isInterestingNamespace(eventInterval monitorapi.EventInterval, interestingNamespaces sets.String) bool {
-	locatorParts := monitorapi.LocatorParts(eventInterval.Locator)
-	namespace := monitorapi.NamespaceFrom(locatorParts)
+	namespace := monitorapi.NamespaceFrom(monitorapi.LocatorFrom(eventInterval))
 	return interestingNamespaces.Has(namespace)
 }
 
 func isLessInterestingAlert(eventInterval monitorapi.EventInterval) bool {
-	locatorParts := monitorapi.LocatorParts(eventInterval.Locator)
-	alertName := monitorapi.AlertFrom(locatorParts)
+	alertName := monitorapi.AlertFrom(monitorapi.LocatorFrom(eventInterval))
 	if len(alertName) == 0 {
 		return false
 	}
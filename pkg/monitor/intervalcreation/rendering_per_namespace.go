@@ -90,6 +90,11 @@ func wellKnownNamespaceGroups() []relatedNamespaces {
 	}
 }
 
+const (
+	e2eNamespacesTimeline  = "e2e-namespaces"
+	everythingElseTimeline = "everything-else"
+)
+
 type podRendering struct {
 	name string
 }
@@ -98,6 +103,14 @@ func NewPodEventIntervalRenderer() podRendering {
 	return podRendering{}
 }
 
+func (r podRendering) TimelineNames() []string {
+	names := []string{}
+	for _, group := range wellKnownNamespaceGroups() {
+		names = append(names, group.name)
+	}
+	return append(names, e2eNamespacesTimeline, everythingElseTimeline)
+}
+
 func (r podRendering) WriteRunData(artifactDir string, _ monitorapi.ResourcesMap, events monitorapi.Intervals, timeSuffix string) error {
 	allNamespaces := sets.NewString()
 	for _, interval := range events {
@@ -106,10 +119,10 @@ func (r podRendering) WriteRunData(artifactDir string, _ monitorapi.ResourcesMap
 
 	namespaceGroups := wellKnownNamespaceGroups()
 	e2eNamespaces := relatedNamespaces{
-		name: "e2e-namespaces", namespaces: sets.String{},
+		name: e2eNamespacesTimeline, namespaces: sets.String{},
 	}
 	allTheOtherNamespaces := relatedNamespaces{
-		name: "everything-else", namespaces: sets.String{},
+		name: everythingElseTimeline, namespaces: sets.String{},
 	}
 	for _, namespace := range allNamespaces.List() {
 		collected := false
@@ -150,6 +163,8 @@ func (r podRendering) WriteRunData(artifactDir string, _ monitorapi.ResourcesMap
 	return utilerrors.NewAggregate(errs)
 }
 
+const ingressServicePodTimeline = "image-reg-console-oauth"
+
 // ingressServicePodRendering type is used for rendering intervals for services that use the
 // router-default pods found in the openshift-ingress namespace.  This includes image-registry,
 // console, and oauth pods.
@@ -161,6 +176,10 @@ func NewIngressServicePodIntervalRenderer() ingressServicePodRendering {
 	return ingressServicePodRendering{}
 }
 
+func (r ingressServicePodRendering) TimelineNames() []string {
+	return []string{ingressServicePodTimeline}
+}
+
 // WriteEventData for ingressServicePodRendering writes out a custom spyglass chart to help debug TRT-364 and BZ2101622 where
 // image-registry, console, and oauth pods were experiencing disruption during upgrades.  We wanted one chart that
 // showed those pods, router-default pods, node changes, and disruption.
@@ -170,7 +189,7 @@ func (r ingressServicePodRendering) WriteRunData(artifactDir string, _ monitorap
 		backenddisruption.DisruptionEndedEventReason,
 		backenddisruption.DisruptionSamplerOutageBeganEventReason)
 	relevantNamespaces := sets.NewString("openshift-authentication", "openshift-console", "openshift-image-registry", "openshift-ingress", "openshift-ovn-kubernetes")
-	writer := NewNonSpyglassEventIntervalRenderer("image-reg-console-oauth",
+	writer := NewNonSpyglassEventIntervalRenderer(ingressServicePodTimeline,
 		func(eventInterval monitorapi.EventInterval) bool {
 			switch {
 			case isInterestingNamespace(eventInterval, relevantNamespaces):
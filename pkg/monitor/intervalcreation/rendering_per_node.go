@@ -0,0 +1,84 @@
+package intervalcreation
+
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	corev1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// nodeTimelinePrefix starts the names of the timelines written for every node.
+const nodeTimelinePrefix = "node-"
+
+// nodeRendering type is used for rendering a timeline per node, so a node level incident during an upgrade can be
+// read in one chart: the node condition and update intervals (including drain and reboot), the intervals from the
+// kubelet log and everything about the pods that ran on the node.
+type nodeRendering struct {
+}
+
+func NewNodeEventIntervalRenderer() nodeRendering {
+	return nodeRendering{}
+}
+
+func (r nodeRendering) TimelineNames() []string {
+	return []string{nodeTimelinePrefix + "*"}
+}
+
+// WriteRunData for nodeRendering writes e2e-timelines_node-<node><suffix> for every node that has intervals.
+func (r nodeRendering) WriteRunData(artifactDir string, recordedResources monitorapi.ResourcesMap, events monitorapi.Intervals, timeSuffix string) error {
+	podToNode := podNodes(recordedResources, events)
+	allNodes := sets.NewString()
+	for _, interval := range events {
+		if node, ok := monitorapi.NodeFromLocator(interval.Locator); ok {
+			allNodes.Insert(node)
+		}
+	}
+
+	errs := []error{}
+	for _, node := range allNodes.List() {
+		writer := NewNonSpyglassEventIntervalRenderer(nodeTimelinePrefix+node, IsOnNode(node, podToNode))
+		if err := writer.WriteRunData(artifactDir, nil, events, timeSuffix); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// IsOnNode matches the intervals of the node and of the pods in podToNode that ran on it.  Intervals from the
+// kubelet log locate the pod without its node, podToNode fills that in.
+func IsOnNode(node string, podToNode map[string]string) monitorapi.EventIntervalMatchesFunc {
+	return func(eventInterval monitorapi.EventInterval) bool {
+		locator := monitorapi.LocatorFrom(eventInterval)
+		// pods are located with an empty node until they are scheduled
+		if curr := locator.Get(monitorapi.LocatorNodeKey); len(curr) > 0 {
+			return curr == node
+		}
+		if !locator.Has(monitorapi.LocatorPodKey) {
+			return false
+		}
+		return podToNode[monitorapi.NonUniquePodLocatorFrom(eventInterval.Locator)] == node
+	}
+}
+
+// podNodes maps the non-unique locator of every pod (see monitorapi.NonUniquePodLocatorFrom) to the node it ran on,
+// from the recorded pods and from the intervals that locate a pod with its node.
+func podNodes(recordedResources monitorapi.ResourcesMap, events monitorapi.Intervals) map[string]string {
+	ret := map[string]string{}
+	for _, obj := range recordedResources["pods"] {
+		pod, ok := obj.(*corev1.Pod)
+		if !ok || len(pod.Spec.NodeName) == 0 {
+			continue
+		}
+		ret[monitorapi.NonUniquePodLocatorFrom(monitorapi.LocatePod(pod))] = pod.Spec.NodeName
+	}
+	for _, interval := range events {
+		locator := monitorapi.LocatorFrom(interval)
+		node := locator.Get(monitorapi.LocatorNodeKey)
+		if len(node) == 0 || !locator.Has(monitorapi.LocatorPodKey) {
+			continue
+		}
+		ret[monitorapi.NonUniquePodLocatorFrom(interval.Locator)] = node
+	}
+	return ret
+}
@@ -0,0 +1,84 @@
+package intervalcreation
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeRendering(t *testing.T) {
+	events := monitorapi.Intervals{
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "node/master-0", Message: "reason/NodeUpdate phase/Drain roles/master drained node"},
+			From:      timeFor("2022-03-01T10:00:00Z"), To: timeFor("2022-03-01T10:05:00Z"),
+		},
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Warning, Locator: "node/master-1", Message: "condition/Ready status/False reason/KubeletNotReady"},
+			From:      timeFor("2022-03-01T10:01:00Z"), To: timeFor("2022-03-01T10:01:30Z"),
+		},
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "ns/openshift-etcd pod/etcd-master-0 node/master-0 uid/a", Message: "reason/NotReady"},
+			From:      timeFor("2022-03-01T10:02:00Z"), To: timeFor("2022-03-01T10:02:30Z"),
+		},
+		{
+			// from the kubelet log, which does not name the node
+			Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "ns/openshift-etcd pod/etcd-master-0 uid/a container/etcd", Message: "reason/ReadinessFailed probe failed"},
+			From:      timeFor("2022-03-01T10:03:00Z"), To: timeFor("2022-03-01T10:03:30Z"),
+		},
+		{
+			// only the recorded pod knows where this one ran
+			Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "ns/openshift-dns pod/dns-default-x uid/b container/dns", Message: "reason/ReadinessFailed probe failed"},
+			From:      timeFor("2022-03-01T10:04:00Z"), To: timeFor("2022-03-01T10:04:10Z"),
+		},
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Error, Locator: "disruption/kube-api connection/new", Message: "stopped responding"},
+			From:      timeFor("2022-03-01T10:04:00Z"), To: timeFor("2022-03-01T10:04:30Z"),
+		},
+	}
+	recordedResources := monitorapi.ResourcesMap{
+		"pods": monitorapi.InstanceMap{
+			{Namespace: "openshift-dns", Name: "dns-default-x", UID: "b"}: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-dns", Name: "dns-default-x", UID: "b"},
+				Spec:       corev1.PodSpec{NodeName: "master-1"},
+			},
+		},
+	}
+
+	artifactDir, err := ioutil.TempDir("", "node-rendering")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(artifactDir)
+	if err := NewNodeEventIntervalRenderer().WriteRunData(artifactDir, recordedResources, events, "_20220301"); err != nil {
+		t.Fatal(err)
+	}
+
+	for node, expected := range map[string][]string{
+		"master-0": {"node/master-0", "ns/openshift-etcd pod/etcd-master-0 node/master-0 uid/a", "ns/openshift-etcd pod/etcd-master-0 uid/a container/etcd"},
+		"master-1": {"node/master-1", "ns/openshift-dns pod/dns-default-x uid/b container/dns"},
+	} {
+		for _, extension := range []string{".json", ".html"} {
+			if _, err := os.Stat(filepath.Join(artifactDir, "e2e-timelines_node-"+node+"_20220301"+extension)); err != nil {
+				t.Error(err)
+			}
+		}
+		written, err := monitorserialization.EventsFromFile(filepath.Join(artifactDir, "e2e-timelines_node-"+node+"_20220301.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual := []string{}
+		for _, interval := range written {
+			actual = append(actual, interval.Locator)
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: expected %v, got %v", node, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package intervalcreation
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// TimelineDefinition is a named timeline described in YAML, so a team can ship its own timeline views alongside the
+// binary instead of adding a filter here.  For instance
+//
+//	name: etcd
+//	filter:
+//	  locator:
+//	    ns: ["openshift-etcd", "openshift-etcd-operator"]
+//	    pod: ["-installer"]
+//	  levels: [Warning, Error]
+//	  messages: ["probe", "leader"]
+//	groupBy: node
+//	sortBy: duration
+//
+// GroupBy and SortBy lay out the rows of the timeline viewer.  The other renderers order intervals by time.
+type TimelineDefinition struct {
+	// Name names the timeline for timeline --type and the files written for a run.
+	Name   string         `json:"name"`
+	Filter TimelineFilter `json:"filter,omitempty"`
+	// GroupBy groups the rows by namespace, node, operator, type (the locator type) or any other locator key.
+	GroupBy string `json:"groupBy,omitempty"`
+	// SortBy orders the rows of a group by from (the first interval, the default), locator, duration (longest first)
+	// or level (most severe first).
+	SortBy string `json:"sortBy,omitempty"`
+}
+
+// TimelineFilter chooses the intervals of a timeline.  An interval must match every field that is set.
+type TimelineFilter struct {
+	// Locator maps a locator key to regular expressions, and the value of that key must match at least one of them.
+	// A regular expression that starts with a dash must not match, as for timeline --locator.
+	Locator map[string][]string `json:"locator,omitempty"`
+	// Levels are the levels to include.
+	Levels []string `json:"levels,omitempty"`
+	// Messages are regular expressions, and the message must match at least one of them.
+	Messages []string `json:"messages,omitempty"`
+	// ExcludeMessages are regular expressions the message must not match.
+	ExcludeMessages []string `json:"excludeMessages,omitempty"`
+	// Query is a filter expression, as for timeline --query.
+	Query string `json:"query,omitempty"`
+}
+
+var timelineSortOrders = sets.NewString("", "from", "locator", "duration", "level")
+
+// Matcher returns the filter of the timeline.
+func (d TimelineDefinition) Matcher() (monitorapi.EventIntervalMatchesFunc, error) {
+	filters := []monitorapi.EventIntervalMatchesFunc{}
+
+	locatorMatcher := map[string][]*regexp.Regexp{}
+	inverseLocatorMatcher := map[string][]*regexp.Regexp{}
+	for key, values := range d.Filter.Locator {
+		for _, value := range values {
+			inverse := strings.HasPrefix(value, "-")
+			regExp, err := regexp.Compile(strings.TrimPrefix(value, "-"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid locator %s: %w", key, err)
+			}
+			if inverse {
+				inverseLocatorMatcher[key] = append(inverseLocatorMatcher[key], regExp)
+			} else {
+				locatorMatcher[key] = append(locatorMatcher[key], regExp)
+			}
+		}
+	}
+	if len(locatorMatcher) > 0 {
+		filters = append(filters, monitorapi.ContainsAllParts(locatorMatcher))
+	}
+	if len(inverseLocatorMatcher) > 0 {
+		filters = append(filters, monitorapi.NotContainsAllParts(inverseLocatorMatcher))
+	}
+
+	if len(d.Filter.Levels) > 0 {
+		levels := map[monitorapi.EventLevel]bool{}
+		for _, level := range d.Filter.Levels {
+			eventLevel, err := monitorapi.EventLevelFromString(level)
+			if err != nil {
+				return nil, err
+			}
+			levels[eventLevel] = true
+		}
+		filters = append(filters, func(eventInterval monitorapi.EventInterval) bool {
+			return levels[eventInterval.Level]
+		})
+	}
+
+	messages, err := compileAll(d.Filter.Messages)
+	if err != nil {
+		return nil, fmt.Errorf("invalid messages: %w", err)
+	}
+	if len(messages) > 0 {
+		filters = append(filters, func(eventInterval monitorapi.EventInterval) bool {
+			return matchesAny(messages, eventInterval.Message)
+		})
+	}
+	excludeMessages, err := compileAll(d.Filter.ExcludeMessages)
+	if err != nil {
+		return nil, fmt.Errorf("invalid excludeMessages: %w", err)
+	}
+	if len(excludeMessages) > 0 {
+		filters = append(filters, func(eventInterval monitorapi.EventInterval) bool {
+			return !matchesAny(excludeMessages, eventInterval.Message)
+		})
+	}
+
+	if len(d.Filter.Query) > 0 {
+		query, err := monitorapi.ParseQuery(d.Filter.Query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query: %w", err)
+		}
+		filters = append(filters, query)
+	}
+
+	return monitorapi.And(filters...), nil
+}
+
+// Validate returns an error if the timeline has no name, an invalid filter or an unknown sort order.
+func (d TimelineDefinition) Validate() error {
+	if len(d.Name) == 0 {
+		return fmt.Errorf("missing name")
+	}
+	if strings.ContainsAny(d.Name, `/\ `) {
+		return fmt.Errorf("timeline %q: the name is used in file names and cannot contain slashes or spaces", d.Name)
+	}
+	if !timelineSortOrders.Has(d.SortBy) {
+		return fmt.Errorf("timeline %q: unknown sortBy %q, must be one of %s", d.Name, d.SortBy, strings.Join(timelineSortOrders.List()[1:], ","))
+	}
+	if _, err := d.Matcher(); err != nil {
+		return fmt.Errorf("timeline %q: %w", d.Name, err)
+	}
+	return nil
+}
+
+// ViewerOptions returns the layout of the timeline in the viewer.
+func (d TimelineDefinition) ViewerOptions() TimelineViewerOptions {
+	return TimelineViewerOptions{
+		GroupBy: d.GroupBy,
+		SortBy:  d.SortBy,
+	}
+}
+
+// LoadTimelineDefinitions reads the timelines in the YAML or JSON files at paths.  A file may hold several timelines
+// as separate documents, and every .yaml, .yml and .json file in a directory is read.  Names must be unique, and must
+// not be those of the timelines of NewDefaultTimelineRenderers, whose files they would overwrite.
+func LoadTimelineDefinitions(paths ...string) ([]TimelineDefinition, error) {
+	files := []string{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			switch filepath.Ext(entry.Name()) {
+			case ".yaml", ".yml", ".json":
+				if !entry.IsDir() {
+					files = append(files, filepath.Join(path, entry.Name()))
+				}
+			}
+		}
+	}
+
+	ret := []TimelineDefinition{}
+	errs := []error{}
+	seen := sets.NewString()
+	builtin := NewDefaultTimelineRenderers()
+	for _, file := range files {
+		definitions, err := readTimelineDefinitions(file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+		for _, definition := range definitions {
+			if err := definition.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", file, err))
+				continue
+			}
+			if seen.Has(definition.Name) {
+				errs = append(errs, fmt.Errorf("%s: timeline %q is defined more than once", file, definition.Name))
+				continue
+			}
+			if isTimelineNameTaken(definition.Name, builtin) {
+				errs = append(errs, fmt.Errorf("%s: timeline %q would replace a built-in timeline", file, definition.Name))
+				continue
+			}
+			seen.Insert(definition.Name)
+			ret = append(ret, definition)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+	return ret, nil
+}
+
+func readTimelineDefinitions(file string) ([]TimelineDefinition, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ret := []TimelineDefinition{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		definition := TimelineDefinition{}
+		if err := decoder.Decode(&definition); err != nil {
+			if err == io.EOF {
+				return ret, nil
+			}
+			return nil, err
+		}
+		// skip empty documents
+		if reflect.DeepEqual(definition, TimelineDefinition{}) {
+			continue
+		}
+		ret = append(ret, definition)
+	}
+}
+
+func compileAll(expressions []string) ([]*regexp.Regexp, error) {
+	ret := []*regexp.Regexp{}
+	for _, expression := range expressions {
+		regExp, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, regExp)
+	}
+	return ret, nil
+}
+
+func matchesAny(regExps []*regexp.Regexp, value string) bool {
+	for _, regExp := range regExps {
+		if regExp.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+type timelineDefinitionRenderer struct {
+	definition TimelineDefinition
+}
+
+// NewTimelineDefinitionRenderer writes the timeline of definition as e2e-timelines_<name><suffix> and
+// e2e-timeline-viewer_<name><suffix>.html.
+func NewTimelineDefinitionRenderer(definition TimelineDefinition) timelineDefinitionRenderer {
+	return timelineDefinitionRenderer{
+		definition: definition,
+	}
+}
+
+func (r timelineDefinitionRenderer) TimelineNames() []string {
+	return []string{r.definition.Name}
+}
+
+func (r timelineDefinitionRenderer) WriteRunData(artifactDir string, recordedResources monitorapi.ResourcesMap, events monitorapi.Intervals, timeSuffix string) error {
+	filter, err := r.definition.Matcher()
+	if err != nil {
+		return fmt.Errorf("timeline %q: %w", r.definition.Name, err)
+	}
+	viewer := NewTimelineViewerRenderer(r.definition.Name, filter)
+	viewer.options = r.definition.ViewerOptions()
+	return utilerrors.NewAggregate([]error{
+		NewSpyglassEventIntervalRenderer(r.definition.Name, filter).WriteRunData(artifactDir, recordedResources, events, timeSuffix),
+		viewer.WriteRunData(artifactDir, recordedResources, events, timeSuffix),
+	})
+}
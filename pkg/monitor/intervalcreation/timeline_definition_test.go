@@ -0,0 +1,153 @@
+package intervalcreation
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestLoadTimelineDefinitions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "timeline-definitions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, content string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("etcd.yaml", `
+name: etcd
+filter:
+  locator:
+    ns: ["openshift-etcd$"]
+    pod: ["-installer"]
+  levels: [Warning, Error]
+  messages: ["probe", "leader"]
+  excludeMessages: ["Startup"]
+groupBy: node
+sortBy: duration
+---
+name: slow
+filter:
+  query: duration>1m
+`)
+	write("README.md", "not a timeline")
+
+	definitions, err := LoadTimelineDefinitions(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(definitions) != 2 || definitions[0].Name != "etcd" || definitions[1].Name != "slow" {
+		t.Fatalf("unexpected definitions %#v", definitions)
+	}
+	if expected := (TimelineViewerOptions{GroupBy: "node", SortBy: "duration"}); definitions[0].ViewerOptions() != expected {
+		t.Errorf("expected %#v, got %#v", expected, definitions[0].ViewerOptions())
+	}
+
+	events := monitorapi.Intervals{
+		{Condition: monitorapi.Condition{Level: monitorapi.Warning, Locator: "ns/openshift-etcd pod/etcd-a node/a", Message: "reason/Unhealthy readiness probe failed"}},
+		{Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "ns/openshift-etcd pod/etcd-a node/a", Message: "reason/Unhealthy readiness probe failed"}},
+		{Condition: monitorapi.Condition{Level: monitorapi.Warning, Locator: "ns/openshift-etcd pod/installer-3-a node/a", Message: "reason/Unhealthy readiness probe failed"}},
+		{Condition: monitorapi.Condition{Level: monitorapi.Warning, Locator: "ns/openshift-etcd-operator pod/etcd-operator-x", Message: "reason/Unhealthy readiness probe failed"}},
+		{Condition: monitorapi.Condition{Level: monitorapi.Warning, Locator: "ns/openshift-etcd pod/etcd-a node/a", Message: "reason/Unhealthy Startup probe failed"}},
+		{Condition: monitorapi.Condition{Level: monitorapi.Error, Locator: "ns/openshift-etcd pod/etcd-a node/a", Message: "reason/LeaderElection became leader"}},
+		{Condition: monitorapi.Condition{Level: monitorapi.Error, Locator: "ns/openshift-etcd pod/etcd-a node/a", Message: "reason/LeaderElection leader"}},
+	}
+	filter, err := definitions[0].Matcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := events.Filter(filter)
+	if expected := (monitorapi.Intervals{events[0], events[5], events[6]}); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestLoadTimelineDefinitionsRejectsTakenNames(t *testing.T) {
+	dir, err := ioutil.TempDir("", "timeline-definitions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, content := range map[string]string{
+		"a.yaml": "name: etcd\n---\nname: everything\n---\nname: node-master-0\n---\nname: e2e-namespaces\n",
+		"b.yaml": "name: etcd\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err = LoadTimelineDefinitions(dir)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, expected := range []string{`timeline "etcd" is defined more than once`, `timeline "everything" would replace a built-in timeline`, `timeline "node-master-0" would replace a built-in timeline`, `timeline "e2e-namespaces" would replace a built-in timeline`} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q in %v", expected, err)
+		}
+	}
+}
+
+func TestTimelineDefinitionValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition TimelineDefinition
+		expected   string
+	}{
+		{name: "missing name", definition: TimelineDefinition{}, expected: "missing name"},
+		{name: "slash", definition: TimelineDefinition{Name: "a/b"}, expected: "cannot contain slashes"},
+		{name: "sort", definition: TimelineDefinition{Name: "a", SortBy: "size"}, expected: `unknown sortBy "size"`},
+		{name: "level", definition: TimelineDefinition{Name: "a", Filter: TimelineFilter{Levels: []string{"Fatal"}}}, expected: "Fatal"},
+		{name: "message", definition: TimelineDefinition{Name: "a", Filter: TimelineFilter{Messages: []string{"("}}}, expected: "invalid messages"},
+		{name: "locator", definition: TimelineDefinition{Name: "a", Filter: TimelineFilter{Locator: map[string][]string{"ns": {"-("}}}}, expected: "invalid locator ns"},
+		{name: "query", definition: TimelineDefinition{Name: "a", Filter: TimelineFilter{Query: "level >="}}, expected: "invalid query"},
+		{name: "valid", definition: TimelineDefinition{Name: "a", SortBy: "level"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.definition.Validate()
+			switch {
+			case len(tt.expected) == 0 && err != nil:
+				t.Errorf("unexpected error %v", err)
+			case len(tt.expected) > 0 && (err == nil || !strings.Contains(err.Error(), tt.expected)):
+				t.Errorf("expected an error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestTimelineDefinitionRenderer(t *testing.T) {
+	artifactDir, err := ioutil.TempDir("", "timeline-definition-renderer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(artifactDir)
+
+	events := monitorapi.Intervals{
+		{Condition: monitorapi.Condition{Level: monitorapi.Error, Locator: "node/a", Message: "reason/NodeUpdate"}, From: timeFor("2022-03-01T10:00:00Z"), To: timeFor("2022-03-01T10:01:00Z")},
+	}
+	definition := TimelineDefinition{Name: "nodes", Filter: TimelineFilter{Locator: map[string][]string{"node": {"."}}}, GroupBy: "node"}
+	if err := NewTimelineDefinitionRenderer(definition).WriteRunData(artifactDir, nil, events, "_20220301"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"e2e-timelines_nodes_20220301.json", "e2e-timelines_nodes_20220301.html", "e2e-timeline-viewer_nodes_20220301.html"} {
+		if _, err := os.Stat(filepath.Join(artifactDir, name)); err != nil {
+			t.Error(err)
+		}
+	}
+	viewer, err := ioutil.ReadFile(filepath.Join(artifactDir, "e2e-timeline-viewer_nodes_20220301.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(viewer), `var layout = {groupBy: "node", sortBy: ""};`) {
+		t.Errorf("the viewer does not start grouped by node")
+	}
+}
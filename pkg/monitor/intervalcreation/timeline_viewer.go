@@ -0,0 +1,134 @@
+package intervalcreation
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// timelineViewerTemplate is a single HTML page with no external resources, so the viewer works offline from an
+// artifacts directory.
+//
+//go:embed timeline_viewer.html
+var timelineViewerTemplate string
+
+var timelineViewer = template.Must(template.New("timeline-viewer").Parse(timelineViewerTemplate))
+
+// timelineViewerInterval is an interval as the viewer consumes it.
+type timelineViewerInterval struct {
+	Level   string `json:"level"`
+	Locator string `json:"locator"`
+	Message string `json:"message"`
+	// Type and Parts are the structured locator, used for filtering and grouping.
+	Type        string            `json:"type,omitempty"`
+	Parts       map[string]string `json:"parts,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// From and To are milliseconds since the epoch.  To is zero for intervals that never ended.
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// RenderTimelineViewer returns an interactive timeline of events as a single HTML page.  The page filters by free
+// text, regular expression and locator key, zooms into a time range, groups rows by namespace, node, operator or
+// locator type, shows the full message of an interval when it is clicked and, for failed e2e tests, links to the
+// disruption intervals that overlap them.  Upgrade phases (see monitorapi.IsUpgradePhase) are drawn as bands behind the
+// other intervals.
+func RenderTimelineViewer(title string, events monitorapi.Intervals) ([]byte, error) {
+	return RenderTimelineViewerWithOptions(title, TimelineViewerOptions{}, events)
+}
+
+// TimelineViewerOptions are the initial layout of the viewer.  The page can change them.
+type TimelineViewerOptions struct {
+	// GroupBy groups the rows by namespace, node, operator, type or any other locator key.  Empty is no grouping.
+	GroupBy string
+	// SortBy orders the rows of a group by from, locator, duration or level.  Empty is from.
+	SortBy string
+}
+
+// RenderTimelineViewerWithOptions is RenderTimelineViewer with an initial layout.
+func RenderTimelineViewerWithOptions(title string, options TimelineViewerOptions, events monitorapi.Intervals) ([]byte, error) {
+	sorted := make(monitorapi.Intervals, len(events))
+	copy(sorted, events)
+	sort.Stable(sorted)
+
+	viewerIntervals := make([]timelineViewerInterval, 0, len(sorted))
+	for _, event := range sorted {
+		locator := monitorapi.LocatorFrom(event)
+		message := monitorapi.MessageFrom(event)
+		viewerInterval := timelineViewerInterval{
+			Level:   event.Level.String(),
+			Locator: event.Locator,
+			Message: event.Message,
+			Type:    string(locator.Type),
+			From:    event.From.UnixNano() / 1e6,
+		}
+		if !event.To.IsZero() {
+			viewerInterval.To = event.To.UnixNano() / 1e6
+		}
+		for _, part := range locator.Parts {
+			if len(part.Key) == 0 {
+				continue
+			}
+			if viewerInterval.Parts == nil {
+				viewerInterval.Parts = map[string]string{}
+			}
+			viewerInterval.Parts[string(part.Key)] = part.Value
+		}
+		for key, value := range message.Annotations {
+			if viewerInterval.Annotations == nil {
+				viewerInterval.Annotations = map[string]string{}
+			}
+			viewerInterval.Annotations[string(key)] = value
+		}
+		viewerIntervals = append(viewerIntervals, viewerInterval)
+	}
+
+	out := &bytes.Buffer{}
+	if err := timelineViewer.Execute(out, struct {
+		Title     string
+		Intervals []timelineViewerInterval
+		GroupBy   string
+		SortBy    string
+	}{
+		Title:     title,
+		Intervals: viewerIntervals,
+		GroupBy:   options.GroupBy,
+		SortBy:    options.SortBy,
+	}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+type timelineViewerRenderer struct {
+	name    string
+	filter  monitorapi.EventIntervalMatchesFunc
+	options TimelineViewerOptions
+}
+
+// NewTimelineViewerRenderer writes the intervals that match filter as e2e-timeline-viewer_<name><suffix>.html.  See
+// RenderTimelineViewer.
+func NewTimelineViewerRenderer(name string, filter monitorapi.EventIntervalMatchesFunc) timelineViewerRenderer {
+	return timelineViewerRenderer{
+		name:   name,
+		filter: filter,
+	}
+}
+
+func (r timelineViewerRenderer) TimelineNames() []string {
+	return []string{r.name}
+}
+
+func (r timelineViewerRenderer) WriteRunData(artifactDir string, _ monitorapi.ResourcesMap, events monitorapi.Intervals, timeSuffix string) error {
+	viewerHTML, err := RenderTimelineViewerWithOptions(fmt.Sprintf("Timeline - %s%s", r.name, timeSuffix), r.options, events.Filter(monitorapi.Or(r.filter, monitorapi.IsUpgradePhase)))
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(artifactDir, fmt.Sprintf("e2e-timeline-viewer_%s%s.html", r.name, timeSuffix)), viewerHTML, 0644)
+}
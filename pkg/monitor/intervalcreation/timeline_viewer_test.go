@@ -0,0 +1,62 @@
+package intervalcreation
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestRenderTimelineViewer(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := monitorapi.Intervals{
+		{Condition: monitorapi.Condition{Level: monitorapi.Error, Locator: "e2e-test/\"[sig-network] fails\"", Message: `e2e test finished As "Failed"`}, From: start.Add(time.Minute), To: start.Add(2 * time.Minute)},
+		{Condition: monitorapi.Condition{Level: monitorapi.Warning, Locator: "ns/openshift-etcd pod/etcd-0 node/a", Message: "reason/Unhealthy </script><script>alert(1)</script>"}, From: start, To: start},
+		{Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "node/a", Message: "still open"}, From: start},
+	}
+
+	html, err := RenderTimelineViewer("Timeline <everything>", events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(html)
+	if !strings.Contains(page, "<title>Timeline &lt;everything&gt;</title>") {
+		t.Errorf("title is not escaped")
+	}
+	if strings.Contains(page, "</script><script>alert(1)") {
+		t.Errorf("messages are not escaped")
+	}
+	// the viewer has to work offline from the artifacts directory
+	if external := regexp.MustCompile(`(src|href)="?(https?:)?//`).FindString(page); len(external) > 0 {
+		t.Errorf("viewer loads an external resource: %s", external)
+	}
+
+	data := regexp.MustCompile(`(?s)var intervals = (.*?);\n`).FindStringSubmatch(page)
+	if data == nil {
+		t.Fatal("no intervals in the page")
+	}
+	got := []timelineViewerInterval{}
+	if err := json.Unmarshal([]byte(data[1]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 intervals, got %#v", got)
+	}
+	byLocator := map[string]timelineViewerInterval{}
+	for _, interval := range got {
+		byLocator[interval.Locator] = interval
+	}
+	if pod := byLocator[events[1].Locator]; pod.Type != string(monitorapi.LocatorTypePod) || pod.Parts["ns"] != "openshift-etcd" || pod.Parts["node"] != "a" || pod.Annotations["reason"] != "Unhealthy" || pod.From != pod.To {
+		t.Errorf("unexpected pod interval %#v", pod)
+	}
+	if open := byLocator["node/a"]; open.To != 0 {
+		t.Errorf("unexpected open interval %#v", open)
+	}
+	// intervals are sorted by time
+	if test := got[2]; test.Type != string(monitorapi.LocatorTypeE2ETest) || test.To-test.From != time.Minute.Milliseconds() {
+		t.Errorf("unexpected e2e test interval %#v", test)
+	}
+}
@@ -20,6 +20,8 @@ func defaultIntervalCreationFns() []simpleIntervalCreationFunc {
 		IntervalsFromEvents_OperatorDegraded,
 		IntervalsFromEvents_E2ETests,
 		IntervalsFromEvents_NodeChanges,
+		IntervalsFromEvents_ConditionChanges,
+		IntervalsFromEvents_UpgradePhases,
 		CreatePodIntervalsFromInstants,
 	}
 }
@@ -0,0 +1,66 @@
+package intervalcreation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// IntervalsFromEvents_UpgradePhases builds an interval for every upgrade phase from the events the upgrade test
+// records at its boundaries (see monitorapi.UpgradePhaseStartedEventReason).  A phase that never ended, because the
+// upgrade failed or the run was cut short, lasts until end as a Warning.
+func IntervalsFromEvents_UpgradePhases(events monitorapi.Intervals, _ monitorapi.ResourcesMap, beginning, end time.Time) monitorapi.Intervals {
+	ret := monitorapi.Intervals{}
+	started := map[string]time.Time{}
+	// keep the output in the order phases started
+	order := []string{}
+	for _, event := range events {
+		message := monitorapi.MessageFrom(event)
+		phase := message.Annotation(monitorapi.AnnotationPhase)
+		if len(phase) == 0 {
+			continue
+		}
+		switch message.Annotation(monitorapi.AnnotationReason) {
+		case monitorapi.UpgradePhaseStartedEventReason:
+			if _, ok := started[phase]; ok {
+				continue
+			}
+			started[phase] = event.From
+			order = append(order, phase)
+		case monitorapi.UpgradePhaseEndedEventReason:
+			from, ok := started[phase]
+			if !ok {
+				from = beginning
+			}
+			ret = append(ret, monitorapi.EventInterval{
+				Condition: monitorapi.Condition{
+					Level:   monitorapi.Info,
+					Locator: monitorapi.UpgradePhaseLocator(phase),
+					Message: fmt.Sprintf("constructed/true phase/%s upgrade phase", phase),
+				},
+				From: from,
+				To:   event.From,
+			})
+			delete(started, phase)
+		}
+	}
+
+	for _, phase := range order {
+		from, ok := started[phase]
+		if !ok {
+			continue
+		}
+		ret = append(ret, monitorapi.EventInterval{
+			Condition: monitorapi.Condition{
+				Level:   monitorapi.Warning,
+				Locator: monitorapi.UpgradePhaseLocator(phase),
+				Message: fmt.Sprintf("constructed/true phase/%s upgrade phase never completed", phase),
+			},
+			From: from,
+			To:   end,
+		})
+		delete(started, phase)
+	}
+	return ret
+}
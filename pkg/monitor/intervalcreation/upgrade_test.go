@@ -0,0 +1,52 @@
+package intervalcreation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestIntervalsFromEvents_UpgradePhases(t *testing.T) {
+	event := func(at, reason, phase string) monitorapi.EventInterval {
+		return monitorapi.EventInterval{
+			Condition: monitorapi.Condition{
+				Level:   monitorapi.Info,
+				Locator: "ns/openshift-cluster-version clusterversion/cluster",
+				Message: "reason/" + reason + " phase/" + phase,
+			},
+			From: timeFor(at),
+			To:   timeFor(at),
+		}
+	}
+	events := monitorapi.Intervals{
+		event("2022-03-01T10:00:00Z", monitorapi.UpgradePhaseStartedEventReason, monitorapi.UpgradePhaseAcknowledge),
+		event("2022-03-01T10:01:00Z", monitorapi.UpgradePhaseEndedEventReason, monitorapi.UpgradePhaseAcknowledge),
+		event("2022-03-01T10:01:00Z", monitorapi.UpgradePhaseStartedEventReason, monitorapi.UpgradePhaseControlPlane),
+		event("2022-03-01T10:20:00Z", monitorapi.UpgradePhaseStartedEventReason, monitorapi.UpgradePoolPhase("master")),
+		event("2022-03-01T10:40:00Z", monitorapi.UpgradePhaseEndedEventReason, monitorapi.UpgradePoolPhase("master")),
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "node/master-0", Message: "reason/NodeUpdate phase/Drain roles/master drained node"},
+			From:      timeFor("2022-03-01T10:21:00Z"), To: timeFor("2022-03-01T10:22:00Z"),
+		},
+	}
+
+	expected := monitorapi.Intervals{
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "upgrade/acknowledge", Message: "constructed/true phase/acknowledge upgrade phase"},
+			From:      timeFor("2022-03-01T10:00:00Z"), To: timeFor("2022-03-01T10:01:00Z"),
+		},
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "upgrade/pool/master", Message: "constructed/true phase/pool/master upgrade phase"},
+			From:      timeFor("2022-03-01T10:20:00Z"), To: timeFor("2022-03-01T10:40:00Z"),
+		},
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Warning, Locator: "upgrade/control-plane", Message: "constructed/true phase/control-plane upgrade phase never completed"},
+			From:      timeFor("2022-03-01T10:01:00Z"), To: timeFor("2022-03-01T11:00:00Z"),
+		},
+	}
+	actual := IntervalsFromEvents_UpgradePhases(events, nil, timeFor("2022-03-01T09:00:00Z"), timeFor("2022-03-01T11:00:00Z"))
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected\n%v\ngot\n%v", expected, actual)
+	}
+}
@@ -24,8 +24,16 @@ type Monitor struct {
 	unsortedEvents monitorapi.Intervals
 	samples        []*sample
 
+	// journal, when set, receives intervals instead of events and unsortedEvents.  Intervals that could not be
+	// written to the journal are kept in memory instead.
+	journal         EventJournal
+	journaledStarts []journaledStart
+	journalErr      error
+
 	recordedResourceLock sync.Mutex
 	recordedResources    monitorapi.ResourcesMap
+
+	stats monitorStats
 }
 
 // NewMonitor creates a monitor with the default sampling interval.
@@ -42,6 +50,31 @@ func NewMonitorWithInterval(interval time.Duration) *Monitor {
 	}
 }
 
+// NewMonitorWithJournal creates a monitor that samples at the provided interval and appends recorded
+// intervals to journal instead of holding them in memory.
+func NewMonitorWithJournal(interval time.Duration, journal EventJournal) *Monitor {
+	m := NewMonitorWithInterval(interval)
+	m.journal = journal
+	return m
+}
+
+// EventJournal persists intervals as they are recorded.
+type EventJournal interface {
+	Record(interval monitorapi.EventInterval) error
+	StartInterval(id int, interval monitorapi.EventInterval) error
+	EndInterval(id int, to time.Time) error
+	// Intervals returns everything written to the journal so far.
+	Intervals() (monitorapi.Intervals, error)
+}
+
+// journaledStart tracks an interval opened by StartInterval on a journaled monitor.
+type journaledStart struct {
+	from  time.Time
+	ended bool
+	// index is the position of the interval in unsortedEvents if it could not be journaled, otherwise -1.
+	index int
+}
+
 var _ Interface = &Monitor{}
 
 // StartSampling starts sampling every interval until the provided context is done.
@@ -101,7 +134,9 @@ func (m *Monitor) RecordResource(resourceType string, obj runtime.Object) {
 		m.recordedResources[resourceType] = recordedResource
 	}
 
-	newMetadata, err := meta.Accessor(obj)
+	// annotate a copy, obj may be shared with an informer cache
+	toStore := obj.DeepCopyObject()
+	newMetadata, err := meta.Accessor(toStore)
 	if err != nil {
 		// coding error
 		panic(err)
@@ -112,7 +147,6 @@ func (m *Monitor) RecordResource(resourceType string, obj runtime.Object) {
 		UID:       fmt.Sprintf("%v", newMetadata.GetUID()),
 	}
 
-	toStore := obj.DeepCopyObject()
 	// without metadata, just stomp in the new value, we can't add annotations
 	if newMetadata == nil {
 		recordedResource[key] = toStore
@@ -175,27 +209,46 @@ func (m *Monitor) Record(conditions ...monitorapi.Condition) {
 	if len(conditions) == 0 {
 		return
 	}
+	t := time.Now().UTC()
+	m.stats.countRecorded(t, conditions...)
 	m.lock.Lock()
 	defer m.lock.Unlock()
-	t := time.Now().UTC()
 	for _, condition := range conditions {
-		m.events = append(m.events, monitorapi.EventInterval{
-			Condition: condition,
+		interval := monitorapi.EventInterval{
+			Condition: monitorapi.EnsureStructured(condition),
 			From:      t,
 			To:        t,
-		})
+		}
+		if m.journalRecord(interval) {
+			continue
+		}
+		m.events = append(m.events, interval)
 	}
 }
 
 // StartInterval inserts a record at time t with the provided condition and returns an opaque
 // locator to the interval. The caller may close the sample at any point by invoking EndInterval().
 func (m *Monitor) StartInterval(t time.Time, condition monitorapi.Condition) int {
+	m.stats.countRecorded(time.Now(), condition)
+	m.stats.intervalStarted()
 	m.lock.Lock()
 	defer m.lock.Unlock()
-	m.unsortedEvents = append(m.unsortedEvents, monitorapi.EventInterval{
-		Condition: condition,
+	interval := monitorapi.EventInterval{
+		Condition: monitorapi.EnsureStructured(condition),
 		From:      t,
-	})
+	}
+	if m.journal != nil {
+		id := len(m.journaledStarts)
+		start := journaledStart{from: t, index: -1}
+		if err := m.journal.StartInterval(id, interval); err != nil {
+			m.setJournalErr(err)
+			start.index = len(m.unsortedEvents)
+			m.unsortedEvents = append(m.unsortedEvents, interval)
+		}
+		m.journaledStarts = append(m.journaledStarts, start)
+		return id
+	}
+	m.unsortedEvents = append(m.unsortedEvents, interval)
 	return len(m.unsortedEvents) - 1
 }
 
@@ -204,8 +257,32 @@ func (m *Monitor) StartInterval(t time.Time, condition monitorapi.Condition) int
 func (m *Monitor) EndInterval(startedInterval int, t time.Time) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
+	if m.journal != nil {
+		if startedInterval < 0 || startedInterval >= len(m.journaledStarts) {
+			return
+		}
+		start := &m.journaledStarts[startedInterval]
+		if !start.from.Before(t) {
+			return
+		}
+		if !start.ended {
+			start.ended = true
+			m.stats.intervalEnded()
+		}
+		if start.index >= 0 {
+			m.unsortedEvents[start.index].To = t
+			return
+		}
+		if err := m.journal.EndInterval(startedInterval, t); err != nil {
+			m.setJournalErr(err)
+		}
+		return
+	}
 	if startedInterval < len(m.unsortedEvents) {
 		if m.unsortedEvents[startedInterval].From.Before(t) {
+			if m.unsortedEvents[startedInterval].To.IsZero() {
+				m.stats.intervalEnded()
+			}
 			m.unsortedEvents[startedInterval].To = t
 		}
 	}
@@ -217,15 +294,48 @@ func (m *Monitor) RecordAt(t time.Time, conditions ...monitorapi.Condition) {
 	if len(conditions) == 0 {
 		return
 	}
+	m.stats.countRecorded(time.Now(), conditions...)
 	m.lock.Lock()
 	defer m.lock.Unlock()
 	for _, condition := range conditions {
-		m.unsortedEvents = append(m.unsortedEvents, monitorapi.EventInterval{
-			Condition: condition,
+		interval := monitorapi.EventInterval{
+			Condition: monitorapi.EnsureStructured(condition),
 			From:      t,
 			To:        t,
-		})
+		}
+		if m.journalRecord(interval) {
+			continue
+		}
+		m.unsortedEvents = append(m.unsortedEvents, interval)
+	}
+}
+
+// journalRecord writes the interval to the journal and returns true if it no longer needs to be held in memory.
+// Callers must hold the lock.
+func (m *Monitor) journalRecord(interval monitorapi.EventInterval) bool {
+	if m.journal == nil {
+		return false
 	}
+	if err := m.journal.Record(interval); err != nil {
+		m.setJournalErr(err)
+		return false
+	}
+	return true
+}
+
+// setJournalErr remembers the first journal failure.  Callers must hold the lock.
+func (m *Monitor) setJournalErr(err error) {
+	if m.journalErr == nil {
+		m.journalErr = err
+	}
+}
+
+// JournalErr returns the first error encountered writing to or reading from the journal, if any.  Intervals that
+// could not be written are still kept in memory, but intervals that could not be read back are lost.
+func (m *Monitor) JournalErr() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.journalErr
 }
 
 func (m *Monitor) sample(hasPrevious bool) bool {
@@ -277,11 +387,25 @@ func (m *Monitor) Conditions(from, to time.Time) monitorapi.Intervals {
 func (m *Monitor) Intervals(from, to time.Time) monitorapi.Intervals {
 	samples, sortedEvents, unsortedEvents := m.snapshot()
 
-	intervals := mergeIntervals(sortedEvents.Slice(from, to), unsortedEvents.CopyAndSort(from, to), filterSamples(samples, from, to))
+	intervals := mergeIntervals(sortedEvents.Slice(from, to), unsortedEvents.CopyAndSort(from, to), m.journaledIntervals().CopyAndSort(from, to), filterSamples(samples, from, to))
 
 	return intervals
 }
 
+// journaledIntervals reads back the intervals held in the journal.
+func (m *Monitor) journaledIntervals() monitorapi.Intervals {
+	if m.journal == nil {
+		return nil
+	}
+	intervals, err := m.journal.Intervals()
+	if err != nil {
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		m.setJournalErr(err)
+	}
+	return intervals
+}
+
 // filterSamples converts the sorted samples that are within [from,to) to a set of
 // intervals.
 // TODO: simplify this by having the monitor samplers produce intervals themselves
@@ -315,13 +439,14 @@ func filterSamples(samples []*sample, from, to time.Time) monitorapi.Intervals {
 	}
 
 	intervals := make(monitorapi.Intervals, 0, len(samples)*2)
-	last, next := make(map[monitorapi.Condition]*monitorapi.EventInterval), make(map[monitorapi.Condition]*monitorapi.EventInterval)
+	last, next := make(map[sampleKey]*monitorapi.EventInterval), make(map[sampleKey]*monitorapi.EventInterval)
 	for _, sample := range samples {
 		for _, condition := range sample.conditions {
-			interval, ok := last[*condition]
+			key := sampleKeyFor(condition)
+			interval, ok := last[key]
 			if ok {
 				interval.To = sample.at
-				next[*condition] = interval
+				next[key] = interval
 				continue
 			}
 			intervals = append(intervals, monitorapi.EventInterval{
@@ -329,7 +454,7 @@ func filterSamples(samples []*sample, from, to time.Time) monitorapi.Intervals {
 				From:      sample.at,
 				To:        sample.at.Add(time.Second),
 			})
-			next[*condition] = &intervals[len(intervals)-1]
+			next[key] = &intervals[len(intervals)-1]
 		}
 		for k := range last {
 			delete(last, k)
@@ -339,6 +464,18 @@ func filterSamples(samples []*sample, from, to time.Time) monitorapi.Intervals {
 	return intervals
 }
 
+// sampleKey identifies a sampled condition across sampling intervals.  Conditions carry structured fields that
+// are not comparable, so only the legacy string fields are used.
+type sampleKey struct {
+	level   monitorapi.EventLevel
+	locator string
+	message string
+}
+
+func sampleKeyFor(condition *monitorapi.Condition) sampleKey {
+	return sampleKey{level: condition.Level, locator: condition.Locator, message: condition.Message}
+}
+
 // mergeEvents returns a sorted list of all events provided as sources. This could be
 // more efficient by requiring all sources to be sorted and then performing a zipper
 // merge.
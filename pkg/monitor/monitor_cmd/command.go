@@ -1,20 +1,13 @@
 package monitor_cmd
 
 import (
-	"archive/zip"
 	"bytes"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/runtime"
-
 	"github.com/openshift/origin/pkg/monitor/intervalcreation"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
@@ -32,12 +25,18 @@ type TimelineOptions struct {
 
 	LocatorMatchers []string
 	Namespaces      []string
+	Query           string
 	OutputType      string
 	EndDate         string
+	// TimelineDefinitionFiles are YAML files, or directories of them, that add to KnownTimelines.  See
+	// intervalcreation.TimelineDefinition.
+	TimelineDefinitionFiles []string
 
 	KnownRenderers map[string]RenderFunc
 	KnownTimelines map[string]monitorapi.EventIntervalMatchesFunc
-	IOStreams      genericclioptions.IOStreams
+	// TimelineLayouts are the viewer layouts of the timelines loaded from TimelineDefinitionFiles.
+	TimelineLayouts map[string]intervalcreation.TimelineViewerOptions
+	IOStreams       genericclioptions.IOStreams
 }
 
 type RenderFunc func(intervals monitorapi.Intervals) ([]byte, error)
@@ -50,8 +49,15 @@ func NewTimelineOptions(ioStreams genericclioptions.IOStreams) *TimelineOptions
 
 		IOStreams: ioStreams,
 		KnownRenderers: map[string]RenderFunc{
-			"json": monitorserialization.EventsToJSON,
-			"html": renderHTML,
+			"json":         monitorserialization.EventsToJSON,
+			"compact":      monitorserialization.EventsToCompact,
+			"html":         renderHTML,
+			"viewer":       renderViewer,
+			"csv":          renderCSV,
+			"tsv":          renderTSV,
+			"gantt":        renderGantt,
+			"mermaid":      renderMermaid,
+			"chrome-trace": renderChromeTrace,
 		},
 		KnownTimelines: map[string]monitorapi.EventIntervalMatchesFunc{
 			"everything":    intervalcreation.BelongsInEverything,
@@ -60,6 +66,7 @@ func NewTimelineOptions(ioStreams genericclioptions.IOStreams) *TimelineOptions
 			"spyglass":      intervalcreation.BelongsInSpyglass,
 			"pod-lifecycle": intervalcreation.IsOriginalPodEvent,
 		},
+		TimelineLayouts: map[string]intervalcreation.TimelineViewerOptions{},
 	}
 }
 
@@ -73,6 +80,10 @@ func NewTimelineCommand(ioStreams genericclioptions.IOStreams) *cobra.Command {
 		Create a timeline html page based on the provided monitor events.
 
 		openshift-tests timeline --type=pod -f raw-monitor-events.json --namespace=openshift-kube-apiserver --namespace=openshift-kube-apiserver-operator -ojson 
+
+		openshift-tests timeline -f raw-monitor-events.json --query='level>=Warning and ns=~"openshift-etcd.*" and message contains "probe"'
+
+		openshift-tests timeline -f raw-monitor-events.json --timeline-definitions=etcd-timelines.yaml --type=etcd -oviewer
 		`,
 
 		SilenceUsage:  true,
@@ -92,23 +103,43 @@ func NewTimelineCommand(ioStreams genericclioptions.IOStreams) *cobra.Command {
 	}
 
 	o.Bind(cmd.Flags())
+	cmd.AddCommand(NewTimelineDiffCommand(ioStreams))
+	cmd.AddCommand(NewTimelineMergeCommand(ioStreams))
 
 	return cmd
 }
 
 func (o *TimelineOptions) Bind(flagset *pflag.FlagSet) error {
-	flagset.StringVarP(&o.MonitorEventFilename, "filename", "f", o.MonitorEventFilename, "raw-monitor-events.json file")
+	flagset.StringVarP(&o.MonitorEventFilename, "filename", "f", o.MonitorEventFilename, "raw-monitor-events.json file, or an e2e-events-journal_*.jsonl event journal from a run that may not have finished")
 	flagset.StringSliceVar(&o.Namespaces, "namespace", o.Namespaces, "namespaces to filter.  No entry is no filtering.")
 	flagset.StringVarP(&o.OutputType, "output", "o", o.OutputType, fmt.Sprintf("type of output: [%s]", strings.Join(sets.StringKeySet(o.KnownRenderers).List(), ",")))
 	flagset.StringVar(&o.TimelineType, "type", o.TimelineType, "type of timeline to produce: "+strings.Join(sets.StringKeySet(o.KnownTimelines).List(), ","))
 	flagset.StringVar(&o.PodResourceFilename, "known-pods", o.PodResourceFilename, "resource-pods_<timestamp>.zip filename from openshift-tests.")
 	flagset.StringSliceVarP(&o.LocatorMatchers, "locator", "l", o.LocatorMatchers, "key=value selector for monitor event locators (where value is a regex).  for instance -lpod=openshift-etcd-installer.  The same key listed multiple times means an OR.  Each separate key is logically ANDed.  Precede value with a dash for anti-match")
+	flagset.StringVarP(&o.Query, "query", "q", o.Query, `filter expression, for instance: level>=Warning and ns=~"openshift-etcd.*" and message contains "probe" and duration>5s.  Fields are level, duration, locator, message, type, annotation.<key>, or any locator key.  Operators are =, !=, =~, !~, contains, <, <=, >, >=, combined with and, or, not and parentheses`)
+	flagset.StringSliceVar(&o.TimelineDefinitionFiles, "timeline-definitions", o.TimelineDefinitionFiles, "YAML files, or directories of them, defining more timelines for --type.  Each timeline has a name, a filter on locator keys, levels, message patterns or a query, and the grouping and sort order of the rows in the viewer.")
 	flagset.StringVarP(&o.EndDate, "end-date", "e", o.EndDate, fmt.Sprintf("End date (default is one hour after latest event) in RFC3399 format in UTC timezone: %s", time.RFC3339))
 
 	return nil
 }
 
 func (o *TimelineOptions) Complete() error {
+	if len(o.TimelineDefinitionFiles) == 0 {
+		return nil
+	}
+	definitions, err := intervalcreation.LoadTimelineDefinitions(o.TimelineDefinitionFiles...)
+	if err != nil {
+		return fmt.Errorf("invalid --timeline-definitions: %w", err)
+	}
+	for _, definition := range definitions {
+		if o.KnownTimelines[definition.Name] != nil {
+			return fmt.Errorf("invalid --timeline-definitions: timeline %q already exists", definition.Name)
+		}
+		// Validate already compiled the filter
+		filter, _ := definition.Matcher()
+		o.KnownTimelines[definition.Name] = filter
+		o.TimelineLayouts[definition.Name] = definition.ViewerOptions()
+	}
 	return nil
 }
 
@@ -136,6 +167,12 @@ func (o *TimelineOptions) Validate() error {
 		}
 	}
 
+	if len(o.Query) > 0 {
+		if _, err := monitorapi.ParseQuery(o.Query); err != nil {
+			return fmt.Errorf("invalid --query: %w", err)
+		}
+	}
+
 	if len(o.EndDate) > 0 {
 		_, err := time.ParseInLocation(time.RFC3339, o.EndDate, time.UTC)
 		if err != nil {
@@ -162,6 +199,11 @@ func (o *TimelineOptions) ToTimeline() *Timeline {
 		}
 	}
 
+	var queryFilter monitorapi.EventIntervalMatchesFunc
+	if len(o.Query) > 0 {
+		queryFilter = monitorapi.MustParseQuery(o.Query)
+	}
+
 	var endDateTime = &time.Time{}
 	if len(o.EndDate) > 0 {
 		parsedTime, _ := time.Parse(time.RFC3339, o.EndDate)
@@ -170,6 +212,13 @@ func (o *TimelineOptions) ToTimeline() *Timeline {
 		endDateTime = nil
 	}
 
+	renderer := o.KnownRenderers[o.OutputType]
+	if layout, ok := o.TimelineLayouts[o.TimelineType]; ok && o.OutputType == "viewer" {
+		renderer = func(events monitorapi.Intervals) ([]byte, error) {
+			return intervalcreation.RenderTimelineViewerWithOptions("Timeline - "+o.TimelineType, layout, events)
+		}
+	}
+
 	return &Timeline{
 		MonitorEventFilename: o.MonitorEventFilename,
 		PodResourceFilename:  o.PodResourceFilename,
@@ -177,9 +226,10 @@ func (o *TimelineOptions) ToTimeline() *Timeline {
 		LocatorMatcher:        locatorMatcher,
 		RemovedLocatorMatcher: inverseLocatorMatcher,
 		Namespaces:            o.Namespaces,
+		QueryFilter:           queryFilter,
 		EndDate:               endDateTime,
 
-		Renderer:       o.KnownRenderers[o.OutputType],
+		Renderer:       renderer,
 		TimelineFilter: o.KnownTimelines[o.TimelineType],
 		IOStreams:      o.IOStreams,
 	}
@@ -192,6 +242,7 @@ type Timeline struct {
 	LocatorMatcher        map[string][]*regexp.Regexp
 	RemovedLocatorMatcher map[string][]*regexp.Regexp
 	Namespaces            []string
+	QueryFilter           monitorapi.EventIntervalMatchesFunc
 	EndDate               *time.Time
 
 	Renderer       RenderFunc
@@ -208,7 +259,7 @@ func (o *Timeline) Run() error {
 
 	recordedResources := monitorapi.ResourcesMap{}
 	if len(o.PodResourceFilename) > 0 {
-		recordedResources, err = loadKnownPods(o.PodResourceFilename)
+		recordedResources, err = monitorserialization.ResourcesMapFromFiles(o.PodResourceFilename)
 		if err != nil {
 			return err
 		}
@@ -225,6 +276,9 @@ func (o *Timeline) Run() error {
 	if len(o.RemovedLocatorMatcher) > 0 {
 		filteredEvents = filteredEvents.Filter(monitorapi.NotContainsAllParts(o.RemovedLocatorMatcher))
 	}
+	if o.QueryFilter != nil {
+		filteredEvents = filteredEvents.Filter(o.QueryFilter)
+	}
 	// compute intervals from raw
 	from := time.Time{}
 	var to time.Time
@@ -247,6 +301,14 @@ func (o *Timeline) Run() error {
 
 	filteredEvents = intervalcreation.InsertCalculatedIntervals(filteredEvents, recordedResources, from, to)
 
+	// the upgrade phases are the background of every timeline, so they are kept whatever the filters
+	phases := consumedEvents.Filter(monitorapi.IsUpgradePhase)
+	if len(phases) == 0 {
+		phases = intervalcreation.IntervalsFromEvents_UpgradePhases(consumedEvents, recordedResources, from, to)
+	}
+	filteredEvents = append(filteredEvents.Filter(monitorapi.Not(monitorapi.IsUpgradePhase)), phases...)
+	sort.Sort(filteredEvents)
+
 	output, err := o.Renderer(filteredEvents)
 	if err != nil {
 		return err
@@ -259,6 +321,10 @@ func (o *Timeline) Run() error {
 	return nil
 }
 
+func renderViewer(events monitorapi.Intervals) ([]byte, error) {
+	return intervalcreation.RenderTimelineViewer("Timeline", events)
+}
+
 func renderHTML(events monitorapi.Intervals) ([]byte, error) {
 	eventIntervalsJSON, err := monitorserialization.EventsIntervalsToJSON(events)
 	if err != nil {
@@ -272,60 +338,3 @@ func renderHTML(events monitorapi.Intervals) ([]byte, error) {
 
 	return e2eChartHTML, nil
 }
-
-func loadKnownPods(filename string) (monitorapi.ResourcesMap, error) {
-
-	zipReader, err := zip.OpenReader(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer zipReader.Close()
-
-	pods := monitorapi.InstanceMap{}
-	for _, f := range zipReader.File {
-		rc, err := f.Open()
-		if err != nil {
-			return nil, err
-		}
-		currBytes, err := ioutil.ReadAll(rc)
-		if err != nil {
-			return nil, err
-		}
-		_ = rc.Close()
-
-		// there has to be a better way, but this functions, ugly as it is.
-		unstructuredObject := map[string]interface{}{}
-		if err := json.Unmarshal(currBytes, &unstructuredObject); err != nil {
-			return nil, fmt.Errorf("error unmarshalling list: %w", err)
-		}
-		unstructuredObj := &unstructured.Unstructured{
-			Object: unstructuredObject,
-		}
-		unstructuredList, err := unstructuredObj.ToList()
-		if err != nil {
-			return nil, err
-		}
-		//nsList := &unstructured.UnstructuredList{}
-
-		for _, item := range unstructuredList.Items {
-			item.SetKind("Pod")
-			item.SetAPIVersion("v1")
-
-			pod := &corev1.Pod{}
-			err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, pod)
-			if err != nil {
-				return nil, err
-			}
-			instanceKey := monitorapi.InstanceKey{
-				Namespace: pod.Namespace,
-				Name:      pod.Name,
-				UID:       fmt.Sprintf("%v", pod.UID),
-			}
-			pods[instanceKey] = pod
-		}
-	}
-
-	return monitorapi.ResourcesMap{
-		"pods": pods,
-	}, nil
-}
@@ -0,0 +1,349 @@
+package monitor_cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/intervalcreation"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// DiffAlignment decides how the times of two runs are lined up.
+type DiffAlignment string
+
+const (
+	// AlignByStart compares times relative to the first interval of each run.
+	AlignByStart DiffAlignment = "start"
+	// AlignByUpgradePhase compares times relative to the upgrade phase markers recorded by the upgrade test, so an
+	// interval during the upgrade of one run is only matched by an interval during the upgrade of the other.
+	AlignByUpgradePhase DiffAlignment = "upgrade"
+)
+
+// upgradePhaseReasons are the event reasons the upgrade test records against clusterversion/cluster.
+var upgradePhaseReasons = sets.NewString("UpgradeStarted", "UpgradeRollback", "UpgradeFailed", "UpgradeComplete")
+
+// beforeUpgradePhase is the phase of intervals that started before the first upgrade phase marker.
+const beforeUpgradePhase = "BeforeUpgrade"
+
+// DefaultDiffWindow is how far apart, after alignment, two intervals of the same kind may start and still match.
+const DefaultDiffWindow = 5 * time.Minute
+
+// TimelineDiff is the comparison of two runs.  Duration deltas are B minus A.
+type TimelineDiff struct {
+	Alignment DiffAlignment `json:"alignment"`
+	// Window is how far apart, after alignment, intervals of the same kind may start and still match.
+	Window time.Duration `json:"window"`
+	A      DiffRun       `json:"a"`
+	B      DiffRun       `json:"b"`
+
+	// OnlyInA and OnlyInB are the intervals with no counterpart in the other run.
+	OnlyInA []DiffInterval `json:"onlyInA"`
+	OnlyInB []DiffInterval `json:"onlyInB"`
+
+	// Disruption is the total disruption of every backend and connection type, keyed by locator.
+	Disruption []DurationDelta `json:"disruption"`
+	// OperatorConditions is how long each clusteroperator condition was abnormal, keyed by operator and condition.
+	OperatorConditions []DurationDelta `json:"operatorConditions"`
+}
+
+// DiffRun describes one of the runs being compared.
+type DiffRun struct {
+	Name   string      `json:"name"`
+	Start  time.Time   `json:"start"`
+	End    time.Time   `json:"end"`
+	Phases []DiffPhase `json:"phases,omitempty"`
+}
+
+// DiffPhase is an upgrade phase marker.
+type DiffPhase struct {
+	Name  string    `json:"name"`
+	Start time.Time `json:"start"`
+}
+
+// DiffInterval is a kind of interval seen in only one of the runs, or not around the same time in the other.
+type DiffInterval struct {
+	// Key identifies the kind of interval across runs, with generated names and numbers masked.
+	Key string `json:"key"`
+	// Phase and Offset are where the first occurrence without a counterpart happened, relative to the alignment.
+	Phase  string        `json:"phase,omitempty"`
+	Offset time.Duration `json:"offset"`
+	// Count is the number of occurrences without a counterpart.
+	Count int `json:"count"`
+	// Example is the first occurrence without a counterpart.
+	Example monitorapi.EventInterval `json:"example"`
+}
+
+// DurationDelta compares a duration between the runs.
+type DurationDelta struct {
+	Name string        `json:"name"`
+	A    time.Duration `json:"a"`
+	B    time.Duration `json:"b"`
+}
+
+// Delta is how much longer the duration was in B.
+func (d DurationDelta) Delta() time.Duration {
+	return d.B - d.A
+}
+
+// DiffTimelines compares the intervals of two runs.  An interval is matched by an interval of the same kind, see
+// diffKey, in the other run that started within window of it after alignment.  With AlignByUpgradePhase both must
+// also have started in the same upgrade phase.
+func DiffTimelines(alignment DiffAlignment, window time.Duration, nameA string, a monitorapi.Intervals, nameB string, b monitorapi.Intervals) (*TimelineDiff, error) {
+	runA, err := newDiffRun(alignment, nameA, a)
+	if err != nil {
+		return nil, err
+	}
+	runB, err := newDiffRun(alignment, nameB, b)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &TimelineDiff{
+		Alignment: alignment,
+		Window:    window,
+		A:         runA,
+		B:         runB,
+	}
+	keysA, keysB := runA.intervalsByKey(a), runB.intervalsByKey(b)
+	diff.OnlyInA = onlyIn(keysA, keysB, window)
+	diff.OnlyInB = onlyIn(keysB, keysA, window)
+	diff.Disruption = durationDeltas(disruptionDurations(a), disruptionDurations(b))
+	diff.OperatorConditions = durationDeltas(
+		operatorConditionDurations(a, runA.Start, runA.End),
+		operatorConditionDurations(b, runB.Start, runB.End),
+	)
+	return diff, nil
+}
+
+func newDiffRun(alignment DiffAlignment, name string, intervals monitorapi.Intervals) (DiffRun, error) {
+	run := DiffRun{Name: name}
+	for _, interval := range intervals {
+		if run.Start.IsZero() || interval.From.Before(run.Start) {
+			run.Start = interval.From
+		}
+		if interval.From.After(run.End) {
+			run.End = interval.From
+		}
+		if interval.To.After(run.End) {
+			run.End = interval.To
+		}
+	}
+
+	switch alignment {
+	case AlignByStart:
+	case AlignByUpgradePhase:
+		for _, interval := range intervals {
+			if !strings.HasSuffix(interval.Locator, "clusterversion/cluster") {
+				continue
+			}
+			reason := monitorapi.AnnotationFrom(interval, monitorapi.AnnotationReason)
+			if upgradePhaseReasons.Has(reason) {
+				run.Phases = append(run.Phases, DiffPhase{Name: reason, Start: interval.From})
+			}
+		}
+		if len(run.Phases) == 0 {
+			return run, fmt.Errorf("%s has no upgrade phase markers, align by %s instead", name, AlignByStart)
+		}
+		sort.SliceStable(run.Phases, func(i, j int) bool {
+			return run.Phases[i].Start.Before(run.Phases[j].Start)
+		})
+	default:
+		return run, fmt.Errorf("unknown alignment %q", alignment)
+	}
+	return run, nil
+}
+
+// position returns the phase t falls in and how long after the start of the phase it is.
+func (r DiffRun) position(t time.Time) (string, time.Duration) {
+	if len(r.Phases) == 0 {
+		return "", t.Sub(r.Start)
+	}
+	phase, start := beforeUpgradePhase, r.Start
+	for _, curr := range r.Phases {
+		if t.Before(curr.Start) {
+			break
+		}
+		phase, start = curr.Name, curr.Start
+	}
+	return phase, t.Sub(start)
+}
+
+// diffOccurrences are the intervals of one kind that started in one phase, in the order they started.
+type diffOccurrences struct {
+	key       string
+	phase     string
+	offsets   []time.Duration
+	intervals monitorapi.Intervals
+}
+
+// near returns whether an occurrence started within window of offset.
+func (o *diffOccurrences) near(offset, window time.Duration) bool {
+	i := sort.Search(len(o.offsets), func(i int) bool {
+		return o.offsets[i] >= offset-window
+	})
+	return i < len(o.offsets) && o.offsets[i] <= offset+window
+}
+
+// intervalsByKey groups intervals by kind and phase.
+func (r DiffRun) intervalsByKey(intervals monitorapi.Intervals) map[string]*diffOccurrences {
+	sorted := make(monitorapi.Intervals, len(intervals))
+	copy(sorted, intervals)
+	sort.Stable(sorted)
+
+	ret := map[string]*diffOccurrences{}
+	for _, interval := range sorted {
+		phase, offset := r.position(interval.From)
+		key := diffKey(interval)
+		phaseKey := phase + "\x00" + key
+		occurrences, ok := ret[phaseKey]
+		if !ok {
+			occurrences = &diffOccurrences{key: key, phase: phase}
+			ret[phaseKey] = occurrences
+		}
+		occurrences.offsets = append(occurrences.offsets, offset)
+		occurrences.intervals = append(occurrences.intervals, interval)
+	}
+	return ret
+}
+
+// onlyIn returns the kinds of intervals that have occurrences with no occurrence of the same kind within window in
+// the other run.
+func onlyIn(these, others map[string]*diffOccurrences, window time.Duration) []DiffInterval {
+	ret := []DiffInterval{}
+	for phaseKey, occurrences := range these {
+		other := others[phaseKey]
+		var unmatched *DiffInterval
+		for i, offset := range occurrences.offsets {
+			if other != nil && other.near(offset, window) {
+				continue
+			}
+			if unmatched == nil {
+				unmatched = &DiffInterval{
+					Key:     occurrences.key,
+					Phase:   occurrences.phase,
+					Offset:  offset,
+					Example: occurrences.intervals[i],
+				}
+			}
+			unmatched.Count++
+		}
+		if unmatched != nil {
+			ret = append(ret, *unmatched)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if !ret[i].Example.From.Equal(ret[j].Example.From) {
+			return ret[i].Example.From.Before(ret[j].Example.From)
+		}
+		return ret[i].Key < ret[j].Key
+	})
+	return ret
+}
+
+var (
+	// generatedPodSuffix matches the suffixes controllers add to pod names: a replicaset hash and a random string,
+	// or just the random string.
+	generatedPodSuffix = regexp.MustCompile(`(-[a-z0-9]{8,10})?-[a-z0-9]{5}$`)
+	diffNumbers        = regexp.MustCompile(`[0-9]+`)
+)
+
+// diffKey identifies the kind of an interval so it can be matched across runs.  Node names, UIDs and the generated
+// parts of pod names differ between clusters, so they are masked, as are the numbers in the message.  The message is
+// reduced to its reason when it has one.
+func diffKey(interval monitorapi.EventInterval) string {
+	locator := monitorapi.LocatorFrom(interval)
+	node := locator.Get(monitorapi.LocatorNodeKey)
+	parts := []string{}
+	for _, part := range locator.Parts {
+		value := part.Value
+		switch part.Key {
+		case monitorapi.LocatorUIDKey:
+			continue
+		case monitorapi.LocatorNodeKey:
+			value = "*"
+		case monitorapi.LocatorPodKey:
+			// static pods are named after their node
+			if len(node) > 0 && strings.HasSuffix(value, "-"+node) {
+				value = strings.TrimSuffix(value, node) + "*"
+			} else {
+				value = generatedPodSuffix.ReplaceAllString(value, "-*")
+			}
+		}
+		if len(part.Key) == 0 {
+			parts = append(parts, value)
+			continue
+		}
+		parts = append(parts, string(part.Key)+"/"+value)
+	}
+
+	message := interval.Message
+	if reason := monitorapi.AnnotationFrom(interval, monitorapi.AnnotationReason); len(reason) > 0 {
+		message = "reason/" + reason
+	}
+	message = diffNumbers.ReplaceAllString(message, "N")
+	return fmt.Sprintf("%s %s %s", interval.Level, strings.Join(parts, " "), message)
+}
+
+// disruptionDurations sums the disruption of every backend and connection type, like the disruption tests do.
+func disruptionDurations(intervals monitorapi.Intervals) map[string]time.Duration {
+	ret := map[string]time.Duration{}
+	for _, locator := range sets.NewString(locatorsOf(intervals.Filter(monitorapi.IsDisruptionEvent))...).List() {
+		ret[locator], _, _ = monitorapi.BackendDisruptionSeconds(locator, intervals)
+	}
+	return ret
+}
+
+func locatorsOf(intervals monitorapi.Intervals) []string {
+	ret := []string{}
+	for _, interval := range intervals {
+		ret = append(ret, interval.Locator)
+	}
+	return ret
+}
+
+// operatorConditionDurations sums how long every clusteroperator condition was abnormal.  The intervals are
+// calculated from the recorded condition changes, so raw monitor events and e2e-events files give the same result.
+func operatorConditionDurations(intervals monitorapi.Intervals, beginning, end time.Time) map[string]time.Duration {
+	instants := intervals.Filter(func(eventInterval monitorapi.EventInterval) bool {
+		return eventInterval.To.IsZero() || eventInterval.To.Equal(eventInterval.From)
+	})
+	calculated := monitorapi.Intervals{}
+	calculated = append(calculated, intervalcreation.IntervalsFromEvents_OperatorAvailable(instants, nil, beginning, end)...)
+	calculated = append(calculated, intervalcreation.IntervalsFromEvents_OperatorProgressing(instants, nil, beginning, end)...)
+	calculated = append(calculated, intervalcreation.IntervalsFromEvents_OperatorDegraded(instants, nil, beginning, end)...)
+
+	ret := map[string]time.Duration{}
+	for _, interval := range calculated {
+		operator, _ := monitorapi.OperatorFromLocator(interval.Locator)
+		condition := monitorapi.GetOperatorConditionStatus(interval.Message)
+		if condition == nil {
+			continue
+		}
+		ret[fmt.Sprintf("%s %s", operator, condition.Type)] += monitorapi.Intervals{interval}.Duration(0)
+	}
+	return ret
+}
+
+// durationDeltas pairs up durations by name, sorted by the size of the change.
+func durationDeltas(a, b map[string]time.Duration) []DurationDelta {
+	names := sets.StringKeySet(a).Union(sets.StringKeySet(b))
+	ret := []DurationDelta{}
+	for _, name := range names.List() {
+		ret = append(ret, DurationDelta{Name: name, A: a[name], B: b[name]})
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return absDuration(ret[i].Delta()) > absDuration(ret[j].Delta())
+	})
+	return ret
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,297 @@
+package monitor_cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+type TimelineDiffOptions struct {
+	MonitorEventFilenameA string
+	MonitorEventFilenameB string
+	TimelineType          string
+	Alignment             string
+	Window                time.Duration
+	OutputType            string
+
+	KnownRenderers map[string]RenderFunc
+	KnownTimelines map[string]monitorapi.EventIntervalMatchesFunc
+	IOStreams      genericclioptions.IOStreams
+}
+
+// diffRunA and diffRunB tell the runs apart in the invocations annotation of the intervals handed to the renderers.
+const (
+	diffRunA = "a"
+	diffRunB = "b"
+)
+
+// NewTimelineDiffOptions offers the renderers of the timeline command, which show the intervals of both runs, and
+// the diff renderers, which compare them.
+func NewTimelineDiffOptions(ioStreams genericclioptions.IOStreams) *TimelineDiffOptions {
+	timelineOptions := NewTimelineOptions(ioStreams)
+	o := &TimelineDiffOptions{
+		TimelineType: "everything",
+		Alignment:    string(AlignByStart),
+		Window:       DefaultDiffWindow,
+
+		OutputType: "diff",
+
+		IOStreams:      ioStreams,
+		KnownRenderers: timelineOptions.KnownRenderers,
+		KnownTimelines: timelineOptions.KnownTimelines,
+	}
+	o.KnownRenderers["diff"] = o.renderDiff(renderDiffText)
+	o.KnownRenderers["diff-html"] = o.renderDiff(renderDiffHTML)
+	o.KnownRenderers["diff-json"] = o.renderDiff(renderDiffJSON)
+	return o
+}
+
+func NewTimelineDiffCommand(ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewTimelineDiffOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare the monitor events of two runs",
+		Long: `
+		Compare the monitor events of two runs, reporting the intervals seen in only one of them and the difference
+		in disruption and clusteroperator condition durations.
+
+		openshift-tests timeline diff -a run1/e2e-events.json -b run2/e2e-events.json --align=upgrade -odiff-html
+
+		The timeline outputs show the intervals of both runs, with the invocations annotation set to a or b.
+
+		openshift-tests timeline diff -a run1/e2e-events.json -b run2/e2e-events.json -oviewer
+		`,
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	o.Bind(cmd.Flags())
+
+	return cmd
+}
+
+func (o *TimelineDiffOptions) Bind(flagset *pflag.FlagSet) error {
+	flagset.StringVarP(&o.MonitorEventFilenameA, "run-a", "a", o.MonitorEventFilenameA, "monitor events file of the first run, usually the one that passed")
+	flagset.StringVarP(&o.MonitorEventFilenameB, "run-b", "b", o.MonitorEventFilenameB, "monitor events file of the second run, usually the one that regressed")
+	flagset.StringVar(&o.Alignment, "align", o.Alignment, fmt.Sprintf("how to line up the runs: %s for the time since the first event, %s for the time since the last upgrade phase marker", AlignByStart, AlignByUpgradePhase))
+	flagset.DurationVar(&o.Window, "window", o.Window, "how far apart, after alignment, intervals of the same kind may start and still match")
+	flagset.StringVarP(&o.OutputType, "output", "o", o.OutputType, fmt.Sprintf("type of output: [%s]", strings.Join(sets.StringKeySet(o.KnownRenderers).List(), ",")))
+	flagset.StringVar(&o.TimelineType, "type", o.TimelineType, "type of timeline to compare: "+strings.Join(sets.StringKeySet(o.KnownTimelines).List(), ","))
+
+	return nil
+}
+
+func (o *TimelineDiffOptions) Validate() error {
+	if len(o.MonitorEventFilenameA) == 0 || len(o.MonitorEventFilenameB) == 0 {
+		return fmt.Errorf("both -a and -b are required")
+	}
+	if o.KnownRenderers[o.OutputType] == nil {
+		return fmt.Errorf("unknown -o")
+	}
+	if o.KnownTimelines[o.TimelineType] == nil {
+		return fmt.Errorf("unknown --type")
+	}
+	switch DiffAlignment(o.Alignment) {
+	case AlignByStart, AlignByUpgradePhase:
+	default:
+		return fmt.Errorf("--align must be %s or %s", AlignByStart, AlignByUpgradePhase)
+	}
+	return nil
+}
+
+func (o *TimelineDiffOptions) Run() error {
+	eventsA, err := monitorserialization.EventsFromFile(o.MonitorEventFilenameA)
+	if err != nil {
+		return err
+	}
+	eventsB, err := monitorserialization.EventsFromFile(o.MonitorEventFilenameB)
+	if err != nil {
+		return err
+	}
+
+	timelineFilter := o.KnownTimelines[o.TimelineType]
+	intervals := append(withDiffRun(diffRunA, eventsA.Filter(timelineFilter)), withDiffRun(diffRunB, eventsB.Filter(timelineFilter))...)
+	sort.Sort(intervals)
+
+	output, err := o.KnownRenderers[o.OutputType](intervals)
+	if err != nil {
+		return err
+	}
+	if _, err := o.IOStreams.Out.Write(output); err != nil {
+		return err
+	}
+	return nil
+}
+
+// withDiffRun returns copies of the intervals of a run with the invocations annotation set to the run.
+func withDiffRun(run string, intervals monitorapi.Intervals) monitorapi.Intervals {
+	ret := make(monitorapi.Intervals, 0, len(intervals))
+	for _, interval := range intervals {
+		condition := monitorapi.EnsureStructured(interval.Condition)
+		condition.StructuredMessage = condition.StructuredMessage.WithAnnotation(monitorapi.AnnotationInvocations, run)
+		interval.Condition = condition
+		ret = append(ret, interval)
+	}
+	return ret
+}
+
+// renderDiff returns a RenderFunc that splits the intervals into the runs they are annotated with, and renders their
+// comparison.
+func (o *TimelineDiffOptions) renderDiff(render func(diff *TimelineDiff) ([]byte, error)) RenderFunc {
+	return func(intervals monitorapi.Intervals) ([]byte, error) {
+		var a, b monitorapi.Intervals
+		for _, interval := range intervals {
+			switch monitorapi.AnnotationFrom(interval, monitorapi.AnnotationInvocations) {
+			case diffRunA:
+				a = append(a, interval)
+			case diffRunB:
+				b = append(b, interval)
+			}
+		}
+		diff, err := DiffTimelines(DiffAlignment(o.Alignment), o.Window, o.MonitorEventFilenameA, a, o.MonitorEventFilenameB, b)
+		if err != nil {
+			return nil, err
+		}
+		return render(diff)
+	}
+}
+
+func renderDiffJSON(diff *TimelineDiff) ([]byte, error) {
+	return json.MarshalIndent(diff, "", "    ")
+}
+
+func renderDiffText(diff *TimelineDiff) ([]byte, error) {
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, "a: %s\n", describeDiffRun(diff.A))
+	fmt.Fprintf(out, "b: %s\n", describeDiffRun(diff.B))
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, section := range []struct {
+		title  string
+		deltas []DurationDelta
+	}{
+		{title: "Disruption", deltas: diff.Disruption},
+		{title: "Clusteroperator conditions", deltas: diff.OperatorConditions},
+	} {
+		fmt.Fprintf(w, "\n%s:\n", section.title)
+		if len(section.deltas) == 0 {
+			fmt.Fprintf(w, "  none\n")
+			continue
+		}
+		fmt.Fprintf(w, "  NAME\tA\tB\tDELTA\n")
+		for _, delta := range section.deltas {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", delta.Name, delta.A.Round(time.Second), delta.B.Round(time.Second), formatDelta(delta.Delta()))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	for _, section := range []struct {
+		title     string
+		intervals []DiffInterval
+	}{
+		{title: "Only in a", intervals: diff.OnlyInA},
+		{title: "Only in b", intervals: diff.OnlyInB},
+	} {
+		fmt.Fprintf(out, "\n%s (%d):\n", section.title, len(section.intervals))
+		for _, interval := range section.intervals {
+			fmt.Fprintf(out, "  %s x%d %s\n", formatDiffPosition(interval), interval.Count, interval.Example.String())
+		}
+	}
+	return out.Bytes(), nil
+}
+
+func describeDiffRun(run DiffRun) string {
+	ret := fmt.Sprintf("%s, %s from %s", run.Name, run.End.Sub(run.Start).Round(time.Second), run.Start.UTC().Format(time.RFC3339))
+	for _, phase := range run.Phases {
+		ret += fmt.Sprintf(", %s at +%s", phase.Name, phase.Start.Sub(run.Start).Round(time.Second))
+	}
+	return ret
+}
+
+func formatDiffPosition(interval DiffInterval) string {
+	if len(interval.Phase) == 0 {
+		return fmt.Sprintf("+%s", interval.Offset.Round(time.Second))
+	}
+	return fmt.Sprintf("%s+%s", interval.Phase, interval.Offset.Round(time.Second))
+}
+
+func formatDelta(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d > 0 {
+		return "+" + d.String()
+	}
+	return d.String()
+}
+
+var diffHTML = template.Must(template.New("timeline-diff").Funcs(template.FuncMap{
+	"describe": describeDiffRun,
+	"position": formatDiffPosition,
+	"delta":    formatDelta,
+	"round":    func(d time.Duration) time.Duration { return d.Round(time.Second) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Timeline diff</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; text-align: left; vertical-align: top; }
+td.worse { color: #b00; }
+td.better { color: #070; }
+.Error { background: #fdd; }
+.Warning { background: #ffc; }
+</style>
+</head>
+<body>
+<h1>Timeline diff</h1>
+<p>a: {{describe .A}}<br>b: {{describe .B}}</p>
+{{define "deltas"}}{{if .}}<table>
+<tr><th>Name</th><th>A</th><th>B</th><th>Delta</th></tr>
+{{range .}}<tr><td>{{.Name}}</td><td>{{round .A}}</td><td>{{round .B}}</td><td class="{{if gt .Delta 0}}worse{{else if lt .Delta 0}}better{{end}}">{{delta .Delta}}</td></tr>
+{{end}}</table>{{else}}<p>none</p>{{end}}{{end}}
+{{define "intervals"}}<table>
+<tr><th>When</th><th>Count</th><th>Level</th><th>Locator</th><th>Message</th></tr>
+{{range .}}<tr class="{{.Example.Level}}"><td>{{position .}}</td><td>{{.Count}}</td><td>{{.Example.Level}}</td><td>{{.Example.Locator}}</td><td>{{.Example.Message}}</td></tr>
+{{end}}</table>{{end}}
+<h2>Disruption</h2>
+{{template "deltas" .Disruption}}
+<h2>Clusteroperator conditions</h2>
+{{template "deltas" .OperatorConditions}}
+<h2>Only in a ({{len .OnlyInA}})</h2>
+{{template "intervals" .OnlyInA}}
+<h2>Only in b ({{len .OnlyInB}})</h2>
+{{template "intervals" .OnlyInB}}
+</body>
+</html>
+`))
+
+func renderDiffHTML(diff *TimelineDiff) ([]byte, error) {
+	out := &bytes.Buffer{}
+	if err := diffHTML.Execute(out, diff); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
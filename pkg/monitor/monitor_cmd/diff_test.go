@@ -0,0 +1,158 @@
+package monitor_cmd
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func diffTestRun(start time.Time, podName, node string, restartAfter, disruption, degraded time.Duration) monitorapi.Intervals {
+	upgradeStarted := start.Add(10 * time.Minute)
+	return monitorapi.Intervals{
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "ns/openshift-etcd pod/etcd-" + node + " node/" + node, Message: "reason/Created"},
+			From:      start, To: start,
+		},
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "ns/openshift-cluster-version clusterversion/cluster", Message: "reason/UpgradeStarted version/4.11.0"},
+			From:      upgradeStarted, To: upgradeStarted,
+		},
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Warning, Locator: "ns/openshift-apiserver pod/" + podName + " node/" + node + " uid/1234", Message: "reason/BackOff Back-off restarting failed container"},
+			From:      start.Add(restartAfter), To: start.Add(restartAfter),
+		},
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Error, Locator: "disruption/kube-api connection/new", Message: "kube-api stopped responding"},
+			From:      upgradeStarted.Add(time.Minute), To: upgradeStarted.Add(time.Minute + disruption),
+		},
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Warning, Locator: "clusteroperator/etcd", Message: "condition/Degraded status/True reason/NodeControllerDegraded changed: bad"},
+			From:      upgradeStarted.Add(2 * time.Minute), To: upgradeStarted.Add(2 * time.Minute),
+		},
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "clusteroperator/etcd", Message: "condition/Degraded status/False changed: "},
+			From:      upgradeStarted.Add(2*time.Minute + degraded), To: upgradeStarted.Add(2*time.Minute + degraded),
+		},
+	}
+}
+
+func TestDiffTimelines(t *testing.T) {
+	startA := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	startB := time.Date(2022, 3, 2, 18, 30, 0, 0, time.UTC)
+	// the pod restarts before the upgrade in a and during it in b
+	a := diffTestRun(startA, "apiserver-5d7f9c8b4-x2k9q", "ip-10-0-1-1", 5*time.Minute, 3*time.Second, time.Minute)
+	b := diffTestRun(startB, "apiserver-7c9d4f6b8-zz8lp", "ip-10-0-2-2", 15*time.Minute, 45*time.Second, 4*time.Minute)
+
+	t.Run("start", func(t *testing.T) {
+		diff, err := DiffTimelines(AlignByStart, DefaultDiffWindow, "a", a, "b", b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the restarts are ten minutes apart, everything else starts within the window of its counterpart
+		if len(diff.OnlyInA) != 1 || diff.OnlyInA[0].Phase != "" || diff.OnlyInA[0].Offset != 5*time.Minute || diff.OnlyInA[0].Count != 1 {
+			t.Fatalf("unexpected only in a %#v", diff.OnlyInA)
+		}
+		if len(diff.OnlyInB) != 1 || diff.OnlyInB[0].Offset != 15*time.Minute {
+			t.Fatalf("unexpected only in b %#v", diff.OnlyInB)
+		}
+		if diff.OnlyInA[0].Key != diff.OnlyInB[0].Key {
+			t.Errorf("expected generated names to match, got %q and %q", diff.OnlyInA[0].Key, diff.OnlyInB[0].Key)
+		}
+		if len(diff.Disruption) != 1 {
+			t.Fatalf("unexpected disruption %v", diff.Disruption)
+		}
+		if disruption := diff.Disruption[0]; disruption.Name != "disruption/kube-api connection/new" || disruption.A != 3*time.Second || disruption.B != 45*time.Second || disruption.Delta() != 42*time.Second {
+			t.Errorf("unexpected disruption %#v", disruption)
+		}
+		if len(diff.OperatorConditions) != 1 {
+			t.Fatalf("unexpected operator conditions %v", diff.OperatorConditions)
+		}
+		if degraded := diff.OperatorConditions[0]; degraded.Name != "etcd Degraded" || degraded.A != time.Minute || degraded.B != 4*time.Minute {
+			t.Errorf("unexpected operator conditions %#v", degraded)
+		}
+	})
+
+	t.Run("wide window", func(t *testing.T) {
+		diff, err := DiffTimelines(AlignByStart, 10*time.Minute, "a", a, "b", b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(diff.OnlyInA) != 0 || len(diff.OnlyInB) != 0 {
+			t.Errorf("expected the restarts to match, got only in a %v, only in b %v", diff.OnlyInA, diff.OnlyInB)
+		}
+	})
+
+	t.Run("upgrade", func(t *testing.T) {
+		diff, err := DiffTimelines(AlignByUpgradePhase, DefaultDiffWindow, "a", a, "b", b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(diff.OnlyInA) != 1 || diff.OnlyInA[0].Phase != beforeUpgradePhase || diff.OnlyInA[0].Offset != 5*time.Minute {
+			t.Fatalf("unexpected only in a %#v", diff.OnlyInA)
+		}
+		if len(diff.OnlyInB) != 1 || diff.OnlyInB[0].Phase != "UpgradeStarted" || diff.OnlyInB[0].Offset != 5*time.Minute {
+			t.Fatalf("unexpected only in b %#v", diff.OnlyInB)
+		}
+		if expected := "Warning ns/openshift-apiserver pod/apiserver-* node/* reason/BackOff"; diff.OnlyInB[0].Key != expected {
+			t.Errorf("expected key %q, got %q", expected, diff.OnlyInB[0].Key)
+		}
+
+		text, err := renderDiffText(diff)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, expected := range []string{"UpgradeStarted at +10m0s", "disruption/kube-api connection/new  3s  45s  +42s", "UpgradeStarted+5m0s x1"} {
+			if !strings.Contains(string(text), expected) {
+				t.Errorf("missing %q in\n%s", expected, text)
+			}
+		}
+		html, err := renderDiffHTML(diff)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(html), `<td class="worse">&#43;42s</td>`) {
+			t.Errorf("missing disruption delta in\n%s", html)
+		}
+	})
+
+	t.Run("missing markers", func(t *testing.T) {
+		if _, err := DiffTimelines(AlignByUpgradePhase, DefaultDiffWindow, "a", a, "b", b[2:]); err == nil {
+			t.Error("expected an error for a run without upgrade phase markers")
+		}
+	})
+}
+
+func TestTimelineDiffRenderers(t *testing.T) {
+	a := diffTestRun(time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC), "apiserver-5d7f9c8b4-x2k9q", "ip-10-0-1-1", 5*time.Minute, 3*time.Second, time.Minute)
+	b := diffTestRun(time.Date(2022, 3, 2, 18, 30, 0, 0, time.UTC), "apiserver-7c9d4f6b8-zz8lp", "ip-10-0-2-2", 15*time.Minute, 45*time.Second, 4*time.Minute)
+	intervals := append(withDiffRun(diffRunA, a), withDiffRun(diffRunB, b)...)
+	sort.Sort(intervals)
+
+	o := NewTimelineDiffOptions(genericclioptions.IOStreams{})
+	o.Alignment = string(AlignByUpgradePhase)
+	for _, name := range []string{"diff", "diff-html", "diff-json", "json", "viewer"} {
+		if o.KnownRenderers[name] == nil {
+			t.Errorf("expected the %s renderer", name)
+		}
+	}
+	output, err := o.KnownRenderers["diff-json"](intervals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := &TimelineDiff{}
+	if err := json.Unmarshal(output, diff); err != nil {
+		t.Fatal(err)
+	}
+	// the runs are told apart by their annotation, and compared with the alignment of the options
+	if diff.Alignment != AlignByUpgradePhase || len(diff.OnlyInA) != 1 || len(diff.OnlyInB) != 1 || diff.OnlyInB[0].Phase != "UpgradeStarted" {
+		t.Errorf("unexpected diff %#v", diff)
+	}
+	if run := monitorapi.AnnotationFrom(diff.OnlyInB[0].Example, monitorapi.AnnotationInvocations); run != diffRunB {
+		t.Errorf("expected the example to be from run b, got %q", run)
+	}
+}
@@ -0,0 +1,131 @@
+package monitor_cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+type TimelineMergeOptions struct {
+	MonitorEventFilenames []string
+	TimelineType          string
+	OutputType            string
+
+	KnownRenderers map[string]RenderFunc
+	KnownTimelines map[string]monitorapi.EventIntervalMatchesFunc
+	IOStreams      genericclioptions.IOStreams
+}
+
+func NewTimelineMergeOptions(ioStreams genericclioptions.IOStreams) *TimelineMergeOptions {
+	timelineOptions := NewTimelineOptions(ioStreams)
+	return &TimelineMergeOptions{
+		TimelineType: "everything",
+
+		OutputType: "json",
+
+		IOStreams:      ioStreams,
+		KnownRenderers: timelineOptions.KnownRenderers,
+		KnownTimelines: timelineOptions.KnownTimelines,
+	}
+}
+
+func NewTimelineMergeCommand(ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewTimelineMergeOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "merge",
+		Short: "Merge the monitor events of the openshift-tests invocations of one job",
+		Long: `
+		Merge the monitor events written by several openshift-tests invocations of one job into a single timeline.
+		Intervals with the same locator and message that overlap in time were seen by more than one monitor and are
+		merged, and the invocations annotation of every interval lists the invocations that observed it.  Name an
+		invocation with name=file, otherwise it is named by its file.
+
+		openshift-tests timeline merge -f upgrade=upgrade/e2e-events.json -f conformance=conformance/e2e-events.json > e2e-events.json
+
+		openshift-tests timeline merge -f upgrade/e2e-events.json -f conformance/e2e-events.json -oviewer > timeline.html
+		`,
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	o.Bind(cmd.Flags())
+
+	return cmd
+}
+
+func (o *TimelineMergeOptions) Bind(flagset *pflag.FlagSet) error {
+	flagset.StringArrayVarP(&o.MonitorEventFilenames, "filename", "f", o.MonitorEventFilenames, "monitor events file of an invocation, optionally named as name=file.  Repeat for every invocation.")
+	flagset.StringVarP(&o.OutputType, "output", "o", o.OutputType, fmt.Sprintf("type of output: [%s]", strings.Join(sets.StringKeySet(o.KnownRenderers).List(), ",")))
+	flagset.StringVar(&o.TimelineType, "type", o.TimelineType, "type of timeline to produce: "+strings.Join(sets.StringKeySet(o.KnownTimelines).List(), ","))
+
+	return nil
+}
+
+func (o *TimelineMergeOptions) Validate() error {
+	if len(o.MonitorEventFilenames) < 2 {
+		return fmt.Errorf("at least two -f are required")
+	}
+	if o.KnownRenderers[o.OutputType] == nil {
+		return fmt.Errorf("unknown -o")
+	}
+	if o.KnownTimelines[o.TimelineType] == nil {
+		return fmt.Errorf("unknown --type")
+	}
+	names := sets.NewString()
+	for _, filename := range o.MonitorEventFilenames {
+		name, _ := parseInvocationFilename(filename)
+		if names.Has(name) {
+			return fmt.Errorf("invocation %q is listed more than once", name)
+		}
+		if strings.Contains(name, ",") {
+			return fmt.Errorf("invocation %q cannot contain a comma", name)
+		}
+		names.Insert(name)
+	}
+	return nil
+}
+
+func (o *TimelineMergeOptions) Run() error {
+	invocations := []monitorapi.Invocation{}
+	for _, filename := range o.MonitorEventFilenames {
+		name, path := parseInvocationFilename(filename)
+		events, err := monitorserialization.EventsFromFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		invocations = append(invocations, monitorapi.Invocation{Name: name, Intervals: events})
+	}
+
+	merged := monitorapi.MergeInvocations(invocations...).Filter(o.KnownTimelines[o.TimelineType])
+	output, err := o.KnownRenderers[o.OutputType](merged)
+	if err != nil {
+		return err
+	}
+	if _, err := o.IOStreams.Out.Write(output); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseInvocationFilename splits name=file.  An invocation without a name is named by its file.
+func parseInvocationFilename(filename string) (string, string) {
+	parts := strings.SplitN(filename, "=", 2)
+	if len(parts) == 2 && len(parts[0]) > 0 && !strings.ContainsAny(parts[0], `/\`) {
+		return parts[0], parts[1]
+	}
+	return filename, filename
+}
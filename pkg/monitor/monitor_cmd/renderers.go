@@ -0,0 +1,261 @@
+package monitor_cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// renderCSV writes one row per interval for spreadsheets.  Open intervals have an empty to and duration.
+func renderCSV(events monitorapi.Intervals) ([]byte, error) {
+	return renderDelimited(events, ',')
+}
+
+// renderTSV is renderCSV separated by tabs, which pastes into spreadsheets without an import step.
+func renderTSV(events monitorapi.Intervals) ([]byte, error) {
+	return renderDelimited(events, '\t')
+}
+
+func renderDelimited(events monitorapi.Intervals, separator rune) ([]byte, error) {
+	out := &bytes.Buffer{}
+	w := csv.NewWriter(out)
+	w.Comma = separator
+	if err := w.Write([]string{"from", "to", "durationSeconds", "level", "locatorType", "locator", "message"}); err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		to, duration := "", ""
+		if !event.To.IsZero() {
+			to = event.To.UTC().Format(time.RFC3339Nano)
+			duration = strconv.FormatFloat(event.To.Sub(event.From).Seconds(), 'f', -1, 64)
+		}
+		if err := w.Write([]string{
+			event.From.UTC().Format(time.RFC3339Nano),
+			to,
+			duration,
+			event.Level.String(),
+			string(monitorapi.LocatorFrom(event).Type),
+			event.Locator,
+			event.Message,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return out.Bytes(), w.Error()
+}
+
+// timelineBounds returns the earliest start and latest end of events.  Open intervals last until the end.
+func timelineBounds(events monitorapi.Intervals) (time.Time, time.Time) {
+	var from, to time.Time
+	for _, event := range events {
+		if from.IsZero() || event.From.Before(from) {
+			from = event.From
+		}
+		if event.From.After(to) {
+			to = event.From
+		}
+		if event.To.After(to) {
+			to = event.To
+		}
+	}
+	return from, to
+}
+
+func intervalEnd(event monitorapi.EventInterval, end time.Time) time.Time {
+	if event.To.IsZero() {
+		return end
+	}
+	return event.To
+}
+
+const (
+	ganttLabelWidth = 60
+	ganttBarWidth   = 100
+)
+
+// ganttLevelMarks draws the bars of each level, so the view needs no color support.
+var ganttLevelMarks = map[monitorapi.EventLevel]string{
+	monitorapi.Info:    "-",
+	monitorapi.Warning: "=",
+	monitorapi.Error:   "#",
+}
+
+// renderGantt draws one line per interval for terminals.  Intervals too short to cover a column are drawn as "|".
+func renderGantt(events monitorapi.Intervals) ([]byte, error) {
+	out := &bytes.Buffer{}
+	from, to := timelineBounds(events)
+	span := to.Sub(from)
+	if span <= 0 {
+		span = time.Second
+	}
+	column := func(t time.Time) int {
+		ret := int(float64(t.Sub(from)) / float64(span) * ganttBarWidth)
+		if ret >= ganttBarWidth {
+			ret = ganttBarWidth - 1
+		}
+		return ret
+	}
+
+	fmt.Fprintf(out, "%-*s %s\n", ganttLabelWidth, "", ganttAxis(from, to))
+	for _, event := range events {
+		label := event.Locator
+		if len(label) > ganttLabelWidth {
+			label = "..." + label[len(label)-ganttLabelWidth+3:]
+		}
+		start, end := column(event.From), column(intervalEnd(event, to))
+		mark := ganttLevelMarks[event.Level]
+		bar := strings.Repeat(" ", start)
+		if end > start {
+			bar += strings.Repeat(mark, end-start)
+		} else {
+			bar += "|"
+		}
+		fmt.Fprintf(out, "%-*s %s\n", ganttLabelWidth, label, bar)
+	}
+	fmt.Fprintf(out, "%-*s %s\n", ganttLabelWidth, "", "- Info   = Warning   # Error")
+	return out.Bytes(), nil
+}
+
+// ganttAxis labels the start, middle and end of the bars.
+func ganttAxis(from, to time.Time) string {
+	start := from.UTC().Format("15:04:05")
+	middle := from.Add(to.Sub(from) / 2).UTC().Format("15:04:05")
+	end := to.UTC().Format("15:04:05")
+	half := ganttBarWidth / 2
+	return fmt.Sprintf("%-*s%-*s%s", half-len(middle)/2, start, ganttBarWidth-half+len(middle)/2-len(end), middle, end)
+}
+
+// mermaidUnsafe are the characters that end a task name or are comments in mermaid gantt charts.
+var mermaidUnsafe = strings.NewReplacer(":", " ", "#", " ", ";", " ", "%", " ", "\n", " ")
+
+// renderMermaid writes a mermaid gantt chart with a section per locator type, for pasting into bugs.  Errors are
+// critical tasks, warnings active tasks and instants milestones.
+func renderMermaid(events monitorapi.Intervals) ([]byte, error) {
+	const dateFormat = "2006-01-02T15:04:05"
+	_, to := timelineBounds(events)
+
+	byLocatorType := map[string]monitorapi.Intervals{}
+	for _, event := range events {
+		locatorType := string(monitorapi.LocatorFrom(event).Type)
+		if len(locatorType) == 0 {
+			locatorType = string(monitorapi.LocatorTypeOther)
+		}
+		byLocatorType[locatorType] = append(byLocatorType[locatorType], event)
+	}
+	locatorTypes := []string{}
+	for locatorType := range byLocatorType {
+		locatorTypes = append(locatorTypes, locatorType)
+	}
+	sort.Strings(locatorTypes)
+
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, "gantt\n")
+	fmt.Fprintf(out, "    title Timeline\n")
+	fmt.Fprintf(out, "    dateFormat YYYY-MM-DDTHH:mm:ss\n")
+	fmt.Fprintf(out, "    axisFormat %%H:%%M\n")
+	for _, locatorType := range locatorTypes {
+		fmt.Fprintf(out, "    section %s\n", locatorType)
+		for _, event := range byLocatorType[locatorType] {
+			name := strings.TrimSpace(mermaidUnsafe.Replace(event.Locator + " " + event.Message))
+			start, end := event.From.UTC(), intervalEnd(event, to).UTC()
+
+			fields := []string{}
+			switch event.Level {
+			case monitorapi.Error:
+				fields = append(fields, "crit")
+			case monitorapi.Warning:
+				fields = append(fields, "active")
+			}
+			if end.After(start) {
+				fields = append(fields, start.Format(dateFormat), end.Format(dateFormat))
+			} else {
+				fields = append(fields, "milestone", start.Format(dateFormat), "0s")
+			}
+			fmt.Fprintf(out, "    %s :%s\n", name, strings.Join(fields, ", "))
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// chromeTraceEvent is an entry of the trace event format read by chrome://tracing and Perfetto.  Times are in
+// microseconds.
+type chromeTraceEvent struct {
+	Name     string            `json:"name"`
+	Category string            `json:"cat,omitempty"`
+	Phase    string            `json:"ph"`
+	Scope    string            `json:"s,omitempty"`
+	Time     int64             `json:"ts"`
+	Duration *int64            `json:"dur,omitempty"`
+	PID      int               `json:"pid"`
+	TID      int               `json:"tid"`
+	Args     map[string]string `json:"args,omitempty"`
+}
+
+// renderChromeTrace writes events in the trace event format with a process per locator type and a thread per
+// locator, so every resource gets its own track.
+func renderChromeTrace(events monitorapi.Intervals) ([]byte, error) {
+	_, to := timelineBounds(events)
+	pids := map[string]int{}
+	tids := map[string]int{}
+	traceEvents := []chromeTraceEvent{}
+	for _, event := range events {
+		locatorType := string(monitorapi.LocatorFrom(event).Type)
+		if len(locatorType) == 0 {
+			locatorType = string(monitorapi.LocatorTypeOther)
+		}
+		pid, ok := pids[locatorType]
+		if !ok {
+			pid = len(pids) + 1
+			pids[locatorType] = pid
+			traceEvents = append(traceEvents, chromeTraceEvent{Name: "process_name", Phase: "M", PID: pid, Args: map[string]string{"name": locatorType}})
+		}
+		tid, ok := tids[event.Locator]
+		if !ok {
+			tid = len(tids) + 1
+			tids[event.Locator] = tid
+			traceEvents = append(traceEvents, chromeTraceEvent{Name: "thread_name", Phase: "M", PID: pid, TID: tid, Args: map[string]string{"name": event.Locator}})
+		}
+
+		name := event.Message
+		if reason := monitorapi.AnnotationFrom(event, monitorapi.AnnotationReason); len(reason) > 0 {
+			name = reason
+		}
+		traceEvent := chromeTraceEvent{
+			Name:     name,
+			Category: event.Level.String(),
+			Time:     event.From.UnixNano() / int64(time.Microsecond),
+			PID:      pid,
+			TID:      tid,
+			Args: map[string]string{
+				"locator": event.Locator,
+				"message": event.Message,
+			},
+		}
+		end := intervalEnd(event, to)
+		if end.After(event.From) {
+			duration := end.Sub(event.From).Microseconds()
+			traceEvent.Phase = "X"
+			traceEvent.Duration = &duration
+		} else {
+			traceEvent.Phase = "i"
+			traceEvent.Scope = "t"
+		}
+		traceEvents = append(traceEvents, traceEvent)
+	}
+
+	return json.MarshalIndent(struct {
+		TraceEvents     []chromeTraceEvent `json:"traceEvents"`
+		DisplayTimeUnit string             `json:"displayTimeUnit"`
+	}{
+		TraceEvents:     traceEvents,
+		DisplayTimeUnit: "ms",
+	}, "", "    ")
+}
@@ -0,0 +1,136 @@
+package monitor_cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func rendererTestIntervals() monitorapi.Intervals {
+	start := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	return monitorapi.Intervals{
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Error, Locator: "disruption/kube-api connection/new", Message: "kube-api stopped responding: EOF, \"retrying\""},
+			From:      start, To: start.Add(10 * time.Second),
+		},
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Warning, Locator: "ns/openshift-etcd pod/etcd-0 node/a", Message: "reason/Unhealthy probe failed"},
+			From:      start.Add(5 * time.Second), To: start.Add(5 * time.Second),
+		},
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "node/a", Message: "reason/NodeUpdate"},
+			From:      start.Add(20 * time.Second),
+		},
+	}
+}
+
+func TestRenderDelimited(t *testing.T) {
+	for name, render := range map[string]RenderFunc{"csv": renderCSV, "tsv": renderTSV} {
+		t.Run(name, func(t *testing.T) {
+			data, err := render(rendererTestIntervals())
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := csv.NewReader(strings.NewReader(string(data)))
+			if name == "tsv" {
+				r.Comma = '\t'
+			}
+			rows, err := r.ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 4 {
+				t.Fatalf("expected a header and 3 rows, got %v", rows)
+			}
+			expected := []string{"2022-03-01T10:00:00Z", "2022-03-01T10:00:10Z", "10", "Error", "Disruption", "disruption/kube-api connection/new", "kube-api stopped responding: EOF, \"retrying\""}
+			if strings.Join(rows[1], "|") != strings.Join(expected, "|") {
+				t.Errorf("expected %q, got %q", expected, rows[1])
+			}
+			if rows[3][1] != "" || rows[3][2] != "" {
+				t.Errorf("expected an open interval to have no end, got %q", rows[3])
+			}
+		})
+	}
+}
+
+func TestRenderGantt(t *testing.T) {
+	data, err := renderGantt(rendererTestIntervals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected an axis, 3 intervals and a legend, got\n%s", data)
+	}
+	bar := func(line string) string {
+		return line[ganttLabelWidth+1:]
+	}
+	if expected := strings.Repeat("#", 50); bar(lines[1]) != expected {
+		t.Errorf("expected the disruption to cover the first half, got %q", bar(lines[1]))
+	}
+	if expected := strings.Repeat(" ", 25) + "|"; bar(lines[2]) != expected {
+		t.Errorf("expected the instant at a quarter, got %q", bar(lines[2]))
+	}
+	if !strings.HasPrefix(bar(lines[0]), "10:00:00") || !strings.HasSuffix(lines[0], "10:00:20") {
+		t.Errorf("unexpected axis %q", lines[0])
+	}
+}
+
+func TestRenderMermaid(t *testing.T) {
+	data, err := renderMermaid(rendererTestIntervals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"    section Disruption\n    disruption/kube-api connection/new kube-api stopped responding  EOF, \"retrying\" :crit, 2022-03-01T10:00:00, 2022-03-01T10:00:10\n",
+		"    section Node\n    node/a reason/NodeUpdate :milestone, 2022-03-01T10:00:20, 0s\n",
+		"    ns/openshift-etcd pod/etcd-0 node/a reason/Unhealthy probe failed :active, milestone, 2022-03-01T10:00:05, 0s\n",
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("missing %q in\n%s", expected, data)
+		}
+	}
+}
+
+func TestRenderChromeTrace(t *testing.T) {
+	data, err := renderChromeTrace(rendererTestIntervals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	trace := struct {
+		TraceEvents []chromeTraceEvent `json:"traceEvents"`
+	}{}
+	if err := json.Unmarshal(data, &trace); err != nil {
+		t.Fatal(err)
+	}
+
+	durations := map[string]int64{}
+	names := map[string]bool{}
+	for _, event := range trace.TraceEvents {
+		switch event.Phase {
+		case "M":
+			names[event.Args["name"]] = true
+		case "X":
+			durations[event.Name] = *event.Duration
+		case "i":
+			durations[event.Name] = 0
+		default:
+			t.Errorf("unexpected phase %q", event.Phase)
+		}
+	}
+	if durations["kube-api stopped responding: EOF, \"retrying\""] != 10000000 {
+		t.Errorf("expected a ten second disruption, got %v", durations)
+	}
+	if _, ok := durations["Unhealthy"]; !ok {
+		t.Errorf("expected the instant to be named by reason, got %v", durations)
+	}
+	for _, expected := range []string{"Disruption", "Pod", "Node", "node/a", "ns/openshift-etcd pod/etcd-0 node/a"} {
+		if !names[expected] {
+			t.Errorf("missing track %q in %v", expected, names)
+		}
+	}
+}
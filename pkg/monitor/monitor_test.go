@@ -1,11 +1,14 @@
 package monitor
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
 	"k8s.io/apimachinery/pkg/util/diff"
 )
 
@@ -110,3 +113,51 @@ func TestMonitor_Events(t *testing.T) {
 		})
 	}
 }
+
+func TestMonitor_Journal(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "e2e-events-journal.jsonl")
+	journal, err := monitorserialization.NewJournalWriter(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+
+	m := NewMonitorWithJournal(0, journal)
+	start := time.Date(2022, 1, 1, 0, 0, 0, 500, time.UTC)
+	m.RecordAt(start, monitorapi.Condition{Level: monitorapi.Warning, Locator: "ns/a pod/b", Message: "reason/Unhealthy probe failed"})
+	closed := m.StartInterval(start.Add(time.Second), monitorapi.Condition{Level: monitorapi.Error, Locator: "disruption/kube-api connection/new", Message: "down"})
+	m.StartInterval(start.Add(2*time.Second), monitorapi.Condition{Level: monitorapi.Info, Locator: "node/a", Message: "still open"})
+	m.EndInterval(closed, start.Add(3*time.Second))
+
+	want := monitorapi.Intervals{
+		{Condition: monitorapi.EnsureStructured(monitorapi.Condition{Level: monitorapi.Warning, Locator: "ns/a pod/b", Message: "reason/Unhealthy probe failed"}), From: start, To: start},
+		{Condition: monitorapi.EnsureStructured(monitorapi.Condition{Level: monitorapi.Error, Locator: "disruption/kube-api connection/new", Message: "down"}), From: start.Add(time.Second), To: start.Add(3 * time.Second)},
+		{Condition: monitorapi.EnsureStructured(monitorapi.Condition{Level: monitorapi.Info, Locator: "node/a", Message: "still open"}), From: start.Add(2 * time.Second)},
+	}
+	if len(m.unsortedEvents) != 0 || len(m.events) != 0 {
+		t.Fatalf("expected journaled intervals to not be held in memory, got %d", len(m.unsortedEvents)+len(m.events))
+	}
+	if got := m.Intervals(time.Time{}, time.Time{}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("%s", diff.ObjectReflectDiff(want, got))
+	}
+	if err := m.JournalErr(); err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate a crash part way through writing a line
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"op":"record","level":"Info","locator":"node/a","mess`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	got, err := monitorserialization.EventsFromFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("%s", diff.ObjectReflectDiff(want, got))
+	}
+}
@@ -0,0 +1,259 @@
+package monitorapi
+
+import (
+	"sort"
+	"time"
+)
+
+// The operations in this file treat each interval as the span of time between From and To.  Two intervals overlap
+// when each starts before the other ends, so intervals that only touch do not overlap and an instant (From == To)
+// only overlaps intervals that strictly contain it.  Intervals that have not ended (zero To) are treated as instants;
+// use Clamp first to give them an end.
+
+// endOf returns the end of the interval, treating open intervals as instants.
+func endOf(interval EventInterval) time.Time {
+	if interval.To.IsZero() || interval.To.Before(interval.From) {
+		return interval.From
+	}
+	return interval.To
+}
+
+// Merge returns a sorted copy of intervals where intervals with the same locator that overlap or touch are coalesced
+// into a single interval.  The coalesced interval keeps the condition of the earliest interval.
+func (intervals Intervals) Merge() Intervals {
+	byLocator := map[string]Intervals{}
+	locators := []string{}
+	for _, interval := range intervals {
+		if _, ok := byLocator[interval.Locator]; !ok {
+			locators = append(locators, interval.Locator)
+		}
+		byLocator[interval.Locator] = append(byLocator[interval.Locator], interval)
+	}
+
+	merged := make(Intervals, 0, len(intervals))
+	for _, locator := range locators {
+		current := byLocator[locator].CopyAndSort(time.Time{}, time.Time{})
+		merged = append(merged, coalesce(current)...)
+	}
+	sort.Sort(merged)
+	return merged
+}
+
+// coalesce merges overlapping and touching intervals in a sorted list.
+func coalesce(sorted Intervals) Intervals {
+	ret := Intervals{}
+	for _, interval := range sorted {
+		interval.To = endOf(interval)
+		if len(ret) > 0 {
+			last := &ret[len(ret)-1]
+			if !interval.From.After(last.To) {
+				if interval.To.After(last.To) {
+					last.To = interval.To
+				}
+				continue
+			}
+		}
+		ret = append(ret, interval)
+	}
+	return ret
+}
+
+// union flattens intervals into a sorted list of disjoint spans, ignoring conditions.
+func union(intervals Intervals) Intervals {
+	spans := make(Intervals, 0, len(intervals))
+	for _, interval := range intervals {
+		if !interval.From.Before(endOf(interval)) {
+			continue
+		}
+		spans = append(spans, EventInterval{From: interval.From, To: endOf(interval)})
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].From.Before(spans[j].From)
+	})
+	return coalesce(spans)
+}
+
+// Intersect returns the parts of intervals that overlap any interval in other.  Each result keeps the condition of
+// the interval it was cut from.  An interval that overlaps several intervals in other produces several results.
+// Instants have no duration and are never returned.
+func (intervals Intervals) Intersect(other Intervals) Intervals {
+	spans := union(other)
+	ret := Intervals{}
+	for _, interval := range intervals {
+		end := endOf(interval)
+		for i := firstSpanEndingAfter(spans, interval.From); i < len(spans) && spans[i].From.Before(end); i++ {
+			cut := interval
+			if spans[i].From.After(cut.From) {
+				cut.From = spans[i].From
+			}
+			cut.To = end
+			if spans[i].To.Before(cut.To) {
+				cut.To = spans[i].To
+			}
+			if cut.From.Before(cut.To) {
+				ret = append(ret, cut)
+			}
+		}
+	}
+	return ret
+}
+
+// Subtract returns the parts of intervals that do not overlap any interval in other.  Each result keeps the condition
+// of the interval it was cut from.  Instants are kept unless they fall strictly inside an interval in other.
+func (intervals Intervals) Subtract(other Intervals) Intervals {
+	spans := union(other)
+	ret := Intervals{}
+	for _, interval := range intervals {
+		end := endOf(interval)
+		i := firstSpanEndingAfter(spans, interval.From)
+		if !interval.From.Before(end) {
+			if i >= len(spans) || !spans[i].From.Before(interval.From) {
+				ret = append(ret, interval)
+			}
+			continue
+		}
+
+		remaining := interval
+		remaining.To = end
+		for ; i < len(spans) && spans[i].From.Before(end); i++ {
+			if spans[i].From.After(remaining.From) {
+				before := remaining
+				before.To = spans[i].From
+				ret = append(ret, before)
+			}
+			remaining.From = spans[i].To
+		}
+		if remaining.From.Before(remaining.To) {
+			ret = append(ret, remaining)
+		}
+	}
+	return ret
+}
+
+// Gaps returns the spans within [from,to) that are not covered by any interval.  The returned intervals have an empty
+// condition.
+func (intervals Intervals) Gaps(from, to time.Time) Intervals {
+	window := Intervals{{From: from, To: to}}
+	return window.Subtract(intervals)
+}
+
+// firstSpanEndingAfter returns the index of the first span in a sorted, disjoint list that ends after t.
+func firstSpanEndingAfter(spans Intervals, t time.Time) int {
+	return sort.Search(len(spans), func(i int) bool {
+		return spans[i].To.After(t)
+	})
+}
+
+// OverlapsWith returns the intervals that overlap at least one interval in other, in their original order.  It runs
+// in O((n+m) log m).
+func (intervals Intervals) OverlapsWith(other Intervals) Intervals {
+	tree := NewIntervalTree(other)
+	ret := Intervals{}
+	for _, interval := range intervals {
+		if tree.Overlaps(interval.From, endOf(interval)) {
+			ret = append(ret, interval)
+		}
+	}
+	return ret
+}
+
+// IntervalTree answers overlap queries against a fixed set of intervals in O(log n + k).  Build it once with
+// NewIntervalTree and query it repeatedly instead of scanning every interval for every query.
+type IntervalTree struct {
+	intervals Intervals
+	// nodes are sorted by From and form an implicit balanced tree: the root of nodes[lo:hi] is at (lo+hi)/2.
+	nodes []intervalTreeNode
+}
+
+type intervalTreeNode struct {
+	from time.Time
+	end  time.Time
+	// maxEnd is the latest end in the subtree rooted at this node.
+	maxEnd time.Time
+	// index is the position of the interval in the slice passed to NewIntervalTree.
+	index int
+}
+
+// NewIntervalTree builds a tree over a copy of intervals.
+func NewIntervalTree(intervals Intervals) *IntervalTree {
+	nodes := make([]intervalTreeNode, 0, len(intervals))
+	for i, interval := range intervals {
+		nodes = append(nodes, intervalTreeNode{from: interval.From, end: endOf(interval), index: i})
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].from.Before(nodes[j].from)
+	})
+	tree := &IntervalTree{intervals: append(Intervals{}, intervals...), nodes: nodes}
+	tree.computeMaxEnd(0, len(nodes))
+	return tree
+}
+
+func (t *IntervalTree) computeMaxEnd(lo, hi int) time.Time {
+	if lo >= hi {
+		return time.Time{}
+	}
+	mid := (lo + hi) / 2
+	maxEnd := t.nodes[mid].end
+	if left := t.computeMaxEnd(lo, mid); left.After(maxEnd) {
+		maxEnd = left
+	}
+	if right := t.computeMaxEnd(mid+1, hi); right.After(maxEnd) {
+		maxEnd = right
+	}
+	t.nodes[mid].maxEnd = maxEnd
+	return maxEnd
+}
+
+// Overlapping returns the intervals that share any time with [from,to), in the order they were passed to
+// NewIntervalTree.  When from == to, it returns the intervals that strictly contain that instant.
+func (t *IntervalTree) Overlapping(from, to time.Time) Intervals {
+	indexes := []int{}
+	t.visit(0, len(t.nodes), from, to, func(node intervalTreeNode) bool {
+		indexes = append(indexes, node.index)
+		return true
+	})
+	sort.Ints(indexes)
+
+	ret := make(Intervals, 0, len(indexes))
+	for _, index := range indexes {
+		ret = append(ret, t.intervals[index])
+	}
+	return ret
+}
+
+// Overlaps returns true if any interval shares time with [from,to).  When from == to, it returns true if any
+// interval strictly contains that instant.
+func (t *IntervalTree) Overlaps(from, to time.Time) bool {
+	found := false
+	t.visit(0, len(t.nodes), from, to, func(intervalTreeNode) bool {
+		found = true
+		return false
+	})
+	return found
+}
+
+// visit calls fn for every node overlapping [from,to) until fn returns false.  It returns false if it was stopped.
+func (t *IntervalTree) visit(lo, hi int, from, to time.Time, fn func(intervalTreeNode) bool) bool {
+	if lo >= hi {
+		return true
+	}
+	mid := (lo + hi) / 2
+	node := t.nodes[mid]
+	// nothing in this subtree ends after from
+	if !node.maxEnd.After(from) {
+		return true
+	}
+	if !t.visit(lo, mid, from, to, fn) {
+		return false
+	}
+	// this node and everything to its right starts too late
+	if !node.from.Before(to) {
+		return true
+	}
+	if node.end.After(from) {
+		if !fn(node) {
+			return false
+		}
+	}
+	return t.visit(mid+1, hi, from, to, fn)
+}
@@ -0,0 +1,127 @@
+package monitorapi
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func at(seconds int) time.Time {
+	return time.Date(2022, 1, 1, 0, 0, seconds, 0, time.UTC)
+}
+
+func span(locator string, from, to int) EventInterval {
+	return EventInterval{Condition: Condition{Locator: locator, Message: locator}, From: at(from), To: at(to)}
+}
+
+func TestIntervals_Merge(t *testing.T) {
+	intervals := Intervals{
+		span("node/a", 5, 8),
+		span("node/a", 0, 2),
+		span("node/b", 1, 3),
+		span("node/a", 2, 4),
+		span("node/a", 3, 3),
+		span("node/b", 4, 6),
+	}
+	want := Intervals{
+		span("node/a", 0, 4),
+		span("node/b", 1, 3),
+		span("node/b", 4, 6),
+		span("node/a", 5, 8),
+	}
+	if got := intervals.Merge(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected\n%v\ngot\n%v", want.Strings(), got.Strings())
+	}
+}
+
+func TestIntervals_IntersectSubtract(t *testing.T) {
+	intervals := Intervals{
+		span("a", 0, 10),
+		span("b", 12, 14),
+		span("instant-inside", 5, 5),
+		span("instant-outside", 16, 16),
+	}
+	other := Intervals{
+		span("x", 2, 4),
+		span("y", 3, 6),
+		span("z", 8, 13),
+	}
+
+	wantIntersect := Intervals{
+		span("a", 2, 6),
+		span("a", 8, 10),
+		span("b", 12, 13),
+	}
+	if got := intervals.Intersect(other); !reflect.DeepEqual(got, wantIntersect) {
+		t.Errorf("intersect: expected\n%v\ngot\n%v", wantIntersect.Strings(), got.Strings())
+	}
+
+	wantSubtract := Intervals{
+		span("a", 0, 2),
+		span("a", 6, 8),
+		span("b", 13, 14),
+		span("instant-outside", 16, 16),
+	}
+	if got := intervals.Subtract(other); !reflect.DeepEqual(got, wantSubtract) {
+		t.Errorf("subtract: expected\n%v\ngot\n%v", wantSubtract.Strings(), got.Strings())
+	}
+
+	wantGaps := Intervals{
+		{From: at(0), To: at(2)},
+		{From: at(6), To: at(8)},
+		{From: at(13), To: at(20)},
+	}
+	if got := other.Gaps(at(0), at(20)); !reflect.DeepEqual(got, wantGaps) {
+		t.Errorf("gaps: expected\n%v\ngot\n%v", wantGaps.Strings(), got.Strings())
+	}
+}
+
+func TestIntervals_OverlapsWith(t *testing.T) {
+	intervals := Intervals{
+		span("touching", 0, 2),
+		span("overlapping", 3, 5),
+		span("instant-inside", 7, 7),
+		span("instant-on-edge", 6, 6),
+		span("after", 20, 30),
+	}
+	other := Intervals{
+		span("x", 2, 4),
+		span("y", 6, 9),
+	}
+	want := Intervals{
+		span("overlapping", 3, 5),
+		span("instant-inside", 7, 7),
+	}
+	if got := intervals.OverlapsWith(other); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected\n%v\ngot\n%v", want.Strings(), got.Strings())
+	}
+}
+
+// TestIntervalTree_MatchesLinearScan compares the tree against the obvious nested loop.
+func TestIntervalTree_MatchesLinearScan(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	intervals := Intervals{}
+	for i := 0; i < 200; i++ {
+		from := r.Intn(1000)
+		intervals = append(intervals, span("x", from, from+r.Intn(50)))
+	}
+	tree := NewIntervalTree(intervals)
+
+	for i := 0; i < 500; i++ {
+		from := r.Intn(1100)
+		to := from + r.Intn(30)
+		want := Intervals{}
+		for _, interval := range intervals {
+			if interval.From.Before(at(to)) && at(from).Before(interval.To) {
+				want = append(want, interval)
+			}
+		}
+		if got := tree.Overlapping(at(from), at(to)); !reflect.DeepEqual(got, want) {
+			t.Fatalf("[%d,%d): expected\n%v\ngot\n%v", from, to, want.Strings(), got.Strings())
+		}
+		if got := tree.Overlaps(at(from), at(to)); got != (len(want) > 0) {
+			t.Fatalf("[%d,%d): expected overlaps=%v", from, to, len(want) > 0)
+		}
+	}
+}
@@ -14,13 +14,13 @@ func BackendDisruptionSeconds(locator string, events Intervals) (time.Duration,
 		),
 	)
 	disruptionMessages := disruptionEvents.Strings()
-	connectionType := DisruptionConnectionTypeFrom(LocatorParts(locator))
+	connectionType := DisruptionConnectionTypeFrom(ParseLocator(locator))
 
 	return disruptionEvents.Duration(1 * time.Second).Round(time.Second), disruptionMessages, connectionType
 }
 
 func IsDisruptionEvent(eventInterval EventInterval) bool {
-	if disruptionBackend := DisruptionFrom(LocatorParts(eventInterval.Locator)); len(disruptionBackend) > 0 {
+	if disruptionBackend := DisruptionFrom(LocatorFrom(eventInterval)); len(disruptionBackend) > 0 {
 		return true
 	}
 	return false
@@ -1,13 +1,11 @@
 package monitorapi
 
-import (
-	"fmt"
-	"strconv"
-	"strings"
-)
-
 func E2ETestLocator(testName string) string {
-	return fmt.Sprintf("e2e-test/%q", testName)
+	return NewE2ETestLocator(testName).OldLocator()
+}
+
+func NewE2ETestLocator(testName string) Locator {
+	return NewLocator(LocatorPart{Key: LocatorE2ETestKey, Value: testName})
 }
 
 func IsE2ETest(locator string) bool {
@@ -16,20 +14,15 @@ func IsE2ETest(locator string) bool {
 }
 
 func E2ETestFromLocator(locator string) (string, bool) {
-	if !strings.HasPrefix(locator, "e2e-test/") {
-		return "", false
-	}
-	parts := strings.SplitN(locator, "/", 2)
-	quotedTestName := parts[1]
-	testName, err := strconv.Unquote(quotedTestName)
-	if err != nil {
-		return "", false
-	}
-	return testName, true
+	return leadingValue(ParseLocator(locator), LocatorE2ETestKey)
 }
 
 func NodeLocator(testName string) string {
-	return fmt.Sprintf("node/%v", testName)
+	return NewNodeLocator(testName).OldLocator()
+}
+
+func NewNodeLocator(nodeName string) Locator {
+	return NewLocator(LocatorPart{Key: LocatorNodeKey, Value: nodeName})
 }
 
 func IsNode(locator string) bool {
@@ -38,15 +31,15 @@ func IsNode(locator string) bool {
 }
 
 func NodeFromLocator(locator string) (string, bool) {
-	if !strings.HasPrefix(locator, "node/") {
-		return "", false
-	}
-	parts := strings.SplitN(strings.TrimPrefix(locator, "node/"), " ", 2)
-	return parts[0], true
+	return leadingValue(ParseLocator(locator), LocatorNodeKey)
 }
 
 func OperatorLocator(testName string) string {
-	return fmt.Sprintf("clusteroperator/%v", testName)
+	return NewOperatorLocator(testName).OldLocator()
+}
+
+func NewOperatorLocator(operatorName string) Locator {
+	return NewLocator(LocatorPart{Key: LocatorClusterOperatorKey, Value: operatorName})
 }
 
 func IsOperator(locator string) bool {
@@ -55,64 +48,63 @@ func IsOperator(locator string) bool {
 }
 
 func OperatorFromLocator(locator string) (string, bool) {
-	if !strings.HasPrefix(locator, "clusteroperator/") {
+	return leadingValue(ParseLocator(locator), LocatorClusterOperatorKey)
+}
+
+// MonitorReasonIngestionLag means the monitor handled a notification long after the watch delivered it, so
+// intervals around that time may be late or missing.
+const MonitorReasonIngestionLag = "IngestionLag"
+
+// MonitorLocator locates the monitor itself, source is the informer or recorder the condition is about.
+func MonitorLocator(source string) string {
+	return NewLocator(LocatorPart{Key: LocatorMonitorKey, Value: source}).OldLocator()
+}
+
+// leadingValue returns the value of key if it is the first part of the locator.
+func leadingValue(locator Locator, key LocatorKey) (string, bool) {
+	if len(locator.Parts) == 0 || locator.Parts[0].Key != key {
 		return "", false
 	}
-	parts := strings.SplitN(strings.TrimPrefix(locator, "clusteroperator/"), " ", 2)
-	return parts[0], true
+	return locator.Parts[0].Value, true
 }
 
-func LocatorParts(locator string) map[string]string {
-	parts := map[string]string{}
-
-	tags := strings.Split(locator, " ")
-	for _, tag := range tags {
-		keyValue := strings.SplitN(tag, "/", 2)
-		if len(keyValue) == 1 {
-			parts[keyValue[0]] = ""
-		} else {
-			parts[keyValue[0]] = keyValue[1]
-		}
+// LocatorFrom returns the structured locator of the eventInterval, parsing the legacy locator if the
+// structured form was never filled in.
+func LocatorFrom(eventInterval EventInterval) Locator {
+	if !eventInterval.StructuredLocator.IsZero() {
+		return eventInterval.StructuredLocator
 	}
+	return ParseLocator(eventInterval.Locator)
+}
 
-	return parts
+// LocatorParts returns the key/value pairs of the locator.  Prefer ParseLocator, which preserves order and
+// repeated keys.
+func LocatorParts(locator string) map[string]string {
+	return ParseLocator(locator).Map()
 }
 
-func NamespaceFrom(locatorParts map[string]string) string {
-	if ns, ok := locatorParts["ns"]; ok {
-		return ns
-	}
-	if ns, ok := locatorParts["namespace"]; ok {
-		return ns
-	}
-	return ""
+func NamespaceFrom(locator Locator) string {
+	return locator.Namespace()
 }
 
 func NamespaceFromLocator(locator string) string {
-	locatorParts := LocatorParts(locator)
-	if ns, ok := locatorParts["ns"]; ok {
-		return ns
-	}
-	if ns, ok := locatorParts["namespace"]; ok {
-		return ns
-	}
-	return ""
+	return NamespaceFrom(ParseLocator(locator))
 }
 
 func AlertFromLocator(locator string) string {
-	return AlertFrom(LocatorParts(locator))
+	return AlertFrom(ParseLocator(locator))
 }
 
-func AlertFrom(locatorParts map[string]string) string {
-	return locatorParts["alert"]
+func AlertFrom(locator Locator) string {
+	return locator.Get(LocatorAlertKey)
 }
 
-func DisruptionFrom(locatorParts map[string]string) string {
-	return locatorParts["disruption"]
+func DisruptionFrom(locator Locator) string {
+	return locator.Get(LocatorDisruptionKey)
 }
 
-func DisruptionConnectionTypeFrom(locatorParts map[string]string) string {
-	return locatorParts["connection"]
+func DisruptionConnectionTypeFrom(locator Locator) string {
+	return locator.Get(LocatorConnectionKey)
 }
 
 func IsEventForLocator(locator string) EventIntervalMatchesFunc {
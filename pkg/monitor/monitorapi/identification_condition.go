@@ -0,0 +1,47 @@
+package monitorapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConditionChangedMessage describes an entry in status.conditions the same way the clusteroperator monitor does, so
+// GetOperatorConditionStatus can read it back.
+func ConditionChangedMessage(conditionType, status, reason, message string) string {
+	if len(reason) > 0 {
+		return fmt.Sprintf("condition/%s status/%s reason/%s changed: %s", conditionType, status, reason, message)
+	}
+	return fmt.Sprintf("condition/%s status/%s changed: %s", conditionType, status, message)
+}
+
+// negativePolarityConditionSuffixes are condition types that describe a problem when they are True.
+var negativePolarityConditionSuffixes = []string{
+	"Degraded",
+	"Updating",
+	"Failing",
+	"Failed",
+	"Pressure",
+	"Unavailable",
+}
+
+// IsAbnormalCondition guesses whether a status.conditions entry describes something going wrong, without knowing
+// the resource it is on.  Conditions like Degraded, Updating or MemoryPressure are abnormal when True, every other
+// condition (Available, Ready, Updated, ...) is abnormal when it is not True.  Progressing is left out, since a
+// healthy deployment reports Progressing=True for as long as it exists; see IsAbnormalOperatorCondition.
+func IsAbnormalCondition(conditionType, status string) bool {
+	for _, suffix := range negativePolarityConditionSuffixes {
+		if strings.HasSuffix(conditionType, suffix) {
+			return status == "True"
+		}
+	}
+	return status != "True"
+}
+
+// IsAbnormalOperatorCondition is IsAbnormalCondition for the conditions of a clusteroperator, which is only
+// Progressing while it rolls out a change.
+func IsAbnormalOperatorCondition(conditionType, status string) bool {
+	if conditionType == "Progressing" {
+		return status == "True"
+	}
+	return IsAbnormalCondition(conditionType, status)
+}
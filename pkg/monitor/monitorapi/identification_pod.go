@@ -10,27 +10,46 @@ import (
 )
 
 func LocatePod(pod *corev1.Pod) string {
-	return fmt.Sprintf("ns/%s pod/%s node/%s uid/%s", pod.Namespace, pod.Name, pod.Spec.NodeName, pod.UID)
+	return NewPodLocator(pod).OldLocator()
+}
+
+func NewPodLocator(pod *corev1.Pod) Locator {
+	return NewLocator(
+		LocatorPart{Key: LocatorNamespaceKey, Value: pod.Namespace},
+		LocatorPart{Key: LocatorPodKey, Value: pod.Name},
+		LocatorPart{Key: LocatorNodeKey, Value: pod.Spec.NodeName},
+		LocatorPart{Key: LocatorUIDKey, Value: string(pod.UID)},
+	)
 }
 
 func LocatePodContainer(pod *corev1.Pod, containerName string) string {
-	return fmt.Sprintf("ns/%s pod/%s node/%s uid/%s container/%s", pod.Namespace, pod.Name, pod.Spec.NodeName, pod.UID, containerName)
+	return NewPodContainerLocator(pod, containerName).OldLocator()
+}
+
+func NewPodContainerLocator(pod *corev1.Pod, containerName string) Locator {
+	podLocator := NewPodLocator(pod)
+	return NewLocator(append(podLocator.Parts, LocatorPart{Key: LocatorContainerKey, Value: containerName})...)
 }
 
 // NonUniquePodLocator produces an inexact locator based on namespace and name.  This is useful when dealing with events
 // that are produced that do not contain UIDs.  Ultimately, we should use UIDs everywhere, but this is will keep some our
 // matching working until then.
 func NonUniquePodLocatorFrom(locator string) string {
-	parts := LocatorParts(locator)
-	namespace := NamespaceFrom(parts)
-	return fmt.Sprintf("ns/%s pod/%s", namespace, parts["pod"])
+	parts := ParseLocator(locator)
+	return NewLocator(
+		LocatorPart{Key: LocatorNamespaceKey, Value: parts.Namespace()},
+		LocatorPart{Key: LocatorPodKey, Value: parts.Get(LocatorPodKey)},
+	).OldLocator()
 }
 
 func PodFrom(locator string) PodReference {
-	parts := LocatorParts(locator)
-	namespace := NamespaceFrom(parts)
-	name := parts["pod"]
-	uid := parts["uid"]
+	return PodFromLocator(ParseLocator(locator))
+}
+
+func PodFromLocator(locator Locator) PodReference {
+	namespace := locator.Namespace()
+	name := locator.Get(LocatorPodKey)
+	uid := locator.Get(LocatorUIDKey)
 	if len(namespace) == 0 || len(name) == 0 || len(uid) == 0 {
 		return PodReference{}
 	}
@@ -44,9 +63,12 @@ func PodFrom(locator string) PodReference {
 }
 
 func ContainerFrom(locator string) ContainerReference {
-	pod := PodFrom(locator)
-	parts := LocatorParts(locator)
-	name := parts["container"]
+	return ContainerFromLocator(ParseLocator(locator))
+}
+
+func ContainerFromLocator(locator Locator) ContainerReference {
+	pod := PodFromLocator(locator)
+	name := locator.Get(LocatorContainerKey)
 	if len(name) == 0 || len(pod.UID) == 0 {
 		return ContainerReference{}
 	}
@@ -61,7 +83,15 @@ type PodReference struct {
 }
 
 func (r PodReference) ToLocator() string {
-	return fmt.Sprintf("ns/%s pod/%s uid/%s", r.Namespace, r.Name, r.UID)
+	return r.ToStructuredLocator().OldLocator()
+}
+
+func (r PodReference) ToStructuredLocator() Locator {
+	return NewLocator(
+		LocatorPart{Key: LocatorNamespaceKey, Value: r.Namespace},
+		LocatorPart{Key: LocatorPodKey, Value: r.Name},
+		LocatorPart{Key: LocatorUIDKey, Value: r.UID},
+	)
 }
 
 type ContainerReference struct {
@@ -70,7 +100,12 @@ type ContainerReference struct {
 }
 
 func (r ContainerReference) ToLocator() string {
-	return fmt.Sprintf("ns/%s pod/%s uid/%s container/%s", r.Pod.Namespace, r.Pod.Name, r.Pod.UID, r.ContainerName)
+	return r.ToStructuredLocator().OldLocator()
+}
+
+func (r ContainerReference) ToStructuredLocator() Locator {
+	podLocator := r.Pod.ToStructuredLocator()
+	return NewLocator(append(podLocator.Parts, LocatorPart{Key: LocatorContainerKey, Value: r.ContainerName})...)
 }
 
 func AnnotationsFromMessage(message string) map[string]string {
@@ -167,12 +202,13 @@ var (
 type ByTimeWithNamespacedPods []EventInterval
 
 func (intervals ByTimeWithNamespacedPods) Less(i, j int) bool {
-	lhsIsPodConstructed := strings.Contains(intervals[i].Message, "constructed") && strings.Contains(intervals[i].Locator, "pod/")
-	rhsIsPodConstructed := strings.Contains(intervals[j].Message, "constructed") && strings.Contains(intervals[j].Locator, "pod/")
+	lhsLocator, rhsLocator := LocatorFrom(intervals[i]), LocatorFrom(intervals[j])
+	lhsIsPodConstructed := IsConstructed(intervals[i]) && lhsLocator.Has(LocatorPodKey)
+	rhsIsPodConstructed := IsConstructed(intervals[j]) && rhsLocator.Has(LocatorPodKey)
 	switch {
 	case lhsIsPodConstructed && rhsIsPodConstructed:
-		lhsNamespace := NamespaceFromLocator(intervals[i].Locator)
-		rhsNamespace := NamespaceFromLocator(intervals[j].Locator)
+		lhsNamespace := lhsLocator.Namespace()
+		rhsNamespace := rhsLocator.Namespace()
 		if lhsNamespace < rhsNamespace {
 			return true
 		} else if lhsNamespace > rhsNamespace {
@@ -0,0 +1,41 @@
+package monitorapi
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	ResourceReasonCreated = "Created"
+	ResourceReasonUpdated = "Updated"
+	ResourceReasonDeleted = "Deleted"
+)
+
+// LocateResource locates any object by its lowercased kind, for instance
+// "ns/openshift-machine-api deployment/machine-api-operator uid/..." or "machineconfigpool/worker uid/...".
+func LocateResource(obj runtime.Object) string {
+	return NewResourceLocator(obj).OldLocator()
+}
+
+func NewResourceLocator(obj runtime.Object) Locator {
+	kind := strings.ToLower(obj.GetObjectKind().GroupVersionKind().Kind)
+	if len(kind) == 0 {
+		kind = "object"
+	}
+	metadata, err := meta.Accessor(obj)
+	if err != nil {
+		return NewLocator(LocatorPart{Key: LocatorKey(kind)})
+	}
+
+	parts := []LocatorPart{}
+	if len(metadata.GetNamespace()) > 0 {
+		parts = append(parts, LocatorPart{Key: LocatorNamespaceKey, Value: metadata.GetNamespace()})
+	}
+	parts = append(parts,
+		LocatorPart{Key: LocatorKey(kind), Value: metadata.GetName()},
+		LocatorPart{Key: LocatorUIDKey, Value: string(metadata.GetUID())},
+	)
+	return NewLocator(parts...)
+}
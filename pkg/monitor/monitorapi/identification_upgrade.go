@@ -0,0 +1,75 @@
+package monitorapi
+
+import (
+	"time"
+)
+
+const (
+	// UpgradePhaseStartedEventReason and UpgradePhaseEndedEventReason are the reasons of the events the upgrade test
+	// records against clusterversion/cluster at the boundaries of a phase.  The phase is in the phase annotation.
+	UpgradePhaseStartedEventReason = "UpgradePhaseStarted"
+	UpgradePhaseEndedEventReason   = "UpgradePhaseEnded"
+
+	// UpgradePhaseAcknowledge is the time the cluster-version operator takes to accept the desired update.
+	UpgradePhaseAcknowledge = "acknowledge"
+	// UpgradePhaseControlPlane lasts until the cluster-version operator reports the cluster at the new version, which
+	// happens once the control plane operators have finished updating.
+	UpgradePhaseControlPlane = "control-plane"
+)
+
+// UpgradePoolPhase is the phase during which a MachineConfigPool rolls its nodes.
+func UpgradePoolPhase(pool string) string {
+	return "pool/" + pool
+}
+
+// UpgradePhaseLocator locates the interval of an upgrade phase, for instance upgrade/control-plane or
+// upgrade/pool/worker.
+func UpgradePhaseLocator(phase string) string {
+	return NewLocator(LocatorPart{Key: LocatorUpgradePhaseKey, Value: phase}).OldLocator()
+}
+
+func UpgradePhaseFromLocator(locator string) (string, bool) {
+	return leadingValue(ParseLocator(locator), LocatorUpgradePhaseKey)
+}
+
+// IsUpgradePhase matches the intervals of upgrade phases.  Renderers draw them behind the other intervals.
+func IsUpgradePhase(eventInterval EventInterval) bool {
+	_, ok := UpgradePhaseFromLocator(eventInterval.Locator)
+	return ok
+}
+
+// InUpgradePhase matches the intervals that overlap phase, as found in intervals.  It lets synthetic tests scope a
+// check to a part of the upgrade, for instance
+//
+//	events.Filter(monitorapi.InUpgradePhase(events, monitorapi.UpgradePoolPhase("worker")))
+//
+// Nothing matches if the phase never happened.
+func InUpgradePhase(intervals Intervals, phase string) EventIntervalMatchesFunc {
+	phases := intervals.Filter(func(eventInterval EventInterval) bool {
+		curr, ok := UpgradePhaseFromLocator(eventInterval.Locator)
+		return ok && curr == phase
+	})
+	return func(eventInterval EventInterval) bool {
+		if IsUpgradePhase(eventInterval) {
+			return false
+		}
+		for _, phaseInterval := range phases {
+			if overlapsInclusive(eventInterval, phaseInterval.From, phaseInterval.To) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// overlapsInclusive returns true if eventInterval overlaps or touches [from, to], so instants at a boundary count.
+// Open intervals never end.
+func overlapsInclusive(eventInterval EventInterval, from, to time.Time) bool {
+	if !to.IsZero() && eventInterval.From.After(to) {
+		return false
+	}
+	if !eventInterval.To.IsZero() && eventInterval.To.Before(from) {
+		return false
+	}
+	return true
+}
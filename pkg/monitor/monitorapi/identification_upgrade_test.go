@@ -0,0 +1,63 @@
+package monitorapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInUpgradePhase(t *testing.T) {
+	start := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+	intervals := Intervals{
+		{Condition: Condition{Locator: UpgradePhaseLocator(UpgradePhaseControlPlane)}, From: at(0), To: at(30)},
+		{Condition: Condition{Locator: UpgradePhaseLocator(UpgradePoolPhase("worker"))}, From: at(30), To: at(50)},
+		{Condition: Condition{Locator: "node/worker-0", Message: "before"}, From: at(-10), To: at(-5)},
+		{Condition: Condition{Locator: "node/worker-0", Message: "control-plane"}, From: at(10), To: at(15)},
+		{Condition: Condition{Locator: "node/worker-0", Message: "boundary"}, From: at(30), To: at(30)},
+		{Condition: Condition{Locator: "node/worker-0", Message: "worker"}, From: at(40), To: at(45)},
+		{Condition: Condition{Locator: "node/worker-0", Message: "open"}, From: at(-20)},
+	}
+
+	tests := []struct {
+		phase    string
+		expected []string
+	}{
+		{phase: UpgradePhaseControlPlane, expected: []string{"control-plane", "boundary", "open"}},
+		{phase: UpgradePoolPhase("worker"), expected: []string{"boundary", "worker", "open"}},
+		{phase: UpgradePoolPhase("infra"), expected: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phase, func(t *testing.T) {
+			actual := []string{}
+			for _, interval := range intervals.Filter(InUpgradePhase(intervals, tt.phase)) {
+				actual = append(actual, interval.Message)
+			}
+			if len(actual) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, actual)
+			}
+			for i := range actual {
+				if actual[i] != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestUpgradePhaseLocator(t *testing.T) {
+	locator := UpgradePhaseLocator(UpgradePoolPhase("worker"))
+	if locator != "upgrade/pool/worker" {
+		t.Fatalf("unexpected locator %q", locator)
+	}
+	if phase, ok := UpgradePhaseFromLocator(locator); !ok || phase != "pool/worker" {
+		t.Errorf("expected phase pool/worker, got %q", phase)
+	}
+	if actual := ParseLocator(locator).Type; actual != LocatorTypeUpgradePhase {
+		t.Errorf("expected type %q, got %q", LocatorTypeUpgradePhase, actual)
+	}
+	if IsUpgradePhase(EventInterval{Condition: Condition{Locator: "ns/openshift-cluster-version clusterversion/cluster"}}) {
+		t.Errorf("clusterversion/cluster is not an upgrade phase")
+	}
+}
@@ -0,0 +1,103 @@
+package monitorapi
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Invocation is what one openshift-tests invocation of a job recorded.  Upgrade jobs invoke openshift-tests several
+// times, and each invocation runs its own monitors.
+type Invocation struct {
+	// Name identifies the invocation in the invocations annotation of merged intervals.
+	Name      string
+	Intervals Intervals
+}
+
+// MergeInvocations combines the intervals of several invocations of one job into a single timeline.  Intervals with
+// the same locator and message from different invocations that overlap or touch were seen by more than one monitor,
+// so they are deduplicated into one interval that spans them all and keeps the highest level.  Intervals from the
+// same invocation are never merged with each other.  The invocations annotation of every interval lists the
+// invocations that observed it, in the order they are passed, and is kept from intervals that were already merged.
+func MergeInvocations(invocations ...Invocation) Intervals {
+	type observation struct {
+		interval    EventInterval
+		invocations []string
+	}
+	observations := []*observation{}
+	for _, invocation := range invocations {
+		for _, interval := range invocation.Intervals {
+			names := []string{invocation.Name}
+			if previous := AnnotationFrom(interval, AnnotationInvocations); len(previous) > 0 {
+				names = strings.Split(previous, ",")
+			}
+			observations = append(observations, &observation{interval: interval, invocations: names})
+		}
+	}
+	sort.SliceStable(observations, func(i, j int) bool {
+		return observations[i].interval.From.Before(observations[j].interval.From)
+	})
+
+	invocationOrder := map[string]int{}
+	for i, invocation := range invocations {
+		invocationOrder[invocation.Name] = i
+	}
+	seenBy := func(merged *observation, names []string) bool {
+		for _, name := range names {
+			for _, curr := range merged.invocations {
+				if curr == name {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	ret := []*observation{}
+	// active holds, for every locator and message, the merged intervals that can still overlap a later interval
+	active := map[string][]*observation{}
+	for _, curr := range observations {
+		key := curr.interval.Locator + "\n" + curr.interval.Message
+		stillActive := active[key][:0]
+		var into *observation
+		for _, merged := range active[key] {
+			if !merged.interval.To.IsZero() && merged.interval.To.Before(curr.interval.From) {
+				continue
+			}
+			stillActive = append(stillActive, merged)
+			if into == nil && !seenBy(merged, curr.invocations) {
+				into = merged
+			}
+		}
+		active[key] = stillActive
+
+		if into == nil {
+			ret = append(ret, curr)
+			active[key] = append(active[key], curr)
+			continue
+		}
+		switch {
+		case into.interval.To.IsZero() || curr.interval.To.IsZero():
+			into.interval.To = time.Time{}
+		case curr.interval.To.After(into.interval.To):
+			into.interval.To = curr.interval.To
+		}
+		if curr.interval.Level > into.interval.Level {
+			into.interval.Level = curr.interval.Level
+		}
+		into.invocations = append(into.invocations, curr.invocations...)
+	}
+
+	merged := make(Intervals, 0, len(ret))
+	for _, curr := range ret {
+		sort.SliceStable(curr.invocations, func(i, j int) bool {
+			return invocationOrder[curr.invocations[i]] < invocationOrder[curr.invocations[j]]
+		})
+		condition := EnsureStructured(curr.interval.Condition)
+		condition.StructuredMessage = condition.StructuredMessage.WithAnnotation(AnnotationInvocations, strings.Join(curr.invocations, ","))
+		curr.interval.Condition = condition
+		merged = append(merged, curr.interval)
+	}
+	sort.Sort(merged)
+	return merged
+}
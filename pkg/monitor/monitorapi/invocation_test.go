@@ -0,0 +1,72 @@
+package monitorapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeInvocations(t *testing.T) {
+	leveled := func(interval EventInterval, level EventLevel) EventInterval {
+		interval.Level = level
+		return interval
+	}
+	open := span("disruption/kube-api", 20, 0)
+	open.To = time.Time{}
+	alreadyMerged := span("node/c", 0, 1)
+	alreadyMerged.StructuredMessage = NewMessage("node/c").WithAnnotation(AnnotationInvocations, "install,upgrade")
+
+	merged := MergeInvocations(
+		Invocation{Name: "upgrade", Intervals: Intervals{
+			span("disruption/kube-api", 0, 10),
+			// repeated within one invocation, so not a duplicate
+			span("disruption/kube-api", 5, 12),
+			span("node/a", 0, 5),
+			span("node/b", 30, 30),
+			open,
+			alreadyMerged,
+		}},
+		Invocation{Name: "conformance", Intervals: Intervals{
+			leveled(span("disruption/kube-api", 8, 15), Error),
+			// touching counts as seen by both
+			span("node/a", 5, 6),
+			span("node/b", 40, 40),
+			span("disruption/kube-api", 25, 26),
+		}},
+	)
+
+	type expectedInterval struct {
+		locator     string
+		from, to    int
+		open        bool
+		level       EventLevel
+		invocations string
+	}
+	expected := []expectedInterval{
+		{locator: "node/c", from: 0, to: 1, invocations: "install,upgrade"},
+		{locator: "node/a", from: 0, to: 6, invocations: "upgrade,conformance"},
+		{locator: "disruption/kube-api", from: 0, to: 15, level: Error, invocations: "upgrade,conformance"},
+		{locator: "disruption/kube-api", from: 5, to: 12, invocations: "upgrade"},
+		{locator: "disruption/kube-api", from: 20, open: true, invocations: "upgrade,conformance"},
+		{locator: "node/b", from: 30, to: 30, invocations: "upgrade"},
+		{locator: "node/b", from: 40, to: 40, invocations: "conformance"},
+	}
+	if len(merged) != len(expected) {
+		t.Fatalf("expected %d intervals, got\n%v", len(expected), merged.Strings())
+	}
+	for i, want := range expected {
+		got := merged[i]
+		wantTo := at(want.to)
+		if want.open {
+			wantTo = time.Time{}
+		}
+		if got.Locator != want.locator || !got.From.Equal(at(want.from)) || !got.To.Equal(wantTo) || got.Level != want.level {
+			t.Errorf("%d: expected %#v, got %v", i, want, got)
+		}
+		if actual := AnnotationFrom(got, AnnotationInvocations); actual != want.invocations {
+			t.Errorf("%d: expected invocations %q, got %q", i, want.invocations, actual)
+		}
+		if got.Message != want.locator {
+			t.Errorf("%d: the message changed to %q", i, got.Message)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package monitorapi
+
+import (
+	"strconv"
+	"strings"
+)
+
+// LocatorType describes the kind of thing a Locator points at.
+type LocatorType string
+
+const (
+	LocatorTypePod             LocatorType = "Pod"
+	LocatorTypeContainer       LocatorType = "Container"
+	LocatorTypeNode            LocatorType = "Node"
+	LocatorTypeNamespace       LocatorType = "Namespace"
+	LocatorTypeClusterOperator LocatorType = "ClusterOperator"
+	LocatorTypeClusterVersion  LocatorType = "ClusterVersion"
+	LocatorTypeAlert           LocatorType = "Alert"
+	LocatorTypeDisruption      LocatorType = "Disruption"
+	LocatorTypeE2ETest         LocatorType = "E2ETest"
+	LocatorTypeMonitor         LocatorType = "Monitor"
+	LocatorTypeUpgradePhase    LocatorType = "UpgradePhase"
+	LocatorTypeOther           LocatorType = "Other"
+)
+
+// LocatorKey is the key half of a single key/value stanza in a locator, "ns" in "ns/openshift-etcd".
+type LocatorKey string
+
+const (
+	LocatorNamespaceKey       LocatorKey = "ns"
+	LocatorNamespaceLongKey   LocatorKey = "namespace"
+	LocatorPodKey             LocatorKey = "pod"
+	LocatorNodeKey            LocatorKey = "node"
+	LocatorUIDKey             LocatorKey = "uid"
+	LocatorContainerKey       LocatorKey = "container"
+	LocatorClusterOperatorKey LocatorKey = "clusteroperator"
+	LocatorClusterVersionKey  LocatorKey = "clusterversion"
+	LocatorAlertKey           LocatorKey = "alert"
+	LocatorDisruptionKey      LocatorKey = "disruption"
+	LocatorConnectionKey      LocatorKey = "connection"
+	LocatorRouteKey           LocatorKey = "route"
+	LocatorE2ETestKey         LocatorKey = "e2e-test"
+	LocatorMonitorKey         LocatorKey = "monitor"
+	LocatorUpgradePhaseKey    LocatorKey = "upgrade"
+)
+
+// LocatorPart is a single key/value stanza of a Locator.  Parts without a key came from legacy locators
+// that contained a bare token without a "/".
+type LocatorPart struct {
+	Key   LocatorKey `json:"key,omitempty"`
+	Value string     `json:"value"`
+}
+
+// Locator is the structured form of Condition.Locator.  Parts are kept in the order they were added so that
+// OldLocator can reproduce the legacy space delimited string exactly.
+type Locator struct {
+	Type  LocatorType   `json:"type"`
+	Parts []LocatorPart `json:"parts,omitempty"`
+}
+
+// NewLocator builds a locator from the provided parts.  The type is inferred from the keys present.
+func NewLocator(parts ...LocatorPart) Locator {
+	ret := Locator{Parts: parts}
+	ret.Type = locatorTypeFor(ret)
+	return ret
+}
+
+// IsZero returns true if the locator carries no information.
+func (l Locator) IsZero() bool {
+	return len(l.Type) == 0 && len(l.Parts) == 0
+}
+
+// Get returns the value of the first part with the given key, or "" if there is no such part.
+func (l Locator) Get(key LocatorKey) string {
+	value, _ := l.Lookup(key)
+	return value
+}
+
+// Lookup returns the value of the first part with the given key and whether it was present.
+func (l Locator) Lookup(key LocatorKey) (string, bool) {
+	for _, part := range l.Parts {
+		if part.Key == key {
+			return part.Value, true
+		}
+	}
+	return "", false
+}
+
+// Has returns true if the locator contains the key.
+func (l Locator) Has(key LocatorKey) bool {
+	_, ok := l.Lookup(key)
+	return ok
+}
+
+// Namespace returns the value of "ns", falling back to "namespace".
+func (l Locator) Namespace() string {
+	if ns, ok := l.Lookup(LocatorNamespaceKey); ok {
+		return ns
+	}
+	return l.Get(LocatorNamespaceLongKey)
+}
+
+// Map returns the locator in the map form historically produced by LocatorParts.  When a key is repeated, the
+// last value wins.
+func (l Locator) Map() map[string]string {
+	ret := map[string]string{}
+	for _, part := range l.Parts {
+		if len(part.Key) == 0 {
+			ret[part.Value] = ""
+			continue
+		}
+		ret[string(part.Key)] = part.Value
+	}
+	return ret
+}
+
+// OldLocator renders the locator in the legacy space delimited form stored in Condition.Locator.
+func (l Locator) OldLocator() string {
+	tokens := make([]string, 0, len(l.Parts))
+	for _, part := range l.Parts {
+		if len(part.Key) == 0 {
+			tokens = append(tokens, part.Value)
+			continue
+		}
+		value := part.Value
+		if needsQuoting(part.Key, value) {
+			value = strconv.Quote(value)
+		}
+		tokens = append(tokens, string(part.Key)+"/"+value)
+	}
+	return strings.Join(tokens, " ")
+}
+
+func (l Locator) String() string {
+	return l.OldLocator()
+}
+
+// needsQuoting decides if a value has to be quoted to survive a round trip through the legacy form.
+// e2e-test locators have always been quoted.
+func needsQuoting(key LocatorKey, value string) bool {
+	if key == LocatorE2ETestKey {
+		return true
+	}
+	return strings.ContainsAny(value, ` "`)
+}
+
+// ParseLocator converts a legacy locator string into a Locator.  Values may be Go quoted strings, which allows
+// them to contain spaces and slashes.  ParseLocator(s).OldLocator() == s for every locator this repo produces.
+func ParseLocator(locator string) Locator {
+	if len(locator) == 0 {
+		return Locator{}
+	}
+
+	ret := Locator{}
+	remaining := locator
+	for {
+		separator := strings.IndexAny(remaining, "/ ")
+		if separator < 0 || remaining[separator] == ' ' {
+			// a bare token without a key
+			if separator < 0 {
+				ret.Parts = append(ret.Parts, LocatorPart{Value: remaining})
+				break
+			}
+			ret.Parts = append(ret.Parts, LocatorPart{Value: remaining[:separator]})
+			remaining = remaining[separator+1:]
+			continue
+		}
+
+		key := LocatorKey(remaining[:separator])
+		value, rest := readLocatorValue(remaining[separator+1:])
+		ret.Parts = append(ret.Parts, LocatorPart{Key: key, Value: value})
+		if len(rest) == 0 {
+			break
+		}
+		remaining = rest[1:]
+	}
+	ret.Type = locatorTypeFor(ret)
+	return ret
+}
+
+// readLocatorValue reads a single value from the start of s and returns it along with the unread remainder, which
+// is either empty or starts with the separating space.
+func readLocatorValue(s string) (string, string) {
+	if strings.HasPrefix(s, `"`) {
+		if quoted, err := strconv.QuotedPrefix(s); err == nil {
+			rest := s[len(quoted):]
+			if unquoted, err := strconv.Unquote(quoted); err == nil && (len(rest) == 0 || rest[0] == ' ') {
+				return unquoted, rest
+			}
+		}
+	}
+	if end := strings.Index(s, " "); end >= 0 {
+		return s[:end], s[end:]
+	}
+	return s, ""
+}
+
+// locatorTypeFor infers the most specific type for the keys present in the locator.
+func locatorTypeFor(l Locator) LocatorType {
+	switch {
+	case l.Has(LocatorE2ETestKey):
+		return LocatorTypeE2ETest
+	case l.Has(LocatorAlertKey):
+		return LocatorTypeAlert
+	case l.Has(LocatorDisruptionKey):
+		return LocatorTypeDisruption
+	case l.Has(LocatorMonitorKey):
+		return LocatorTypeMonitor
+	case l.Has(LocatorUpgradePhaseKey):
+		return LocatorTypeUpgradePhase
+	case l.Has(LocatorContainerKey) && l.Has(LocatorPodKey):
+		return LocatorTypeContainer
+	case l.Has(LocatorPodKey):
+		return LocatorTypePod
+	case l.Has(LocatorClusterOperatorKey):
+		return LocatorTypeClusterOperator
+	case l.Has(LocatorClusterVersionKey):
+		return LocatorTypeClusterVersion
+	case l.Has(LocatorNodeKey) && len(l.Parts) == 1:
+		return LocatorTypeNode
+	case (l.Has(LocatorNamespaceKey) || l.Has(LocatorNamespaceLongKey)) && len(l.Parts) == 1:
+		return LocatorTypeNamespace
+	case len(l.Parts) == 0:
+		return ""
+	default:
+		return LocatorTypeOther
+	}
+}
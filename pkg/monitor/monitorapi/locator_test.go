@@ -0,0 +1,119 @@
+package monitorapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLocator(t *testing.T) {
+	tests := []struct {
+		name     string
+		locator  string
+		wantType LocatorType
+		want     map[LocatorKey]string
+	}{
+		{
+			name:     "pod",
+			locator:  "ns/openshift-etcd pod/etcd-0 node/master-0 uid/a1947638",
+			wantType: LocatorTypePod,
+			want: map[LocatorKey]string{
+				LocatorNamespaceKey: "openshift-etcd",
+				LocatorPodKey:       "etcd-0",
+				LocatorNodeKey:      "master-0",
+				LocatorUIDKey:       "a1947638",
+			},
+		},
+		{
+			name:     "unscheduled-pod-container",
+			locator:  "ns/openshift-monitoring pod/prometheus-k8s-0 node/ uid/a1947638 container/",
+			wantType: LocatorTypeContainer,
+			want: map[LocatorKey]string{
+				LocatorNamespaceKey: "openshift-monitoring",
+				LocatorPodKey:       "prometheus-k8s-0",
+				LocatorNodeKey:      "",
+				LocatorUIDKey:       "a1947638",
+				LocatorContainerKey: "",
+			},
+		},
+		{
+			name:     "e2e-test-with-spaces-and-slashes",
+			locator:  `e2e-test/"[sig-network] a/b should work [Suite:openshift/conformance/parallel]"`,
+			wantType: LocatorTypeE2ETest,
+			want: map[LocatorKey]string{
+				LocatorE2ETestKey: "[sig-network] a/b should work [Suite:openshift/conformance/parallel]",
+			},
+		},
+		{
+			name:     "repeated-keys",
+			locator:  "disruption/oauth-api connection/new disruption/oauth-api connection/new",
+			wantType: LocatorTypeDisruption,
+			want: map[LocatorKey]string{
+				LocatorDisruptionKey: "oauth-api",
+				LocatorConnectionKey: "new",
+			},
+		},
+		{
+			name:     "bare-token",
+			locator:  "tester",
+			wantType: LocatorTypeOther,
+			want:     map[LocatorKey]string{},
+		},
+		{
+			name:     "node",
+			locator:  "node/ci-op-worker-b",
+			wantType: LocatorTypeNode,
+			want: map[LocatorKey]string{
+				LocatorNodeKey: "ci-op-worker-b",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseLocator(tt.locator)
+			if got.Type != tt.wantType {
+				t.Errorf("type: expected %q, got %q", tt.wantType, got.Type)
+			}
+			for key, value := range tt.want {
+				if actual, ok := got.Lookup(key); !ok || actual != value {
+					t.Errorf("%s: expected %q, got %q (present=%v)", key, value, actual, ok)
+				}
+			}
+			if roundTrip := got.OldLocator(); roundTrip != tt.locator {
+				t.Errorf("round trip: expected %q, got %q", tt.locator, roundTrip)
+			}
+		})
+	}
+}
+
+func TestLocatorRoundTripWithSpaces(t *testing.T) {
+	locator := NewLocator(
+		LocatorPart{Key: LocatorNamespaceKey, Value: "odd namespace"},
+		LocatorPart{Key: LocatorPodKey, Value: `quote"d/pod`},
+	)
+	parsed := ParseLocator(locator.OldLocator())
+	if !reflect.DeepEqual(parsed, locator) {
+		t.Fatalf("expected %#v, got %#v", locator, parsed)
+	}
+	if ns := NamespaceFromLocator(locator.OldLocator()); ns != "odd namespace" {
+		t.Fatalf("expected namespace with a space, got %q", ns)
+	}
+}
+
+func TestLocatorHelpers(t *testing.T) {
+	if name, ok := E2ETestFromLocator(E2ETestLocator("a test/with slashes")); !ok || name != "a test/with slashes" {
+		t.Errorf("unexpected e2e test %q %v", name, ok)
+	}
+	if name, ok := NodeFromLocator("node/master-1 roles/master"); !ok || name != "master-1" {
+		t.Errorf("unexpected node %q %v", name, ok)
+	}
+	if _, ok := NodeFromLocator("ns/foo node/master-1"); ok {
+		t.Errorf("node must lead the locator")
+	}
+	pod := PodFrom("ns/openshift-etcd pod/etcd-0 node/master-0 uid/a1947638")
+	if pod.Namespace != "openshift-etcd" || pod.Name != "etcd-0" || pod.UID != "a1947638" {
+		t.Errorf("unexpected pod %#v", pod)
+	}
+	if alert := AlertFromLocator("alert/Watchdog ns/openshift-monitoring"); alert != "Watchdog" {
+		t.Errorf("unexpected alert %q", alert)
+	}
+}
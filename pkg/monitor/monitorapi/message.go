@@ -0,0 +1,193 @@
+package monitorapi
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// AnnotationKey is the key of a machine readable annotation on a Message.  Historically these were written inline
+// at the start of the message, "reason/Unhealthy" or "constructed/true".
+type AnnotationKey string
+
+const (
+	AnnotationReason      AnnotationKey = "reason"
+	AnnotationCause       AnnotationKey = "cause"
+	AnnotationConstructed AnnotationKey = "constructed"
+	AnnotationPhase       AnnotationKey = "phase"
+	AnnotationRoles       AnnotationKey = "roles"
+	AnnotationCondition   AnnotationKey = "condition"
+	AnnotationStatus      AnnotationKey = "status"
+	AnnotationContainer   AnnotationKey = "container"
+	AnnotationDuration    AnnotationKey = "duration"
+	AnnotationExitCode    AnnotationKey = "code"
+	AnnotationImage       AnnotationKey = "image"
+	AnnotationMirrored    AnnotationKey = "mirrored"
+	AnnotationConfig      AnnotationKey = "config"
+	AnnotationAlertState  AnnotationKey = "alertstate"
+	AnnotationSeverity    AnnotationKey = "severity"
+	// AnnotationInvocations lists the openshift-tests invocations that observed an interval, see MergeInvocations.
+	// It is never inlined in the message.
+	AnnotationInvocations AnnotationKey = "invocations"
+)
+
+// annotationOrder is the order annotations are rendered in by OldMessage.  It matches the order producers
+// historically wrote them in.  Keys not listed here are rendered afterwards, sorted.
+var annotationOrder = []AnnotationKey{
+	AnnotationConstructed,
+	AnnotationContainer,
+	AnnotationCondition,
+	AnnotationStatus,
+	AnnotationReason,
+	AnnotationPhase,
+	AnnotationExitCode,
+	AnnotationCause,
+	AnnotationDuration,
+	AnnotationConfig,
+	AnnotationImage,
+	AnnotationMirrored,
+	AnnotationRoles,
+}
+
+// inlineAnnotationKeys are the keys ParseMessage recognizes at the start of a legacy message.
+var inlineAnnotationKeys = map[AnnotationKey]bool{}
+
+func init() {
+	for _, key := range annotationOrder {
+		inlineAnnotationKeys[key] = true
+	}
+}
+
+// alertStateRegex finds the alert state in the prometheus label set used as the message of alert intervals.
+var alertStateRegex = regexp.MustCompile(`alertstate="([^"]*)"`)
+
+// Message is the structured form of Condition.Message.  Annotations carry the machine readable parts of the
+// message, HumanMessage carries the rest.
+type Message struct {
+	Annotations  map[AnnotationKey]string `json:"annotations,omitempty"`
+	HumanMessage string                   `json:"humanMessage,omitempty"`
+}
+
+// NewMessage returns a message with the provided human readable text and no annotations.  Use WithAnnotation to
+// add them.
+func NewMessage(humanMessage string) Message {
+	return Message{HumanMessage: humanMessage}
+}
+
+// WithAnnotation returns a copy of the message with the annotation set.
+func (m Message) WithAnnotation(key AnnotationKey, value string) Message {
+	annotations := make(map[AnnotationKey]string, len(m.Annotations)+1)
+	for k, v := range m.Annotations {
+		annotations[k] = v
+	}
+	annotations[key] = value
+	m.Annotations = annotations
+	return m
+}
+
+// IsZero returns true if the message carries no information.
+func (m Message) IsZero() bool {
+	return len(m.Annotations) == 0 && len(m.HumanMessage) == 0
+}
+
+// Annotation returns the value of the annotation, or "" if it is not set.
+func (m Message) Annotation(key AnnotationKey) string {
+	return m.Annotations[key]
+}
+
+// OldMessage renders the message in the legacy form with the annotations inlined at the start.
+func (m Message) OldMessage() string {
+	tokens := []string{}
+	seen := map[AnnotationKey]bool{}
+	for _, key := range annotationOrder {
+		if value, ok := m.Annotations[key]; ok {
+			tokens = append(tokens, string(key)+"/"+value)
+			seen[key] = true
+		}
+	}
+	remainingKeys := []string{}
+	for key := range m.Annotations {
+		if !seen[key] {
+			remainingKeys = append(remainingKeys, string(key))
+		}
+	}
+	sort.Strings(remainingKeys)
+	for _, key := range remainingKeys {
+		tokens = append(tokens, key+"/"+m.Annotations[AnnotationKey(key)])
+	}
+	if len(m.HumanMessage) > 0 {
+		tokens = append(tokens, m.HumanMessage)
+	}
+	return strings.Join(tokens, " ")
+}
+
+// ParseMessage extracts the annotations inlined at the start of a legacy message.  Only recognized annotation keys
+// are extracted, so messages that start with a locator or a path are left alone.
+func ParseMessage(message string) Message {
+	if len(message) == 0 {
+		return Message{}
+	}
+
+	ret := Message{}
+	remaining := message
+	for len(remaining) > 0 {
+		token := remaining
+		rest := ""
+		if i := strings.Index(remaining, " "); i >= 0 {
+			token, rest = remaining[:i], remaining[i+1:]
+		}
+		keyValue := strings.SplitN(token, "/", 2)
+		if len(keyValue) != 2 || !inlineAnnotationKeys[AnnotationKey(keyValue[0])] {
+			break
+		}
+		if ret.Annotations == nil {
+			ret.Annotations = map[AnnotationKey]string{}
+		}
+		key, value := AnnotationKey(keyValue[0]), keyValue[1]
+		// "cause/Error: message" ends the annotations with a colon.
+		if strings.HasSuffix(value, ":") {
+			ret.Annotations[key] = strings.TrimSuffix(value, ":")
+			remaining = rest
+			break
+		}
+		if _, ok := ret.Annotations[key]; !ok {
+			ret.Annotations[key] = value
+		}
+		remaining = rest
+	}
+	ret.HumanMessage = remaining
+
+	// alert intervals use the prometheus label set as the message
+	if strings.HasPrefix(message, "{") {
+		if matches := alertStateRegex.FindStringSubmatch(message); len(matches) == 2 {
+			ret = ret.WithAnnotation(AnnotationAlertState, matches[1])
+		}
+	}
+	return ret
+}
+
+// MessageFrom returns the structured message of the eventInterval, parsing the legacy message if the structured
+// form was never filled in.
+func MessageFrom(eventInterval EventInterval) Message {
+	if !eventInterval.StructuredMessage.IsZero() {
+		return eventInterval.StructuredMessage
+	}
+	return ParseMessage(eventInterval.Message)
+}
+
+// AnnotationFrom returns the value of the annotation on the eventInterval, or "" if it is not set.
+func AnnotationFrom(eventInterval EventInterval, key AnnotationKey) string {
+	return MessageFrom(eventInterval).Annotation(key)
+}
+
+// HasAnnotation matches intervals where the annotation is set to value.
+func HasAnnotation(key AnnotationKey, value string) EventIntervalMatchesFunc {
+	return func(eventInterval EventInterval) bool {
+		return AnnotationFrom(eventInterval, key) == value
+	}
+}
+
+// IsConstructed returns true for intervals that were calculated from other intervals instead of observed.
+func IsConstructed(eventInterval EventInterval) bool {
+	return AnnotationFrom(eventInterval, AnnotationConstructed) == "true"
+}
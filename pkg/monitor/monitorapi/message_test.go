@@ -0,0 +1,102 @@
+package monitorapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    Message
+	}{
+		{
+			name:    "constructed",
+			message: "constructed/true reason/NotReady missed real \"NotReady\"",
+			want: Message{
+				Annotations: map[AnnotationKey]string{
+					AnnotationConstructed: "true",
+					AnnotationReason:      "NotReady",
+				},
+				HumanMessage: `missed real "NotReady"`,
+			},
+		},
+		{
+			name:    "container-exit-with-cause",
+			message: "reason/ContainerExit code/137 cause/Error: something went wrong",
+			want: Message{
+				Annotations: map[AnnotationKey]string{
+					AnnotationReason:   "ContainerExit",
+					AnnotationExitCode: "137",
+					AnnotationCause:    "Error",
+				},
+				HumanMessage: "something went wrong",
+			},
+		},
+		{
+			name:    "operator-condition",
+			message: "condition/Degraded status/True reason/DNSDegraded changed: DNS default is degraded",
+			want: Message{
+				Annotations: map[AnnotationKey]string{
+					AnnotationCondition: "Degraded",
+					AnnotationStatus:    "True",
+					AnnotationReason:    "DNSDegraded",
+				},
+				HumanMessage: "changed: DNS default is degraded",
+			},
+		},
+		{
+			name:    "leading-locator-is-not-an-annotation",
+			message: "disruption/kube-api connection/new started responding to GET requests over new connections",
+			want: Message{
+				HumanMessage: "disruption/kube-api connection/new started responding to GET requests over new connections",
+			},
+		},
+		{
+			name:    "alert",
+			message: `{alertname="KubePodNotReady", alertstate="pending", namespace="openshift-etcd", severity="warning"}`,
+			want: Message{
+				Annotations: map[AnnotationKey]string{
+					AnnotationAlertState: "pending",
+				},
+				HumanMessage: `{alertname="KubePodNotReady", alertstate="pending", namespace="openshift-etcd", severity="warning"}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseMessage(tt.message); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMessageOldMessage(t *testing.T) {
+	message := NewMessage("missed real \"Ready\"").
+		WithAnnotation(AnnotationReason, "Ready").
+		WithAnnotation(AnnotationConstructed, "true")
+	expected := `constructed/true reason/Ready missed real "Ready"`
+	if actual := message.OldMessage(); actual != expected {
+		t.Fatalf("expected %q, got %q", expected, actual)
+	}
+	if !reflect.DeepEqual(ParseMessage(expected), message) {
+		t.Fatalf("expected %#v to round trip, got %#v", message, ParseMessage(expected))
+	}
+}
+
+func TestAlertFiringInNamespace(t *testing.T) {
+	firing := EventInterval{
+		Condition: Condition{
+			Locator: "alert/KubePodNotReady ns/openshift-etcd",
+			Message: `{alertname="KubePodNotReady", alertstate="firing", namespace="openshift-etcd"}`,
+		},
+	}
+	if !AlertFiringInNamespace("KubePodNotReady", "openshift-etcd")(firing) {
+		t.Errorf("expected firing alert to match")
+	}
+	if AlertPendingInNamespace("KubePodNotReady", "openshift-etcd")(firing) {
+		t.Errorf("expected firing alert to not match pending")
+	}
+}
@@ -0,0 +1,376 @@
+package monitorapi
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseQuery compiles a filter expression into an EventIntervalMatchesFunc.  For example:
+//
+//	level>=Warning and ns=~"openshift-etcd.*" and message contains "probe" and duration>5s
+//
+// A comparison is a field, an operator, and a value.  Values are bare words or Go quoted strings.  The fields are
+//
+//	level                   Info, Warning or Error.  Supports every operator, ordered by severity.
+//	duration                To - From, as a Go duration.  Open intervals have a zero duration.
+//	locator, message        the legacy string forms of the condition.
+//	type                    the LocatorType of the locator, for instance Pod or Disruption.
+//	annotation.<key>        an annotation on the message, for instance annotation.reason.
+//	<key>                   any other name is a locator key, for instance ns, pod, node or alert.
+//
+// The operators are =, !=, =~ and !~ (regular expressions that must match the whole value), contains, and for
+// level and duration <, <=, > and >=.  Comparisons are combined with and, or, not and parentheses.  and binds
+// tighter than or.  Missing locator keys and annotations compare as "".
+func ParseQuery(query string) (EventIntervalMatchesFunc, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{query: query, tokens: tokens}
+	if p.atEnd() {
+		return nil, fmt.Errorf("query is empty")
+	}
+	matcher, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.atEnd() {
+		return nil, p.errorf("unexpected %q", p.peek().text)
+	}
+	return matcher, nil
+}
+
+// MustParseQuery is like ParseQuery but panics if the query is invalid.  It is intended for queries declared as
+// package level variables.
+func MustParseQuery(query string) EventIntervalMatchesFunc {
+	matcher, err := ParseQuery(query)
+	if err != nil {
+		panic(err)
+	}
+	return matcher
+}
+
+type queryTokenType int
+
+const (
+	queryTokenWord queryTokenType = iota
+	queryTokenString
+	queryTokenOperator
+	queryTokenOpenParen
+	queryTokenCloseParen
+)
+
+type queryToken struct {
+	tokenType queryTokenType
+	text      string
+	position  int
+}
+
+// queryOperators are listed longest first so that "<=" is not lexed as "<".
+var queryOperators = []string{"!=", "=~", "!~", "<=", ">=", "=", "<", ">"}
+
+func lexQuery(query string) ([]queryToken, error) {
+	tokens := []queryToken{}
+	for i := 0; i < len(query); {
+		switch c := query[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{tokenType: queryTokenOpenParen, text: "(", position: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{tokenType: queryTokenCloseParen, text: ")", position: i})
+			i++
+		case c == '"':
+			quoted, err := strconv.QuotedPrefix(query[i:])
+			if err != nil {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			value, err := strconv.Unquote(quoted)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %v", i, err)
+			}
+			tokens = append(tokens, queryToken{tokenType: queryTokenString, text: value, position: i})
+			i += len(quoted)
+		case strings.ContainsRune("=!<>", rune(c)):
+			found := false
+			for _, operator := range queryOperators {
+				if strings.HasPrefix(query[i:], operator) {
+					tokens = append(tokens, queryToken{tokenType: queryTokenOperator, text: operator, position: i})
+					i += len(operator)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("unexpected %q at position %d", c, i)
+			}
+		default:
+			end := i
+			for end < len(query) && !strings.ContainsRune(" \t\n()\"=!<>", rune(query[end])) {
+				end++
+			}
+			tokens = append(tokens, queryToken{tokenType: queryTokenWord, text: query[i:end], position: i})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type queryParser struct {
+	query  string
+	tokens []queryToken
+	next   int
+}
+
+func (p *queryParser) atEnd() bool {
+	return p.next >= len(p.tokens)
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.next]
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	position := len(p.query)
+	if !p.atEnd() {
+		position = p.peek().position
+	}
+	return fmt.Errorf("invalid query at position %d: %s", position, fmt.Sprintf(format, args...))
+}
+
+// acceptKeyword consumes the next token if it is the bare word keyword.
+func (p *queryParser) acceptKeyword(keyword string) bool {
+	if p.atEnd() {
+		return false
+	}
+	token := p.peek()
+	if token.tokenType != queryTokenWord || !strings.EqualFold(token.text, keyword) {
+		return false
+	}
+	p.next++
+	return true
+}
+
+func (p *queryParser) parseOr() (EventIntervalMatchesFunc, error) {
+	matchers := []EventIntervalMatchesFunc{}
+	for {
+		matcher, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+		if !p.acceptKeyword("or") {
+			break
+		}
+	}
+	if len(matchers) == 1 {
+		return matchers[0], nil
+	}
+	return Or(matchers...), nil
+}
+
+func (p *queryParser) parseAnd() (EventIntervalMatchesFunc, error) {
+	matchers := []EventIntervalMatchesFunc{}
+	for {
+		matcher, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+		if !p.acceptKeyword("and") {
+			break
+		}
+	}
+	if len(matchers) == 1 {
+		return matchers[0], nil
+	}
+	return And(matchers...), nil
+}
+
+func (p *queryParser) parseUnary() (EventIntervalMatchesFunc, error) {
+	if p.atEnd() {
+		return nil, p.errorf("expected a comparison")
+	}
+	if p.acceptKeyword("not") {
+		matcher, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not(matcher), nil
+	}
+	if p.peek().tokenType == queryTokenOpenParen {
+		p.next++
+		matcher, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.atEnd() || p.peek().tokenType != queryTokenCloseParen {
+			return nil, p.errorf("expected )")
+		}
+		p.next++
+		return matcher, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *queryParser) parseComparison() (EventIntervalMatchesFunc, error) {
+	field := p.peek()
+	if field.tokenType != queryTokenWord {
+		return nil, p.errorf("expected a field name, got %q", field.text)
+	}
+	p.next++
+
+	if p.atEnd() {
+		return nil, p.errorf("expected an operator after %q", field.text)
+	}
+	operator := p.peek()
+	switch {
+	case operator.tokenType == queryTokenOperator:
+	case operator.tokenType == queryTokenWord && strings.EqualFold(operator.text, "contains"):
+		operator.text = "contains"
+	default:
+		return nil, p.errorf("expected an operator after %q, got %q", field.text, operator.text)
+	}
+	p.next++
+
+	if p.atEnd() {
+		return nil, p.errorf("expected a value after %q", operator.text)
+	}
+	value := p.peek()
+	if value.tokenType != queryTokenWord && value.tokenType != queryTokenString {
+		return nil, p.errorf("expected a value after %q, got %q", operator.text, value.text)
+	}
+	p.next++
+
+	matcher, err := compileComparison(field.text, operator.text, value.text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query at position %d: %w", field.position, err)
+	}
+	return matcher, nil
+}
+
+func compileComparison(field, operator, value string) (EventIntervalMatchesFunc, error) {
+	switch field {
+	case "level":
+		return compileLevelComparison(operator, value)
+	case "duration":
+		return compileDurationComparison(operator, value)
+	case "locator":
+		return compileStringComparison(field, operator, value, func(eventInterval EventInterval) string {
+			return eventInterval.Locator
+		})
+	case "message":
+		return compileStringComparison(field, operator, value, func(eventInterval EventInterval) string {
+			return eventInterval.Message
+		})
+	case "type":
+		return compileStringComparison(field, operator, value, func(eventInterval EventInterval) string {
+			return string(LocatorFrom(eventInterval).Type)
+		})
+	}
+
+	if strings.HasPrefix(field, "annotation.") {
+		key := AnnotationKey(strings.TrimPrefix(field, "annotation."))
+		return compileStringComparison(field, operator, value, func(eventInterval EventInterval) string {
+			return AnnotationFrom(eventInterval, key)
+		})
+	}
+
+	key := LocatorKey(field)
+	return compileStringComparison(field, operator, value, func(eventInterval EventInterval) string {
+		locator := LocatorFrom(eventInterval)
+		if key == LocatorNamespaceKey {
+			return locator.Namespace()
+		}
+		return locator.Get(key)
+	})
+}
+
+func compileStringComparison(field, operator, value string, valueFn func(EventInterval) string) (EventIntervalMatchesFunc, error) {
+	switch operator {
+	case "=":
+		return func(eventInterval EventInterval) bool {
+			return valueFn(eventInterval) == value
+		}, nil
+	case "!=":
+		return func(eventInterval EventInterval) bool {
+			return valueFn(eventInterval) != value
+		}, nil
+	case "contains":
+		return func(eventInterval EventInterval) bool {
+			return strings.Contains(valueFn(eventInterval), value)
+		}, nil
+	case "=~", "!~":
+		re, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return nil, err
+		}
+		want := operator == "=~"
+		return func(eventInterval EventInterval) bool {
+			return re.MatchString(valueFn(eventInterval)) == want
+		}, nil
+	default:
+		return nil, fmt.Errorf("%s does not support %q", field, operator)
+	}
+}
+
+func compileLevelComparison(operator, value string) (EventIntervalMatchesFunc, error) {
+	if operator == "=~" || operator == "!~" || operator == "contains" {
+		return compileStringComparison("level", operator, value, func(eventInterval EventInterval) string {
+			return eventInterval.Level.String()
+		})
+	}
+	level, err := EventLevelFromString(value)
+	if err != nil {
+		return nil, err
+	}
+	compare, err := orderedComparison(operator)
+	if err != nil {
+		return nil, err
+	}
+	return func(eventInterval EventInterval) bool {
+		return compare(int64(eventInterval.Level) - int64(level))
+	}, nil
+}
+
+func compileDurationComparison(operator, value string) (EventIntervalMatchesFunc, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, err
+	}
+	compare, err := orderedComparison(operator)
+	if err != nil {
+		return nil, err
+	}
+	return func(eventInterval EventInterval) bool {
+		actual := time.Duration(0)
+		if !eventInterval.To.IsZero() {
+			actual = eventInterval.To.Sub(eventInterval.From)
+		}
+		return compare(int64(actual - duration))
+	}, nil
+}
+
+// orderedComparison returns a function that applies operator to the sign of actual - expected.
+func orderedComparison(operator string) (func(difference int64) bool, error) {
+	switch operator {
+	case "=":
+		return func(difference int64) bool { return difference == 0 }, nil
+	case "!=":
+		return func(difference int64) bool { return difference != 0 }, nil
+	case "<":
+		return func(difference int64) bool { return difference < 0 }, nil
+	case "<=":
+		return func(difference int64) bool { return difference <= 0 }, nil
+	case ">":
+		return func(difference int64) bool { return difference > 0 }, nil
+	case ">=":
+		return func(difference int64) bool { return difference >= 0 }, nil
+	default:
+		return nil, fmt.Errorf("unsupported operator %q", operator)
+	}
+}
@@ -0,0 +1,93 @@
+package monitorapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	probe := EventInterval{
+		Condition: Condition{
+			Level:   Warning,
+			Locator: "ns/openshift-etcd pod/etcd-0 node/master-0 uid/a1947638 container/etcd",
+			Message: "reason/Unhealthy Readiness probe failed",
+		},
+		From: start,
+		To:   start.Add(10 * time.Second),
+	}
+	disruption := EventInterval{
+		Condition: Condition{
+			Level:   Error,
+			Locator: "disruption/kube-api connection/new",
+			Message: "stopped responding to GET requests over new connections",
+		},
+		From: start,
+		To:   start.Add(2 * time.Second),
+	}
+	open := EventInterval{
+		Condition: Condition{
+			Level:   Info,
+			Locator: "node/master-0",
+			Message: "reason/NotReady",
+		},
+		From: start,
+	}
+
+	tests := []struct {
+		query string
+		want  []bool // probe, disruption, open
+	}{
+		{query: `level>=Warning and ns=~"openshift-etcd.*" and message contains "probe" and duration>5s`, want: []bool{true, false, false}},
+		{query: `level>=Warning`, want: []bool{true, true, false}},
+		{query: `level<Error`, want: []bool{true, false, true}},
+		{query: `level=Info`, want: []bool{false, false, true}},
+		{query: `duration>5s`, want: []bool{true, false, false}},
+		{query: `duration<=2s`, want: []bool{false, true, true}},
+		{query: `ns=openshift-etcd`, want: []bool{true, false, false}},
+		{query: `ns=~"openshift"`, want: []bool{false, false, false}},
+		{query: `ns!~"openshift-.*"`, want: []bool{false, true, true}},
+		{query: `node=master-0 or disruption=kube-api`, want: []bool{true, true, true}},
+		{query: `not type=Node`, want: []bool{true, true, false}},
+		{query: `type=Disruption or (level=Info and annotation.reason=NotReady)`, want: []bool{false, true, true}},
+		{query: `annotation.reason!=Unhealthy AND NOT message CONTAINS "GET"`, want: []bool{false, false, true}},
+		{query: `locator contains "connection/new"`, want: []bool{false, true, false}},
+		{query: `container="etcd"`, want: []bool{true, false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			matcher, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, eventInterval := range []EventInterval{probe, disruption, open} {
+				if got := matcher(eventInterval); got != tt.want[i] {
+					t.Errorf("%s: expected %v, got %v", eventInterval.Locator, tt.want[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []string{
+		``,
+		`level`,
+		`level>=`,
+		`level>=Critical`,
+		`duration>soon`,
+		`message>"a"`,
+		`ns=~"("`,
+		`(level=Info`,
+		`level=Info)`,
+		`level=Info and`,
+		`message contains "unterminated`,
+	}
+	for _, query := range tests {
+		t.Run(query, func(t *testing.T) {
+			if _, err := ParseQuery(query); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
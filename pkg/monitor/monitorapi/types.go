@@ -64,6 +64,31 @@ type Condition struct {
 
 	Locator string
 	Message string
+
+	// StructuredLocator is the typed form of Locator.  Producers may leave it empty, in which case it is
+	// filled in from Locator by EnsureStructured when the condition is recorded or deserialized.
+	StructuredLocator Locator
+	// StructuredMessage carries the annotations of Message separately from the human readable text.  Producers may
+	// leave it empty, in which case it is parsed from Message by EnsureStructured.
+	StructuredMessage Message
+}
+
+// EnsureStructured fills in the structured fields of the condition from the legacy string fields (and the
+// reverse) when the producer only set one of them.
+func EnsureStructured(condition Condition) Condition {
+	switch {
+	case condition.StructuredLocator.IsZero() && len(condition.Locator) > 0:
+		condition.StructuredLocator = ParseLocator(condition.Locator)
+	case len(condition.Locator) == 0 && !condition.StructuredLocator.IsZero():
+		condition.Locator = condition.StructuredLocator.OldLocator()
+	}
+	switch {
+	case condition.StructuredMessage.IsZero() && len(condition.Message) > 0:
+		condition.StructuredMessage = ParseMessage(condition.Message)
+	case len(condition.Message) == 0 && !condition.StructuredMessage.IsZero():
+		condition.Message = condition.StructuredMessage.OldMessage()
+	}
+	return condition
 }
 
 type EventInterval struct {
@@ -201,9 +226,9 @@ func IsInNamespaces(namespaces sets.String) EventIntervalMatchesFunc {
 // ContainsAllParts ensures that all listed key match at least one of the values.
 func ContainsAllParts(matchers map[string][]*regexp.Regexp) EventIntervalMatchesFunc {
 	return func(eventInterval EventInterval) bool {
-		actualParts := LocatorParts(eventInterval.Locator)
+		actualParts := LocatorFrom(eventInterval)
 		for key, possibleValues := range matchers {
-			actualValue := actualParts[key]
+			actualValue := actualParts.Get(LocatorKey(key))
 
 			found := false
 			for _, possibleValue := range possibleValues {
@@ -224,9 +249,9 @@ func ContainsAllParts(matchers map[string][]*regexp.Regexp) EventIntervalMatches
 // NotContainsAllParts returns a function that returns false if any key matches.
 func NotContainsAllParts(matchers map[string][]*regexp.Regexp) EventIntervalMatchesFunc {
 	return func(eventInterval EventInterval) bool {
-		actualParts := LocatorParts(eventInterval.Locator)
+		actualParts := LocatorFrom(eventInterval)
 		for key, possibleValues := range matchers {
-			actualValue := actualParts[key]
+			actualValue := actualParts.Get(LocatorKey(key))
 
 			for _, possibleValue := range possibleValues {
 				if possibleValue.MatchString(actualValue) {
@@ -279,23 +304,18 @@ func EndedAfter(limit time.Time) EventIntervalMatchesFunc {
 }
 
 func NodeUpdate(eventInterval EventInterval) bool {
-	reason := ReasonFrom(eventInterval.Message)
-	return "NodeUpdate" == reason
+	return HasAnnotation(AnnotationReason, "NodeUpdate")(eventInterval)
 }
 
 func AlertFiringInNamespace(alertName, namespace string) EventIntervalMatchesFunc {
 	return func(eventInterval EventInterval) bool {
 		return And(
 			func(eventInterval EventInterval) bool {
-				locatorParts := LocatorParts(eventInterval.Locator)
-				eventAlertName := AlertFrom(locatorParts)
+				eventAlertName := AlertFrom(LocatorFrom(eventInterval))
 				if eventAlertName != alertName {
 					return false
 				}
-				if strings.Contains(eventInterval.Message, `alertstate="firing"`) {
-					return true
-				}
-				return false
+				return AnnotationFrom(eventInterval, AnnotationAlertState) == "firing"
 			},
 			InNamespace(namespace),
 		)(eventInterval)
@@ -306,15 +326,11 @@ func AlertPendingInNamespace(alertName, namespace string) EventIntervalMatchesFu
 	return func(eventInterval EventInterval) bool {
 		return And(
 			func(eventInterval EventInterval) bool {
-				locatorParts := LocatorParts(eventInterval.Locator)
-				eventAlertName := AlertFrom(locatorParts)
+				eventAlertName := AlertFrom(LocatorFrom(eventInterval))
 				if eventAlertName != alertName {
 					return false
 				}
-				if strings.Contains(eventInterval.Message, `alertstate="pending"`) {
-					return true
-				}
-				return false
+				return AnnotationFrom(eventInterval, AnnotationAlertState) == "pending"
 			},
 			InNamespace(namespace),
 		)(eventInterval)
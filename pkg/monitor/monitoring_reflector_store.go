@@ -34,6 +34,14 @@ type monitoringStore struct {
 	// we've already recorded.
 	processedResourceUIDs map[types.UID]int
 	cacheOfNow            map[types.UID]interface{}
+	// delivered times the watches of the reflector filling the store, see watchWithLag.
+	delivered *deliveryTimes
+}
+
+// watchWithLag returns a ListerWatcher for the reflector filling the store, so the store can report how long the
+// objects from its watches waited to be handled.
+func (s *monitoringStore) watchWithLag(lw cache.ListerWatcher) cache.ListerWatcher {
+	return s.delivered.wrap(lw)
 }
 
 func newMonitoringStore(
@@ -48,14 +56,18 @@ func newMonitoringStore(
 		FakeCustomStore:       &cache.FakeCustomStore{},
 		processedResourceUIDs: map[types.UID]int{},
 		cacheOfNow:            map[types.UID]interface{}{},
+		delivered:             newDeliveryTimes(),
 	}
 
 	s.UpdateFunc = func(obj interface{}) error {
 		currentUID := uidOf(obj)
 		currentResourceVersion := resourceVersionAsInt(obj)
+		lag := s.delivered.lag(obj)
 		if s.processedResourceUIDs[currentUID] >= currentResourceVersion {
+			recordNotification(conditionRecorder, resourceType, NotificationDuplicate, 0)
 			return nil
 		}
+		recordNotification(conditionRecorder, resourceType, NotificationHandled, lag)
 
 		defer func() {
 			s.processedResourceUIDs[currentUID] = currentResourceVersion
@@ -79,9 +91,12 @@ func newMonitoringStore(
 	s.AddFunc = func(obj interface{}) error {
 		currentUID := uidOf(obj)
 		currentResourceVersion := resourceVersionAsInt(obj)
+		lag := s.delivered.lag(obj)
 		if s.processedResourceUIDs[currentUID] >= currentResourceVersion {
+			recordNotification(conditionRecorder, resourceType, NotificationDuplicate, 0)
 			return nil
 		}
+		recordNotification(conditionRecorder, resourceType, NotificationHandled, lag)
 
 		defer func() {
 			s.processedResourceUIDs[currentUID] = currentResourceVersion
@@ -100,9 +115,13 @@ func newMonitoringStore(
 	s.DeleteFunc = func(obj interface{}) error {
 		currentUID := uidOf(obj)
 		currentResourceVersion := resourceVersionAsInt(obj)
+		lag := s.delivered.lag(obj)
+		s.delivered.forget(obj)
 		if s.processedResourceUIDs[currentUID] >= currentResourceVersion {
+			recordNotification(conditionRecorder, resourceType, NotificationDuplicate, 0)
 			return nil
 		}
+		recordNotification(conditionRecorder, resourceType, NotificationHandled, lag)
 
 		// clear values that have been deleted
 		defer func() {
@@ -10,7 +10,9 @@ import (
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 
 	corev1 "k8s.io/api/core/v1"
-	informercorev1 "k8s.io/client-go/informers/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 )
@@ -71,15 +73,33 @@ func startNodeMonitoring(ctx context.Context, m Recorder, client kubernetes.Inte
 		},
 	}
 
-	nodeInformer := informercorev1.NewNodeInformer(client, time.Hour, nil)
+	delivered := newDeliveryTimes()
+	nodeInformer := cache.NewSharedIndexInformer(
+		delivered.wrap(&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return client.CoreV1().Nodes().List(ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return client.CoreV1().Nodes().Watch(ctx, options)
+			},
+		}),
+		&corev1.Node{},
+		time.Hour,
+		cache.Indexers{},
+	)
 	nodeInformer.AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
-			AddFunc: func(obj interface{}) {},
+			AddFunc: func(obj interface{}) {
+				recordNotification(m, "nodes", NotificationHandled, delivered.lag(obj))
+			},
 			DeleteFunc: func(obj interface{}) {
+				lag := delivered.lag(obj)
+				delivered.forget(obj)
 				node, ok := obj.(*corev1.Node)
 				if !ok {
 					return
 				}
+				recordNotification(m, "nodes", NotificationHandled, lag)
 				m.Record(monitorapi.Condition{
 					Level:   monitorapi.Warning,
 					Locator: monitorapi.NodeLocator(node.Name),
@@ -95,6 +115,12 @@ func startNodeMonitoring(ctx context.Context, m Recorder, client kubernetes.Inte
 				if !ok {
 					return
 				}
+				if node.ResourceVersion == oldNode.ResourceVersion {
+					// a resync hands out every node again
+					recordNotification(m, "nodes", NotificationDuplicate, 0)
+					return
+				}
+				recordNotification(m, "nodes", NotificationHandled, delivered.lag(obj))
 				for _, fn := range nodeChangeFns {
 					m.Record(fn(node, oldNode)...)
 				}
@@ -524,7 +524,7 @@ func startPodMonitoring(ctx context.Context, m Recorder, client kubernetes.Inter
 		m,
 		m,
 	)
-	reflector := cache.NewReflector(listWatch, &corev1.Pod{}, customStore, 0)
+	reflector := cache.NewReflector(customStore.watchWithLag(listWatch), &corev1.Pod{}, customStore, 0)
 	go reflector.Run(ctx.Done())
 
 	// start controller to watch for shared pod IPs.
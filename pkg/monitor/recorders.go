@@ -0,0 +1,137 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	configclientset "github.com/openshift/client-go/config/clientset/versioned"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// RecorderRegistry is a named set of StartEventIntervalRecorderFuncs.  Each recorder is either enabled or disabled by
+// default, and a list of selectors (see Select) changes which ones a run starts.
+type RecorderRegistry struct {
+	names     []string
+	recorders map[string]registeredRecorder
+}
+
+type registeredRecorder struct {
+	start            StartEventIntervalRecorderFunc
+	enabledByDefault bool
+}
+
+// NewRecorderRegistry returns an empty registry.
+func NewRecorderRegistry() *RecorderRegistry {
+	return &RecorderRegistry{recorders: map[string]registeredRecorder{}}
+}
+
+// NewDefaultRecorderRegistry returns a registry holding the recorders built into the monitor: pods, nodes, events
+// and clusteroperators.  They are all enabled by default.
+func NewDefaultRecorderRegistry() *RecorderRegistry {
+	r := NewRecorderRegistry()
+	r.Register("pods", true, kubeClientRecorder(startPodMonitoring))
+	r.Register("nodes", true, kubeClientRecorder(startNodeMonitoring))
+	r.Register("events", true, kubeClientRecorder(startEventMonitoring))
+	r.Register("clusteroperators", true, func(ctx context.Context, recorder Recorder, clusterConfig *rest.Config) error {
+		client, err := configclientset.NewForConfig(clusterConfig)
+		if err != nil {
+			return err
+		}
+		startClusterOperatorMonitoring(ctx, recorder, client)
+		return nil
+	})
+	return r
+}
+
+func kubeClientRecorder(startFn func(context.Context, Recorder, kubernetes.Interface)) StartEventIntervalRecorderFunc {
+	return func(ctx context.Context, recorder Recorder, clusterConfig *rest.Config) error {
+		client, err := kubernetes.NewForConfig(clusterConfig)
+		if err != nil {
+			return err
+		}
+		startFn(ctx, recorder, client)
+		return nil
+	}
+}
+
+// Register adds a recorder under name.  It panics if name is already registered or cannot be used in a selector.
+func (r *RecorderRegistry) Register(name string, enabledByDefault bool, start StartEventIntervalRecorderFunc) {
+	if len(name) == 0 || name == "*" || strings.HasPrefix(name, "-") || strings.Contains(name, ",") {
+		panic(fmt.Sprintf("invalid recorder name %q", name))
+	}
+	if _, ok := r.recorders[name]; ok {
+		panic(fmt.Sprintf("recorder %q is already registered", name))
+	}
+	r.names = append(r.names, name)
+	r.recorders[name] = registeredRecorder{start: start, enabledByDefault: enabledByDefault}
+}
+
+// Names returns the registered recorders in the order they were registered.
+func (r *RecorderRegistry) Names() []string {
+	return append([]string{}, r.names...)
+}
+
+// Select returns the names of the recorders to start, in registration order.  The selectors are applied in order to
+// the recorders that are enabled by default:
+//
+//	name    enables the recorder
+//	-name   disables the recorder
+//	*       enables every recorder
+//	-*      disables every recorder
+//
+// so "pods,nodes,-events,apiserver-disruption" starts the default recorders except events, plus apiserver-disruption,
+// and "-*,pods" starts only pods.
+func (r *RecorderRegistry) Select(selectors []string) ([]string, error) {
+	enabled := map[string]bool{}
+	for _, name := range r.names {
+		enabled[name] = r.recorders[name].enabledByDefault
+	}
+	for _, selector := range selectors {
+		selector = strings.TrimSpace(selector)
+		if len(selector) == 0 {
+			continue
+		}
+		enable := !strings.HasPrefix(selector, "-")
+		name := strings.TrimPrefix(selector, "-")
+		if name == "*" {
+			for _, name := range r.names {
+				enabled[name] = enable
+			}
+			continue
+		}
+		if _, ok := r.recorders[name]; !ok {
+			return nil, fmt.Errorf("unknown monitor %q, must be one of %s", name, strings.Join(r.sortedNames(), ", "))
+		}
+		enabled[name] = enable
+	}
+
+	selected := []string{}
+	for _, name := range r.names {
+		if enabled[name] {
+			selected = append(selected, name)
+		}
+	}
+	return selected, nil
+}
+
+// Recorders returns the start functions for the named recorders.
+func (r *RecorderRegistry) Recorders(names []string) ([]StartEventIntervalRecorderFunc, error) {
+	recorders := []StartEventIntervalRecorderFunc{}
+	for _, name := range names {
+		recorder, ok := r.recorders[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown monitor %q", name)
+		}
+		recorders = append(recorders, recorder.start)
+	}
+	return recorders, nil
+}
+
+func (r *RecorderRegistry) sortedNames() []string {
+	names := r.Names()
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,46 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestRecorderRegistry_Select(t *testing.T) {
+	noop := func(ctx context.Context, recorder Recorder, clusterConfig *rest.Config) error { return nil }
+	registry := NewRecorderRegistry()
+	registry.Register("pods", true, noop)
+	registry.Register("nodes", true, noop)
+	registry.Register("events", true, noop)
+	registry.Register("apiserver-disruption", false, noop)
+
+	tests := []struct {
+		selectors []string
+		want      []string
+		wantErr   bool
+	}{
+		{selectors: nil, want: []string{"pods", "nodes", "events"}},
+		{selectors: []string{"pods", "nodes", "-events", "apiserver-disruption"}, want: []string{"pods", "nodes", "apiserver-disruption"}},
+		{selectors: []string{"-*", "pods"}, want: []string{"pods"}},
+		{selectors: []string{"*"}, want: []string{"pods", "nodes", "events", "apiserver-disruption"}},
+		{selectors: []string{"-*"}, want: []string{}},
+		{selectors: []string{"apiserver-disruption", "-apiserver-disruption"}, want: []string{"pods", "nodes", "events"}},
+		{selectors: []string{" nodes ", ""}, want: []string{"pods", "nodes", "events"}},
+		{selectors: []string{"missing"}, wantErr: true},
+		{selectors: []string{"-missing"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v", tt.selectors), func(t *testing.T) {
+			got, err := registry.Select(tt.selectors)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package monitor
+
+import (
+	"context"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NewResourceRecorder returns a recorder that watches every instance of the provided resources.  Creates, deletes,
+// changes to metadata.generation and changes to the status of entries in status.conditions are recorded as
+// conditions, and the latest state of every instance is kept for the resource-*.zip artifacts along with the
+// observed update and recreation counts.
+func NewResourceRecorder(resources ...schema.GroupVersionResource) StartEventIntervalRecorderFunc {
+	return func(ctx context.Context, recorder Recorder, clusterConfig *rest.Config) error {
+		if len(resources) == 0 {
+			return nil
+		}
+		client, err := dynamic.NewForConfig(clusterConfig)
+		if err != nil {
+			return err
+		}
+		for _, resource := range resources {
+			startResourceMonitoring(ctx, recorder, client, resource)
+		}
+		return nil
+	}
+}
+
+// ResourceType is the key under which instances of resource are stored in a ResourcesMap, "deployments.apps" for
+// instance.  Resources in the core group keep their plain name so they match the pods and events recorded by
+// their own monitors.
+func ResourceType(resource schema.GroupVersionResource) string {
+	return resource.GroupResource().String()
+}
+
+func startResourceMonitoring(ctx context.Context, m Recorder, client dynamic.Interface, resource schema.GroupVersionResource) {
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.Resource(resource).List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.Resource(resource).Watch(ctx, options)
+		},
+	}
+	customStore := newResourceMonitoringStore(ResourceType(resource), m)
+	reflector := cache.NewReflector(customStore.watchWithLag(NewErrorRecordingListWatcher(m, listWatch)), &unstructured.Unstructured{}, customStore, 0)
+	go reflector.Run(ctx.Done())
+}
+
+func newResourceMonitoringStore(resourceType string, m Recorder) *monitoringStore {
+	return newMonitoringStore(
+		resourceType,
+		[]objCreateFunc{resourceCreated, resourceConditionsObserved},
+		[]objUpdateFunc{resourceUpdated, resourceConditionsChanged},
+		[]objDeleteFunc{resourceDeleted},
+		m,
+		m,
+	)
+}
+
+func resourceCreated(obj interface{}) []monitorapi.Condition {
+	return []monitorapi.Condition{
+		{
+			Level:   monitorapi.Info,
+			Locator: monitorapi.LocateResource(obj.(runtime.Object)),
+			Message: monitorapi.ReasonedMessage(monitorapi.ResourceReasonCreated),
+		},
+	}
+}
+
+// resourceUpdated only records changes to the generation, which most resources only bump when their spec changes.
+// Status updates are far too frequent to be worth an interval each, they are counted on the stored resource instead.
+func resourceUpdated(obj, oldObj interface{}) []monitorapi.Condition {
+	generation, oldGeneration := generationOf(obj), generationOf(oldObj)
+	if generation == oldGeneration {
+		return nil
+	}
+	return []monitorapi.Condition{
+		{
+			Level:   monitorapi.Info,
+			Locator: monitorapi.LocateResource(obj.(runtime.Object)),
+			Message: monitorapi.ReasonedMessagef(monitorapi.ResourceReasonUpdated, "generation/%d (was %d)", generation, oldGeneration),
+		},
+	}
+}
+
+func resourceDeleted(obj interface{}) []monitorapi.Condition {
+	return []monitorapi.Condition{
+		{
+			Level:   monitorapi.Info,
+			Locator: monitorapi.LocateResource(obj.(runtime.Object)),
+			Message: monitorapi.ReasonedMessage(monitorapi.ResourceReasonDeleted),
+		},
+	}
+}
+
+func generationOf(obj interface{}) int64 {
+	metadata, err := meta.Accessor(obj)
+	if err != nil {
+		panic(err)
+	}
+	return metadata.GetGeneration()
+}
+
+type statusCondition struct {
+	Type    string
+	Status  string
+	Reason  string
+	Message string
+}
+
+// statusConditionsOf reads the conventional status.conditions list, which metav1.Condition and most operator
+// conditions follow.  Objects without one have no conditions.
+func statusConditionsOf(obj interface{}) []statusCondition {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+	items, found, err := unstructured.NestedSlice(u.Object, "status", "conditions")
+	if err != nil || !found {
+		return nil
+	}
+	conditions := []statusCondition{}
+	for _, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		condition := statusCondition{}
+		condition.Type, _, _ = unstructured.NestedString(fields, "type")
+		condition.Status, _, _ = unstructured.NestedString(fields, "status")
+		condition.Reason, _, _ = unstructured.NestedString(fields, "reason")
+		condition.Message, _, _ = unstructured.NestedString(fields, "message")
+		if len(condition.Type) == 0 {
+			continue
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions
+}
+
+// resourceConditionsObserved records the starting state of every condition so that intervals can be built for
+// conditions that were already abnormal when the monitor started.
+func resourceConditionsObserved(obj interface{}) []monitorapi.Condition {
+	return conditionChanges(obj, nil)
+}
+
+func resourceConditionsChanged(obj, oldObj interface{}) []monitorapi.Condition {
+	return conditionChanges(obj, statusConditionsOf(oldObj))
+}
+
+func conditionChanges(obj interface{}, previousConditions []statusCondition) []monitorapi.Condition {
+	previousStatus := map[string]string{}
+	for _, previous := range previousConditions {
+		previousStatus[previous.Type] = previous.Status
+	}
+
+	var conditions []monitorapi.Condition
+	for _, c := range statusConditionsOf(obj) {
+		if status, ok := previousStatus[c.Type]; ok && status == c.Status {
+			continue
+		}
+		level := monitorapi.Info
+		if monitorapi.IsAbnormalCondition(c.Type, c.Status) {
+			level = monitorapi.Warning
+		}
+		conditions = append(conditions, monitorapi.Condition{
+			Level:   level,
+			Locator: monitorapi.LocateResource(obj.(runtime.Object)),
+			Message: monitorapi.ConditionChangedMessage(c.Type, c.Status, c.Reason, c.Message),
+		})
+	}
+	return conditions
+}
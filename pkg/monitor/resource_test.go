@@ -0,0 +1,137 @@
+package monitor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newMachineConfigPool(name, uid, resourceVersion string, generation int64) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("machineconfiguration.openshift.io/v1")
+	obj.SetKind("MachineConfigPool")
+	obj.SetName(name)
+	obj.SetUID(types.UID(uid))
+	obj.SetResourceVersion(resourceVersion)
+	obj.SetGeneration(generation)
+	return obj
+}
+
+func TestResourceMonitoringStore(t *testing.T) {
+	m := NewMonitor()
+	resourceType := ResourceType(schema.GroupVersionResource{Group: "machineconfiguration.openshift.io", Version: "v1", Resource: "machineconfigpools"})
+	store := newResourceMonitoringStore(resourceType, m)
+
+	if err := store.Replace([]interface{}{newMachineConfigPool("worker", "a", "1", 1)}, "1"); err != nil {
+		t.Fatal(err)
+	}
+	// a status update does not change the generation
+	if err := store.Update(newMachineConfigPool("worker", "a", "2", 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Update(newMachineConfigPool("worker", "a", "3", 2)); err != nil {
+		t.Fatal(err)
+	}
+	// an update we have already seen is ignored
+	if err := store.Update(newMachineConfigPool("worker", "a", "3", 2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(newMachineConfigPool("worker", "a", "4", 2)); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, interval := range m.Intervals(time.Time{}, time.Time{}) {
+		got = append(got, interval.Locator+" "+interval.Message)
+	}
+	want := []string{
+		"machineconfigpool/worker uid/a reason/Created ",
+		"machineconfigpool/worker uid/a reason/Updated generation/2 (was 1)",
+		"machineconfigpool/worker uid/a reason/Deleted ",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected\n%q\ngot\n%q", want, got)
+	}
+
+	if resourceType != "machineconfigpools.machineconfiguration.openshift.io" {
+		t.Errorf("unexpected resource type %q", resourceType)
+	}
+	instances := m.CurrentResourceState()[resourceType]
+	if len(instances) != 1 {
+		t.Fatalf("expected one instance, got %d", len(instances))
+	}
+	for _, obj := range instances {
+		metadata, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count := metadata.GetAnnotations()[monitorapi.ObservedUpdateCountAnnotation]; count != "4" {
+			t.Errorf("expected 4 observed updates, got %s", count)
+		}
+	}
+}
+
+func TestResourceConditionsChanged(t *testing.T) {
+	withConditions := func(obj *unstructured.Unstructured, conditions ...map[string]interface{}) *unstructured.Unstructured {
+		items := []interface{}{}
+		for _, condition := range conditions {
+			items = append(items, condition)
+		}
+		if err := unstructured.SetNestedSlice(obj.Object, items, "status", "conditions"); err != nil {
+			t.Fatal(err)
+		}
+		return obj
+	}
+	oldPool := withConditions(newMachineConfigPool("worker", "a", "1", 1),
+		map[string]interface{}{"type": "Updated", "status": "True"},
+		map[string]interface{}{"type": "Updating", "status": "False"},
+	)
+	pool := withConditions(newMachineConfigPool("worker", "a", "2", 2),
+		map[string]interface{}{"type": "Updated", "status": "False", "reason": "Rolling"},
+		map[string]interface{}{"type": "Updating", "status": "True", "message": "rolling out rendered-worker-2"},
+		map[string]interface{}{"type": "Degraded", "status": "False"},
+	)
+
+	var got []string
+	for _, condition := range resourceConditionsChanged(pool, oldPool) {
+		got = append(got, condition.Level.String()+" "+condition.Message)
+	}
+	want := []string{
+		"Warning condition/Updated status/False reason/Rolling changed: ",
+		"Warning condition/Updating status/True changed: rolling out rendered-worker-2",
+		"Info condition/Degraded status/False changed: ",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected\n%q\ngot\n%q", want, got)
+	}
+
+	if changes := resourceConditionsChanged(pool, pool); len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+}
+
+func TestResourceConditionsObservedOfHealthyDeployment(t *testing.T) {
+	deployment := &unstructured.Unstructured{}
+	deployment.SetAPIVersion("apps/v1")
+	deployment.SetKind("Deployment")
+	deployment.SetName("console")
+	items := []interface{}{
+		map[string]interface{}{"type": "Available", "status": "True", "reason": "MinimumReplicasAvailable"},
+		map[string]interface{}{"type": "Progressing", "status": "True", "reason": "NewReplicaSetAvailable"},
+	}
+	if err := unstructured.SetNestedSlice(deployment.Object, items, "status", "conditions"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, condition := range resourceConditionsObserved(deployment) {
+		if condition.Level != monitorapi.Info {
+			t.Errorf("expected a healthy deployment to record no warnings, got %s %s", condition.Level, condition.Message)
+		}
+	}
+}
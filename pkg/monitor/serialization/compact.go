@@ -0,0 +1,278 @@
+package monitorserialization
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// A compact events file is a multi-member gzip stream, so that zcat prints it as JSON lines.  The first member is an
+// index of the blocks that follow.  Each block holds the intervals of a single locator type, sorted by From, as
+// record entries of an event journal.  Readers use the index to decompress only the blocks a query needs.
+const (
+	compactFormat        = "openshift-e2e-events-compact"
+	compactFormatVersion = 1
+	// compactBlockSize is the most intervals in a block.
+	compactBlockSize = 1000
+)
+
+// compactIndex is the first member of a compact events file.
+type compactIndex struct {
+	Format  string         `json:"format"`
+	Version int            `json:"version"`
+	Blocks  []compactBlock `json:"blocks"`
+}
+
+// compactBlock locates a block.  Offsets are relative to the end of the index member.
+type compactBlock struct {
+	Offset      int64                  `json:"offset"`
+	Length      int64                  `json:"length"`
+	Count       int                    `json:"count"`
+	LocatorType monitorapi.LocatorType `json:"locatorType"`
+	// From is the earliest From in the block, To is the latest end.
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Open is set if an interval in the block never ended, in which case it overlaps everything after From.
+	Open bool `json:"open,omitempty"`
+}
+
+// CompactQuery selects the intervals read from a compact events file.  Zero values match everything.
+type CompactQuery struct {
+	// From and To select the intervals that overlap [From, To].
+	From time.Time
+	To   time.Time
+	// LocatorTypes selects intervals whose locator has one of these types.
+	LocatorTypes []monitorapi.LocatorType
+}
+
+func (q CompactQuery) matchesLocatorType(locatorType monitorapi.LocatorType) bool {
+	if len(q.LocatorTypes) == 0 {
+		return true
+	}
+	for _, curr := range q.LocatorTypes {
+		if curr == locatorType {
+			return true
+		}
+	}
+	return false
+}
+
+func (q CompactQuery) overlaps(from, to time.Time, open bool) bool {
+	if !q.To.IsZero() && from.After(q.To) {
+		return false
+	}
+	if !q.From.IsZero() && !open && to.Before(q.From) {
+		return false
+	}
+	return true
+}
+
+// EventsToCompactFile writes events in the compact format, see EventsToCompact.
+func EventsToCompactFile(filename string, events monitorapi.Intervals) error {
+	data, err := EventsToCompact(events)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0644)
+}
+
+// EventsToCompact serializes events as gzipped JSON lines with a locator type and time index.  It is much smaller
+// than EventsToJSON and can be read in part with EventsFromCompact.  Times keep their full precision.
+func EventsToCompact(events monitorapi.Intervals) ([]byte, error) {
+	byLocatorType := map[monitorapi.LocatorType]monitorapi.Intervals{}
+	for _, event := range events {
+		locatorType := monitorapi.LocatorFrom(event).Type
+		byLocatorType[locatorType] = append(byLocatorType[locatorType], event)
+	}
+	locatorTypes := []string{}
+	for locatorType := range byLocatorType {
+		locatorTypes = append(locatorTypes, string(locatorType))
+	}
+	sort.Strings(locatorTypes)
+
+	index := compactIndex{Format: compactFormat, Version: compactFormatVersion, Blocks: []compactBlock{}}
+	blocks := &bytes.Buffer{}
+	for _, locatorType := range locatorTypes {
+		intervals := byLocatorType[monitorapi.LocatorType(locatorType)]
+		sort.SliceStable(intervals, func(i, j int) bool {
+			if !intervals[i].From.Equal(intervals[j].From) {
+				return intervals[i].From.Before(intervals[j].From)
+			}
+			return intervals[i].Locator < intervals[j].Locator
+		})
+		for start := 0; start < len(intervals); start += compactBlockSize {
+			end := start + compactBlockSize
+			if end > len(intervals) {
+				end = len(intervals)
+			}
+			block := compactBlock{
+				Offset:      int64(blocks.Len()),
+				LocatorType: monitorapi.LocatorType(locatorType),
+			}
+			entries := make([]interface{}, 0, end-start)
+			for _, interval := range intervals[start:end] {
+				block.addInterval(interval)
+				entries = append(entries, newJournalEntry(JournalOpRecord, 0, interval))
+			}
+			if err := writeGzipMember(blocks, entries...); err != nil {
+				return nil, err
+			}
+			block.Length = int64(blocks.Len()) - block.Offset
+			index.Blocks = append(index.Blocks, block)
+		}
+	}
+
+	ret := &bytes.Buffer{}
+	if err := writeGzipMember(ret, index); err != nil {
+		return nil, err
+	}
+	if _, err := blocks.WriteTo(ret); err != nil {
+		return nil, err
+	}
+	return ret.Bytes(), nil
+}
+
+func (b *compactBlock) addInterval(interval monitorapi.EventInterval) {
+	if b.Count == 0 || interval.From.Before(b.From) {
+		b.From = interval.From
+	}
+	if interval.From.After(b.To) {
+		b.To = interval.From
+	}
+	if interval.To.After(b.To) {
+		b.To = interval.To
+	}
+	if interval.To.IsZero() {
+		b.Open = true
+	}
+	b.Count++
+}
+
+func writeGzipMember(w io.Writer, lines ...interface{}) error {
+	gzipWriter := gzip.NewWriter(w)
+	encoder := json.NewEncoder(gzipWriter)
+	for _, line := range lines {
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+	return gzipWriter.Close()
+}
+
+// EventsFromCompactFile reads the intervals in filename that match query, see EventsFromCompact.
+func EventsFromCompactFile(filename string, query CompactQuery) (monitorapi.Intervals, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return EventsFromCompact(file, query)
+}
+
+// EventsFromCompact reads the intervals written by EventsToCompact that match query, sorted by From.  Only the blocks
+// that can contain matching intervals are decompressed.
+func EventsFromCompact(r io.ReaderAt, query CompactQuery) (monitorapi.Intervals, error) {
+	index, blocksStart, err := readCompactIndex(r)
+	if err != nil {
+		return nil, err
+	}
+
+	events := monitorapi.Intervals{}
+	for i, block := range index.Blocks {
+		if !query.matchesLocatorType(block.LocatorType) || !query.overlaps(block.From, block.To, block.Open) {
+			continue
+		}
+		blockReader, err := gzip.NewReader(io.NewSectionReader(r, blocksStart+block.Offset, block.Length))
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		decoder := json.NewDecoder(blockReader)
+		for {
+			entry := JournalEntry{}
+			if err := decoder.Decode(&entry); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("block %d: %w", i, err)
+			}
+			if entry.Op != JournalOpRecord || entry.From == nil {
+				return nil, fmt.Errorf("block %d: unexpected %q entry", i, entry.Op)
+			}
+			interval, err := entry.toEventInterval()
+			if err != nil {
+				return nil, fmt.Errorf("block %d: %w", i, err)
+			}
+			if query.overlaps(interval.From, interval.To, interval.To.IsZero()) {
+				events = append(events, interval)
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].From.Equal(events[j].From) {
+			return events[i].From.Before(events[j].From)
+		}
+		return events[i].Locator < events[j].Locator
+	})
+	return events, nil
+}
+
+var errNotCompact = errors.New("not a compact events file")
+
+// readCompactIndex returns the index of a compact events file and the offset of its first block.  It returns
+// errNotCompact for gzip streams that are not compact events files.
+func readCompactIndex(r io.ReaderAt) (*compactIndex, int64, error) {
+	// gzip reads exactly the bytes of the member from a ByteReader, so counting them finds the first block.
+	counter := &countingReader{r: bufio.NewReader(io.NewSectionReader(r, 0, math.MaxInt64))}
+	indexReader, err := gzip.NewReader(counter)
+	if err != nil {
+		return nil, 0, err
+	}
+	indexReader.Multistream(false)
+	data, err := ioutil.ReadAll(indexReader)
+	if err != nil {
+		return nil, 0, err
+	}
+	index := &compactIndex{}
+	if err := json.Unmarshal(data, index); err != nil || index.Format != compactFormat {
+		return nil, 0, errNotCompact
+	}
+	if index.Version > compactFormatVersion {
+		return nil, 0, fmt.Errorf("compact events version %d is newer than the supported version %d", index.Version, compactFormatVersion)
+	}
+	return index, counter.n, nil
+}
+
+type countingReader struct {
+	r *bufio.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
+
+// isGzip returns true if data starts with the gzip magic number.
+func isGzip(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b
+}
@@ -0,0 +1,231 @@
+package monitorserialization
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// JournalOp is the kind of change a JournalEntry records.
+type JournalOp string
+
+const (
+	// JournalOpRecord appends a complete interval.
+	JournalOpRecord JournalOp = "record"
+	// JournalOpStart appends an interval that has not ended yet.  It is closed by a later JournalOpEnd with the same ID.
+	JournalOpStart JournalOp = "start"
+	// JournalOpEnd sets the To of the interval started with the same ID.
+	JournalOpEnd JournalOp = "end"
+)
+
+// JournalEntry is a single line of an event journal.  The condition fields match EventInterval, but From and To
+// keep their full precision so that intervals read back from a journal match the ones that were recorded.
+type JournalEntry struct {
+	Op JournalOp `json:"op"`
+	ID int       `json:"id,omitempty"`
+
+	Level       string            `json:"level,omitempty"`
+	Locator     string            `json:"locator,omitempty"`
+	Message     string            `json:"message,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+}
+
+func newJournalEntry(op JournalOp, id int, interval monitorapi.EventInterval) JournalEntry {
+	serialized := monitorEventIntervalToEventInterval(interval)
+	entry := JournalEntry{
+		Op:          op,
+		ID:          id,
+		Level:       serialized.Level,
+		Locator:     serialized.Locator,
+		Message:     serialized.Message,
+		Annotations: serialized.Annotations,
+		From:        &interval.From,
+	}
+	if op == JournalOpRecord {
+		entry.To = &interval.To
+	}
+	return entry
+}
+
+func (e JournalEntry) toEventInterval() (monitorapi.EventInterval, error) {
+	interval, err := eventIntervalToMonitorEventInterval(EventInterval{
+		Level:       e.Level,
+		Locator:     e.Locator,
+		Message:     e.Message,
+		Annotations: e.Annotations,
+	})
+	if err != nil {
+		return monitorapi.EventInterval{}, err
+	}
+	if e.From != nil {
+		interval.From = *e.From
+	}
+	if e.To != nil {
+		interval.To = *e.To
+	}
+	return interval, nil
+}
+
+// JournalWriter appends intervals to an append-only file of JSON lines as they are recorded, so that they survive
+// the process crashing and do not have to be held in memory until the end of a run.  It is safe for concurrent use.
+type JournalWriter struct {
+	filename string
+
+	lock sync.Mutex
+	file *os.File
+}
+
+// NewJournalWriter creates (or truncates) filename and returns a writer for it.
+func NewJournalWriter(filename string) (*JournalWriter, error) {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &JournalWriter{filename: filename, file: file}, nil
+}
+
+// Filename returns the path of the journal.
+func (j *JournalWriter) Filename() string {
+	return j.filename
+}
+
+// Record appends a complete interval.
+func (j *JournalWriter) Record(interval monitorapi.EventInterval) error {
+	return j.write(newJournalEntry(JournalOpRecord, 0, interval))
+}
+
+// StartInterval appends an interval that is still open.  id must be unique within the journal.
+func (j *JournalWriter) StartInterval(id int, interval monitorapi.EventInterval) error {
+	return j.write(newJournalEntry(JournalOpStart, id, interval))
+}
+
+// EndInterval closes the interval previously started with id.
+func (j *JournalWriter) EndInterval(id int, to time.Time) error {
+	return j.write(JournalEntry{Op: JournalOpEnd, ID: id, To: &to})
+}
+
+// Intervals reads back everything written to the journal so far.
+func (j *JournalWriter) Intervals() (monitorapi.Intervals, error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return EventsFromJournalFile(j.filename)
+}
+
+// Close closes the underlying file.  Writes after Close return an error.
+func (j *JournalWriter) Close() error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.file.Close()
+}
+
+// write encodes the entry as a single line.  Each line is written with a single call so a crash can only ever
+// leave the last line truncated.
+func (j *JournalWriter) write(entry JournalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	_, err = j.file.Write(line)
+	return err
+}
+
+// EventsFromJournalFile rebuilds the intervals recorded in the journal at filename.
+func EventsFromJournalFile(filename string) (monitorapi.Intervals, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return EventsFromJournal(file)
+}
+
+// EventsFromJournal rebuilds the intervals recorded in a journal.  A journal written by a process that crashed may
+// end with a partial line, which is ignored.  Intervals that were started but never ended are returned with a zero To.
+func EventsFromJournal(r io.Reader) (monitorapi.Intervals, error) {
+	reader := bufio.NewReader(r)
+	events := monitorapi.Intervals{}
+	started := map[int]int{}
+	for lineNumber := 1; ; lineNumber++ {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, readErr
+		}
+		complete := readErr == nil
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			entry := JournalEntry{}
+			if err := json.Unmarshal(line, &entry); err != nil {
+				if !complete {
+					// the writer died part way through this line
+					break
+				}
+				return nil, fmt.Errorf("journal line %d: %w", lineNumber, err)
+			}
+			if err := applyJournalEntry(&events, started, entry); err != nil {
+				return nil, fmt.Errorf("journal line %d: %w", lineNumber, err)
+			}
+		}
+		if !complete {
+			break
+		}
+	}
+	return events, nil
+}
+
+func applyJournalEntry(events *monitorapi.Intervals, started map[int]int, entry JournalEntry) error {
+	switch entry.Op {
+	case JournalOpRecord, JournalOpStart:
+		if entry.From == nil {
+			return fmt.Errorf("%s entry is missing from", entry.Op)
+		}
+		interval, err := entry.toEventInterval()
+		if err != nil {
+			return err
+		}
+		if entry.Op == JournalOpStart {
+			started[entry.ID] = len(*events)
+		}
+		*events = append(*events, interval)
+	case JournalOpEnd:
+		if entry.To == nil {
+			return fmt.Errorf("end entry is missing to")
+		}
+		index, ok := started[entry.ID]
+		if !ok {
+			// the start was lost, there is nothing to close
+			return nil
+		}
+		if (*events)[index].From.Before(*entry.To) {
+			(*events)[index].To = *entry.To
+		}
+	default:
+		return fmt.Errorf("unknown journal op %q", entry.Op)
+	}
+	return nil
+}
+
+// isJournal returns true if data looks like an event journal rather than an EventIntervalList.
+func isJournal(data []byte) bool {
+	firstLine := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		firstLine = data[:i]
+	}
+	entry := JournalEntry{}
+	if err := json.Unmarshal(firstLine, &entry); err != nil {
+		return false
+	}
+	return len(entry.Op) > 0
+}
@@ -0,0 +1,84 @@
+package monitorserialization
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/openshift/origin/pkg/synthetictests/platformidentification"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	EventIntervalListKind = "EventIntervalList"
+	// EventIntervalListAPIVersion is the apiVersion of the lists written by this version of openshift-tests.
+	EventIntervalListAPIVersion = "monitor.openshift.io/v1"
+	// legacyEventIntervalListAPIVersion is the apiVersion of lists written before they were versioned.
+	legacyEventIntervalListAPIVersion = ""
+)
+
+// RunMetadata describes the run an EventIntervalList was recorded during.  Every field is optional.
+type RunMetadata struct {
+	StartTime *metav1.Time                    `json:"startTime,omitempty"`
+	EndTime   *metav1.Time                    `json:"endTime,omitempty"`
+	JobType   *platformidentification.JobType `json:"jobType,omitempty"`
+	// OpenShiftTestsVersion is the version of the openshift-tests binary that wrote the list.
+	OpenShiftTestsVersion string `json:"openshiftTestsVersion,omitempty"`
+}
+
+// eventIntervalListConverters convert lists of older apiVersions to EventIntervalListAPIVersion.  When the schema
+// changes, bump EventIntervalListAPIVersion, keep a copy of the old types for the previous version and add a converter
+// from it here.  Fields are only ever added within a version, so older readers ignore what they do not understand.
+var eventIntervalListConverters = map[string]func(data []byte) (*EventIntervalList, error){
+	legacyEventIntervalListAPIVersion: convertLegacyEventIntervalList,
+}
+
+func newEventIntervalList(items []EventInterval, metadata *RunMetadata) EventIntervalList {
+	return EventIntervalList{
+		APIVersion: EventIntervalListAPIVersion,
+		Kind:       EventIntervalListKind,
+		Metadata:   metadata,
+		Items:      items,
+	}
+}
+
+// EventIntervalListFromJSON reads a list of any supported apiVersion, including the unversioned lists written before
+// EventIntervalList had an apiVersion, and converts it to EventIntervalListAPIVersion.  Lists of unknown apiVersions
+// are rejected rather than read with missing fields.
+func EventIntervalListFromJSON(data []byte) (*EventIntervalList, error) {
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(data, &typeMeta); err != nil {
+		return nil, err
+	}
+	if len(typeMeta.Kind) > 0 && typeMeta.Kind != EventIntervalListKind {
+		return nil, fmt.Errorf("expected kind %s, got %s", EventIntervalListKind, typeMeta.Kind)
+	}
+	if typeMeta.APIVersion == EventIntervalListAPIVersion {
+		list := &EventIntervalList{}
+		if err := json.Unmarshal(data, list); err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+	convert, ok := eventIntervalListConverters[typeMeta.APIVersion]
+	if !ok {
+		return nil, fmt.Errorf("unsupported %s apiVersion %q, expected %s", EventIntervalListKind, typeMeta.APIVersion, EventIntervalListAPIVersion)
+	}
+	return convert(data)
+}
+
+// legacyEventIntervalList is the unversioned list.  Its items are the same as the first version's.
+type legacyEventIntervalList struct {
+	Items []EventInterval `json:"items"`
+}
+
+func convertLegacyEventIntervalList(data []byte) (*EventIntervalList, error) {
+	legacy := legacyEventIntervalList{}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return nil, err
+	}
+	return &EventIntervalList{
+		APIVersion: EventIntervalListAPIVersion,
+		Kind:       EventIntervalListKind,
+		Items:      legacy.Items,
+	}, nil
+}
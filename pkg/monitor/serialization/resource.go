@@ -4,10 +4,14 @@ import (
 	"archive/zip"
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -73,3 +77,90 @@ func InstanceMapToFile(filename string, resourceType string, instances monitorap
 
 	return ioutil.WriteFile(filename, byteBuffer.Bytes(), 0644)
 }
+
+// knownResourceTypes are the resource types recorded by the monitor that are read back as typed objects.  Other
+// resource types are read back as unstructured objects.
+var knownResourceTypes = map[string]func() runtime.Object{
+	"pods":   func() runtime.Object { return &corev1.Pod{} },
+	"events": func() runtime.Object { return &corev1.Event{} },
+}
+
+// InstanceMapFromFile reads a file written by InstanceMapToFile and returns the resource type it holds along with the
+// instances.
+func InstanceMapFromFile(filename string) (string, monitorapi.InstanceMap, error) {
+	zipReader, err := zip.OpenReader(filename)
+	if err != nil {
+		return "", nil, err
+	}
+	defer zipReader.Close()
+
+	resourceType := ""
+	instances := monitorapi.InstanceMap{}
+	for _, f := range zipReader.File {
+		currResourceType := strings.TrimSuffix(path.Base(f.Name), ".json")
+		if len(resourceType) == 0 {
+			resourceType = currResourceType
+		}
+		if currResourceType != resourceType {
+			return "", nil, fmt.Errorf("%s: mixes resource types %q and %q", filename, resourceType, currResourceType)
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return "", nil, err
+		}
+		currBytes, err := ioutil.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return "", nil, err
+		}
+
+		// items are written without a kind, which the unstructured decoder requires, so decode them by hand.
+		unstructuredObject := map[string]interface{}{}
+		if err := json.Unmarshal(currBytes, &unstructuredObject); err != nil {
+			return "", nil, fmt.Errorf("%s: error unmarshalling %s: %w", filename, f.Name, err)
+		}
+		list, err := (&unstructured.Unstructured{Object: unstructuredObject}).ToList()
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: error reading %s: %w", filename, f.Name, err)
+		}
+		for i := range list.Items {
+			item := &list.Items[i]
+			var obj runtime.Object = item
+			if newFn, ok := knownResourceTypes[resourceType]; ok {
+				obj = newFn()
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, obj); err != nil {
+					return "", nil, fmt.Errorf("%s: error converting %s/%s: %w", filename, item.GetNamespace(), item.GetName(), err)
+				}
+			}
+			instances[monitorapi.InstanceKey{
+				Namespace: item.GetNamespace(),
+				Name:      item.GetName(),
+				UID:       fmt.Sprintf("%v", item.GetUID()),
+			}] = obj
+		}
+	}
+
+	return resourceType, instances, nil
+}
+
+// ResourcesMapFromFiles reads the files written by InstanceMapToFile into a single ResourcesMap.
+func ResourcesMapFromFiles(filenames ...string) (monitorapi.ResourcesMap, error) {
+	resources := monitorapi.ResourcesMap{}
+	for _, filename := range filenames {
+		resourceType, instances, err := InstanceMapFromFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		if len(resourceType) == 0 {
+			continue
+		}
+		if resources[resourceType] == nil {
+			resources[resourceType] = monitorapi.InstanceMap{}
+		}
+		for key, obj := range instances {
+			resources[resourceType][key] = obj
+		}
+	}
+	return resources, nil
+}
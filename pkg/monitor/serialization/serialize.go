@@ -1,12 +1,15 @@
 package monitorserialization
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"sort"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/version"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -15,8 +18,13 @@ import (
 type EventInterval struct {
 	Level string `json:"level"`
 
+	// Locator is the legacy string form of monitorapi.Locator.  It round trips losslessly, so the structured
+	// locator is rebuilt from it on read instead of being written twice.
 	Locator string `json:"locator"`
 	Message string `json:"message"`
+	// Annotations are the machine readable parts of Message.  Artifacts written before annotations existed do not
+	// have them, in which case they are parsed from Message on read.
+	Annotations map[string]string `json:"annotations,omitempty"`
 
 	From metav1.Time `json:"from"`
 	To   metav1.Time `json:"to"`
@@ -24,6 +32,13 @@ type EventInterval struct {
 
 // EventList is not an interval.  It is an instant.  The instant removes any ambiguity about "when"
 type EventIntervalList struct {
+	// APIVersion and Kind identify the schema of the list.  Lists written before they were versioned have neither,
+	// see EventIntervalListFromJSON.
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	// Metadata describes the run the intervals were recorded during.
+	Metadata *RunMetadata `json:"metadata,omitempty"`
+
 	Items []EventInterval `json:"items"`
 }
 
@@ -35,49 +50,111 @@ func EventsToFile(filename string, events monitorapi.Intervals) error {
 	return ioutil.WriteFile(filename, json, 0644)
 }
 
+// EventsWithMetadataToFile is EventsToFile, recording metadata about the run in the list.
+func EventsWithMetadataToFile(filename string, events monitorapi.Intervals, metadata RunMetadata) error {
+	json, err := EventsWithMetadataToJSON(events, metadata)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, json, 0644)
+}
+
+// EventsFromFile reads intervals written by EventsToFile.  Event journals written by JournalWriter, files written by
+// EventsToCompactFile and gzipped copies of any of them are also accepted.
 func EventsFromFile(filename string) (monitorapi.Intervals, error) {
 	data, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
+	return eventsFromData(data)
+}
+
+func eventsFromData(data []byte) (monitorapi.Intervals, error) {
+	if isGzip(data) {
+		events, err := EventsFromCompact(bytes.NewReader(data), CompactQuery{})
+		if err != errNotCompact {
+			return events, err
+		}
+		gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		uncompressed, err := ioutil.ReadAll(gzipReader)
+		if err != nil {
+			return nil, err
+		}
+		return eventsFromData(uncompressed)
+	}
+	if isJournal(data) {
+		return EventsFromJournal(bytes.NewReader(data))
+	}
 	return EventsFromJSON(data)
 }
 
+// EventsFromJSON reads an EventIntervalList of any supported apiVersion, see EventIntervalListFromJSON.
 func EventsFromJSON(data []byte) (monitorapi.Intervals, error) {
-	var list EventIntervalList
-	if err := json.Unmarshal(data, &list); err != nil {
+	list, err := EventIntervalListFromJSON(data)
+	if err != nil {
 		return nil, err
 	}
 	events := make(monitorapi.Intervals, 0, len(list.Items))
 	for _, interval := range list.Items {
-		level, err := monitorapi.EventLevelFromString(interval.Level)
+		event, err := eventIntervalToMonitorEventInterval(interval)
 		if err != nil {
 			return nil, err
 		}
-		events = append(events, monitorapi.EventInterval{
-			Condition: monitorapi.Condition{
-				Level:   level,
-				Locator: interval.Locator,
-				Message: interval.Message,
-			},
-
-			From: interval.From.Time,
-			To:   interval.To.Time,
-		})
+		events = append(events, event)
 	}
 
 	return events, nil
 }
 
+func eventIntervalToMonitorEventInterval(interval EventInterval) (monitorapi.EventInterval, error) {
+	level, err := monitorapi.EventLevelFromString(interval.Level)
+	if err != nil {
+		return monitorapi.EventInterval{}, err
+	}
+	condition := monitorapi.Condition{
+		Level:   level,
+		Locator: interval.Locator,
+		Message: interval.Message,
+	}
+	if len(interval.Annotations) > 0 {
+		condition.StructuredMessage = monitorapi.ParseMessage(interval.Message)
+		condition.StructuredMessage.Annotations = map[monitorapi.AnnotationKey]string{}
+		for k, v := range interval.Annotations {
+			condition.StructuredMessage.Annotations[monitorapi.AnnotationKey(k)] = v
+		}
+	}
+	return monitorapi.EventInterval{
+		Condition: monitorapi.EnsureStructured(condition),
+
+		From: interval.From.Time,
+		To:   interval.To.Time,
+	}, nil
+}
+
 func EventsToJSON(events monitorapi.Intervals) ([]byte, error) {
+	return eventsToJSON(events, nil)
+}
+
+// EventsWithMetadataToJSON is EventsToJSON, recording metadata about the run in the list.  The version of
+// openshift-tests is filled in if metadata does not have one.
+func EventsWithMetadataToJSON(events monitorapi.Intervals, metadata RunMetadata) ([]byte, error) {
+	if len(metadata.OpenShiftTestsVersion) == 0 {
+		metadata.OpenShiftTestsVersion = version.Get().String()
+	}
+	return eventsToJSON(events, &metadata)
+}
+
+func eventsToJSON(events monitorapi.Intervals, metadata *RunMetadata) ([]byte, error) {
 	outputEvents := []EventInterval{}
 	for _, curr := range events {
 		outputEvents = append(outputEvents, monitorEventIntervalToEventInterval(curr))
 	}
 
 	sort.Sort(byTime(outputEvents))
-	list := EventIntervalList{Items: outputEvents}
-	return json.MarshalIndent(list, "", "    ")
+	return json.MarshalIndent(newEventIntervalList(outputEvents, metadata), "", "    ")
 }
 
 func EventsIntervalsToFile(filename string, events monitorapi.Intervals) error {
@@ -98,20 +175,27 @@ func EventsIntervalsToJSON(events monitorapi.Intervals) ([]byte, error) {
 	}
 
 	sort.Sort(byTime(outputEvents))
-	list := EventIntervalList{Items: outputEvents}
-	return json.MarshalIndent(list, "", "    ")
+	return json.MarshalIndent(newEventIntervalList(outputEvents, nil), "", "    ")
 }
 
 func monitorEventIntervalToEventInterval(interval monitorapi.EventInterval) EventInterval {
+	condition := monitorapi.EnsureStructured(interval.Condition)
 	ret := EventInterval{
 		Level:   fmt.Sprintf("%v", interval.Level),
-		Locator: interval.Locator,
-		Message: interval.Message,
+		Locator: condition.Locator,
+		Message: condition.Message,
 
 		From: metav1.Time{Time: interval.From},
 		To:   metav1.Time{Time: interval.To},
 	}
 
+	if len(condition.StructuredMessage.Annotations) > 0 {
+		ret.Annotations = map[string]string{}
+		for k, v := range condition.StructuredMessage.Annotations {
+			ret.Annotations[string(k)] = v
+		}
+	}
+
 	return ret
 }
 
@@ -0,0 +1,204 @@
+package monitor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+const (
+	// ingestionLagThreshold is how late an informer notification may be handled before the monitor records that it
+	// is falling behind.
+	ingestionLagThreshold = time.Minute
+	// ingestionLagConditionInterval limits how often the monitor records that a single informer is falling behind.
+	ingestionLagConditionInterval = time.Minute
+)
+
+// NotificationOutcome is what an informer handler did with a notification.
+type NotificationOutcome string
+
+const (
+	// NotificationHandled notifications were turned into conditions or recorded resources.
+	NotificationHandled NotificationOutcome = "Handled"
+	// NotificationDuplicate notifications were for a resource version that had already been handled.
+	NotificationDuplicate NotificationOutcome = "Duplicate"
+	// NotificationDropped notifications were deliberately ignored, for instance events from before the run.
+	NotificationDropped NotificationOutcome = "Dropped"
+)
+
+// InformerStatsRecorder is implemented by recorders that keep MonitorStats.  Informer handlers report every
+// notification they receive so that a missing interval can be told apart from a monitor that fell behind.
+type InformerStatsRecorder interface {
+	// RecordNotification counts a notification from the informer for source.  lag is how long the notification
+	// waited between the watch delivering it and the handler running, or zero for the initial list.
+	RecordNotification(source string, outcome NotificationOutcome, lag time.Duration)
+}
+
+// recordNotification reports a notification if recorder keeps stats.
+func recordNotification(recorder interface{}, source string, outcome NotificationOutcome, lag time.Duration) {
+	if statsRecorder, ok := recorder.(InformerStatsRecorder); ok {
+		statsRecorder.RecordNotification(source, outcome, lag)
+	}
+}
+
+// MonitorStats describes how much the monitor ingested during a run.
+type MonitorStats struct {
+	// Sources counts the conditions recorded, keyed by the type of their locator.
+	Sources map[string]*SourceStats `json:"sources"`
+	// Informers counts the notifications handled by the informers feeding the monitor, keyed by resource.
+	Informers map[string]*InformerStats `json:"informers"`
+	// OpenIntervals is the number of intervals started that have not ended yet.
+	OpenIntervals int `json:"openIntervals"`
+	// MaxOpenIntervals is the most intervals that were open at the same time.
+	MaxOpenIntervals int `json:"maxOpenIntervals"`
+}
+
+// SourceStats counts the conditions recorded for one type of locator.
+type SourceStats struct {
+	Recorded int64 `json:"recorded"`
+	// PeakPerSecond is the most conditions recorded within a single second.
+	PeakPerSecond int64 `json:"peakPerSecond"`
+
+	currentSecond      int64
+	currentSecondCount int64
+}
+
+// InformerStats counts the notifications handled by one informer.
+type InformerStats struct {
+	Handled    int64 `json:"handled"`
+	Duplicates int64 `json:"duplicates"`
+	Dropped    int64 `json:"dropped"`
+	// Lagged is the number of notifications handled more than a minute after the watch delivered them.
+	Lagged        int64   `json:"lagged"`
+	MaxLagSeconds float64 `json:"maxLagSeconds"`
+
+	lastLagCondition time.Time
+}
+
+// monitorStats guards MonitorStats.  It has its own lock so informer handlers do not contend with recording.
+type monitorStats struct {
+	lock  sync.Mutex
+	stats MonitorStats
+}
+
+func (s *monitorStats) countRecorded(now time.Time, conditions ...monitorapi.Condition) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.stats.Sources == nil {
+		s.stats.Sources = map[string]*SourceStats{}
+	}
+	second := now.Unix()
+	for _, condition := range conditions {
+		source := string(monitorapi.LocatorFrom(monitorapi.EventInterval{Condition: condition}).Type)
+		if len(source) == 0 {
+			source = string(monitorapi.LocatorTypeOther)
+		}
+		sourceStats, ok := s.stats.Sources[source]
+		if !ok {
+			sourceStats = &SourceStats{}
+			s.stats.Sources[source] = sourceStats
+		}
+		sourceStats.Recorded++
+		if sourceStats.currentSecond != second {
+			sourceStats.currentSecond = second
+			sourceStats.currentSecondCount = 0
+		}
+		sourceStats.currentSecondCount++
+		if sourceStats.currentSecondCount > sourceStats.PeakPerSecond {
+			sourceStats.PeakPerSecond = sourceStats.currentSecondCount
+		}
+	}
+}
+
+func (s *monitorStats) intervalStarted() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.stats.OpenIntervals++
+	if s.stats.OpenIntervals > s.stats.MaxOpenIntervals {
+		s.stats.MaxOpenIntervals = s.stats.OpenIntervals
+	}
+}
+
+func (s *monitorStats) intervalEnded() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.stats.OpenIntervals--
+}
+
+// countNotification returns true if a condition should be recorded because the informer is falling behind.
+func (s *monitorStats) countNotification(now time.Time, source string, outcome NotificationOutcome, lag time.Duration) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.stats.Informers == nil {
+		s.stats.Informers = map[string]*InformerStats{}
+	}
+	informerStats, ok := s.stats.Informers[source]
+	if !ok {
+		informerStats = &InformerStats{}
+		s.stats.Informers[source] = informerStats
+	}
+	switch outcome {
+	case NotificationDuplicate:
+		informerStats.Duplicates++
+		return false
+	case NotificationDropped:
+		informerStats.Dropped++
+		return false
+	}
+
+	informerStats.Handled++
+	if lag.Seconds() > informerStats.MaxLagSeconds {
+		informerStats.MaxLagSeconds = lag.Seconds()
+	}
+	if lag <= ingestionLagThreshold {
+		return false
+	}
+	informerStats.Lagged++
+	if now.Sub(informerStats.lastLagCondition) < ingestionLagConditionInterval {
+		return false
+	}
+	informerStats.lastLagCondition = now
+	return true
+}
+
+func (s *monitorStats) copy() MonitorStats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ret := MonitorStats{
+		Sources:          map[string]*SourceStats{},
+		Informers:        map[string]*InformerStats{},
+		OpenIntervals:    s.stats.OpenIntervals,
+		MaxOpenIntervals: s.stats.MaxOpenIntervals,
+	}
+	for source, sourceStats := range s.stats.Sources {
+		copied := *sourceStats
+		ret.Sources[source] = &copied
+	}
+	for source, informerStats := range s.stats.Informers {
+		copied := *informerStats
+		ret.Informers[source] = &copied
+	}
+	return ret
+}
+
+// RecordNotification counts a notification from an informer.  When notifications are handled more than a minute
+// after the watch delivered them, an Info condition is recorded (at most once a minute per informer) so the
+// timeline shows when the monitor was behind.
+func (m *Monitor) RecordNotification(source string, outcome NotificationOutcome, lag time.Duration) {
+	if !m.stats.countNotification(time.Now(), source, outcome, lag) {
+		return
+	}
+	m.Record(monitorapi.Condition{
+		Level:   monitorapi.Info,
+		Locator: monitorapi.MonitorLocator(source),
+		Message: monitorapi.ReasonedMessagef(monitorapi.MonitorReasonIngestionLag, "handled a notification %s after the watch delivered it", lag.Round(time.Second)),
+	})
+}
+
+// Stats returns a snapshot of the ingestion statistics.
+func (m *Monitor) Stats() MonitorStats {
+	return m.stats.copy()
+}
+
+var _ InformerStatsRecorder = &Monitor{}
@@ -0,0 +1,70 @@
+package monitor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestMonitorStats(t *testing.T) {
+	m := NewMonitor()
+	m.Record(
+		monitorapi.Condition{Level: monitorapi.Info, Locator: "ns/a pod/b node/c uid/d", Message: "one"},
+		monitorapi.Condition{Level: monitorapi.Info, Locator: "ns/a pod/b node/c uid/d", Message: "two"},
+		monitorapi.Condition{Level: monitorapi.Info, Locator: "node/c", Message: "three"},
+	)
+	first := m.StartInterval(time.Now(), monitorapi.Condition{Level: monitorapi.Error, Locator: "node/c", Message: "first"})
+	m.StartInterval(time.Now(), monitorapi.Condition{Level: monitorapi.Error, Locator: "node/c", Message: "second"})
+	m.EndInterval(first, time.Now())
+	// ending an interval twice does not close another one
+	m.EndInterval(first, time.Now())
+
+	m.RecordNotification("pods", NotificationHandled, 0)
+	m.RecordNotification("pods", NotificationDuplicate, 0)
+	m.RecordNotification("events", NotificationDropped, 0)
+	m.RecordNotification("events", NotificationHandled, 2*time.Minute)
+	// only the first lagging notification in a minute is recorded as a condition
+	m.RecordNotification("events", NotificationHandled, 3*time.Minute)
+
+	stats := m.Stats()
+	if stats.OpenIntervals != 1 || stats.MaxOpenIntervals != 2 {
+		t.Errorf("unexpected open intervals %d, max %d", stats.OpenIntervals, stats.MaxOpenIntervals)
+	}
+	if stats.Sources[string(monitorapi.LocatorTypePod)] == nil || stats.Sources[string(monitorapi.LocatorTypePod)].Recorded != 2 {
+		t.Errorf("unexpected pod stats %#v", stats.Sources[string(monitorapi.LocatorTypePod)])
+	}
+	// one node condition and the two that started intervals
+	if stats.Sources[string(monitorapi.LocatorTypeNode)] == nil || stats.Sources[string(monitorapi.LocatorTypeNode)].Recorded != 3 || stats.Sources[string(monitorapi.LocatorTypeNode)].PeakPerSecond < 1 {
+		t.Errorf("unexpected node stats %#v", stats.Sources[string(monitorapi.LocatorTypeNode)])
+	}
+	if stats.Sources[string(monitorapi.LocatorTypeMonitor)] == nil || stats.Sources[string(monitorapi.LocatorTypeMonitor)].Recorded != 1 {
+		t.Errorf("unexpected monitor stats %#v", stats.Sources[string(monitorapi.LocatorTypeMonitor)])
+	}
+
+	wantInformers := map[string]*InformerStats{
+		"pods":   {Handled: 1, Duplicates: 1},
+		"events": {Handled: 2, Dropped: 1, Lagged: 2, MaxLagSeconds: 180},
+	}
+	for source, informerStats := range stats.Informers {
+		informerStats.lastLagCondition = time.Time{}
+		stats.Informers[source] = informerStats
+	}
+	if !reflect.DeepEqual(stats.Informers, wantInformers) {
+		t.Errorf("unexpected informer stats %#v", stats.Informers)
+	}
+
+	lagConditions := m.Intervals(time.Time{}, time.Time{}).Filter(func(eventInterval monitorapi.EventInterval) bool {
+		return eventInterval.Locator == monitorapi.MonitorLocator("events")
+	})
+	if len(lagConditions) != 1 {
+		t.Errorf("expected one lag condition, got %v", lagConditions)
+	}
+
+	// the snapshot is not changed by later recording
+	m.RecordNotification("pods", NotificationHandled, 0)
+	if stats.Informers["pods"].Handled != 1 {
+		t.Errorf("snapshot changed after recording")
+	}
+}
@@ -15,7 +15,18 @@ import (
 )
 
 func WriteEventsForJobRun(artifactDir string, _ monitorapi.ResourcesMap, events monitorapi.Intervals, timeSuffix string) error {
-	return monitorserialization.EventsToFile(filepath.Join(artifactDir, fmt.Sprintf("e2e-events%s.json", timeSuffix)), events)
+	return WriteEventsWithMetadataForJobRun(artifactDir, monitorserialization.RunMetadata{}, events, timeSuffix)
+}
+
+// WriteEventsWithMetadataForJobRun is WriteEventsForJobRun, recording metadata about the run in the artifact.
+func WriteEventsWithMetadataForJobRun(artifactDir string, metadata monitorserialization.RunMetadata, events monitorapi.Intervals, timeSuffix string) error {
+	return monitorserialization.EventsWithMetadataToFile(filepath.Join(artifactDir, fmt.Sprintf("e2e-events%s.json", timeSuffix)), events, metadata)
+}
+
+// WriteCompactEventsForJobRun writes the same intervals as WriteEventsForJobRun in the compact format, which is much
+// faster to load and query for long runs.  See monitorserialization.EventsToCompact.
+func WriteCompactEventsForJobRun(artifactDir string, _ monitorapi.ResourcesMap, events monitorapi.Intervals, timeSuffix string) error {
+	return monitorserialization.EventsToCompactFile(filepath.Join(artifactDir, fmt.Sprintf("e2e-events%s.jsonl.gz", timeSuffix)), events)
 }
 
 func WriteTrackedResourcesForJobRun(artifactDir string, recordedResources monitorapi.ResourcesMap, _ monitorapi.Intervals, timeSuffix string) error {
@@ -32,6 +43,15 @@ func WriteTrackedResourcesForJobRun(artifactDir string, recordedResources monito
 	return utilerrors.NewAggregate(errors)
 }
 
+// WriteMonitorStatsForJobRun writes how much the monitor ingested, see MonitorStats.
+func WriteMonitorStatsForJobRun(artifactDir string, stats MonitorStats, timeSuffix string) error {
+	jsonContent, err := json.MarshalIndent(stats, "", "    ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(artifactDir, fmt.Sprintf("monitor-stats%s.json", timeSuffix)), jsonContent, 0644)
+}
+
 func WriteBackendDisruptionForJobRun(artifactDir string, _ monitorapi.ResourcesMap, events monitorapi.Intervals, timeSuffix string) error {
 	backendDisruption := computeDisruptionData(events)
 	return writeDisruptionData(filepath.Join(artifactDir, fmt.Sprintf("backend-disruption%s.json", timeSuffix)), backendDisruption)
@@ -81,7 +101,7 @@ func computeDisruptionData(eventIntervals monitorapi.Intervals) *BackendDisrupti
 	}
 
 	for _, locator := range allBackendLocators.List() {
-		locatorParts := monitorapi.LocatorParts(locator)
+		locatorParts := monitorapi.ParseLocator(locator)
 		disruptionBackend := monitorapi.DisruptionFrom(locatorParts)
 		connectionType := monitorapi.DisruptionConnectionTypeFrom(locatorParts)
 		aggregatedDisruptionName := strings.ToLower(fmt.Sprintf("%s-%s-connections", disruptionBackend, connectionType))
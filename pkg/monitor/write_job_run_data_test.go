@@ -1,12 +1,16 @@
 package monitor
 
 import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -229,3 +233,94 @@ func TestComputeDisruptionData(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteCompactEventsForJobRun(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 500, time.UTC)
+	events := monitorapi.Intervals{
+		{Condition: monitorapi.EnsureStructured(monitorapi.Condition{Level: monitorapi.Error, Locator: "node/a", Message: "still open"}), From: start},
+	}
+	// enough pods for more than one block
+	for i := 0; i < 2500; i++ {
+		events = append(events, monitorapi.EventInterval{
+			Condition: monitorapi.EnsureStructured(monitorapi.Condition{Level: monitorapi.Info, Locator: fmt.Sprintf("ns/a pod/b-%d uid/c", i), Message: "reason/Scheduled node/a"}),
+			From:      start.Add(time.Duration(i) * time.Second),
+			To:        start.Add(time.Duration(i)*time.Second + time.Millisecond),
+		})
+	}
+
+	dir := t.TempDir()
+	if err := WriteCompactEventsForJobRun(dir, nil, events, "_suffix"); err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "e2e-events_suffix.jsonl.gz")
+	got, err := monitorserialization.EventsFromFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, events, got)
+
+	got, err = monitorserialization.EventsFromCompactFile(filename, monitorserialization.CompactQuery{
+		From: start.Add(1500 * time.Second),
+		To:   start.Add(1501 * time.Second),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the open interval overlaps every range
+	assert.Equal(t, monitorapi.Intervals{events[0], events[1501], events[1502]}, got)
+
+	got, err = monitorserialization.EventsFromCompactFile(filename, monitorserialization.CompactQuery{
+		LocatorTypes: []monitorapi.LocatorType{monitorapi.LocatorTypeNode},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, monitorapi.Intervals{events[0]}, got)
+}
+
+func TestWriteEventsWithMetadataForJobRun(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := monitorapi.Intervals{
+		{Condition: monitorapi.EnsureStructured(monitorapi.Condition{Level: monitorapi.Info, Locator: "node/a", Message: "reason/Ready"}), From: start, To: start},
+	}
+	metadata := monitorserialization.RunMetadata{
+		StartTime:             &metav1.Time{Time: start},
+		EndTime:               &metav1.Time{Time: start.Add(time.Hour)},
+		OpenShiftTestsVersion: "v4.11.0",
+	}
+
+	dir := t.TempDir()
+	if err := WriteEventsWithMetadataForJobRun(dir, metadata, events, "_suffix"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "e2e-events_suffix.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := monitorserialization.EventIntervalListFromJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, monitorserialization.EventIntervalListAPIVersion, list.APIVersion)
+	assert.True(t, list.Metadata.StartTime.Equal(metadata.StartTime) && list.Metadata.EndTime.Equal(metadata.EndTime), "unexpected metadata %#v", list.Metadata)
+	assert.Equal(t, metadata.OpenShiftTestsVersion, list.Metadata.OpenShiftTestsVersion)
+	got, err := monitorserialization.EventsFromJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, events[0].Condition, got[0].Condition)
+	}
+
+	// lists written before they were versioned are converted
+	legacy, err := monitorserialization.EventIntervalListFromJSON([]byte(`{"items":[{"level":"Info","locator":"node/a","message":"reason/Ready","annotations":{"reason":"Ready"},"from":"2022-01-01T00:00:00Z","to":"2022-01-01T00:00:00Z"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, monitorserialization.EventIntervalListAPIVersion, legacy.APIVersion)
+	assert.Equal(t, list.Items, legacy.Items)
+
+	if _, err := monitorserialization.EventIntervalListFromJSON([]byte(`{"apiVersion":"monitor.openshift.io/v99","kind":"EventIntervalList","items":[]}`)); err == nil {
+		t.Fatal("expected an error for an unknown apiVersion")
+	}
+}
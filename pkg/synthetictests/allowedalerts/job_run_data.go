@@ -127,8 +127,7 @@ func getAlertLevelFromEvent(event monitorapi.EventInterval) AlertLevel {
 func computeAlertData(events monitorapi.Intervals) *AlertList {
 	alertEvents := events.Filter(
 		func(eventInterval monitorapi.EventInterval) bool {
-			locator := monitorapi.LocatorParts(eventInterval.Locator)
-			alertName := monitorapi.AlertFrom(locator)
+			alertName := monitorapi.AlertFrom(monitorapi.LocatorFrom(eventInterval))
 			if len(alertName) == 0 {
 				return false
 			}
@@ -145,7 +144,7 @@ func computeAlertData(events monitorapi.Intervals) *AlertList {
 
 	alertMap := map[AlertKey]*Alert{}
 	for _, alertInterval := range alertEvents {
-		alertLocator := monitorapi.LocatorParts(alertInterval.Locator)
+		alertLocator := monitorapi.LocatorFrom(alertInterval)
 		alertKey := AlertKey{
 			Name:      monitorapi.AlertFrom(alertLocator),
 			Namespace: monitorapi.NamespaceFrom(alertLocator),
@@ -76,6 +76,9 @@ func IsWatchdogAlert(eventInterval monitorapi.EventInterval) bool {
 }
 
 func isSNOUpgradeTest(ctx context.Context, restConfig *rest.Config) (bool, error) {
+	if restConfig == nil {
+		return false, nil
+	}
 	configClient, err := configclient.NewForConfig(restConfig)
 	if err != nil {
 		return false, fmt.Errorf("Failed to establish API server connection")
@@ -27,7 +27,7 @@ func testServerAvailability(
 	testName := fmt.Sprintf("[%s] %s should be available throughout the test", owner, locator)
 
 	// Lookup allowed disruption based on historical data:
-	locatorParts := monitorapi.LocatorParts(locator)
+	locatorParts := monitorapi.ParseLocator(locator)
 	disruptionName := monitorapi.DisruptionFrom(locatorParts)
 	connType := monitorapi.DisruptionConnectionTypeFrom(locatorParts)
 	backendName := fmt.Sprintf("%s-%s-connections", disruptionName, connType)
@@ -114,7 +114,7 @@ func testAllAPIBackendsForDisruption(events monitorapi.Intervals, jobRunDuration
 	disruptLocators := sets.String{}
 	allDisruptionEventsIntervals := events.Filter(monitorapi.IsDisruptionEvent)
 	for _, eventInterval := range allDisruptionEventsIntervals {
-		backend := monitorapi.DisruptionFrom(monitorapi.LocatorParts(eventInterval.Locator))
+		backend := monitorapi.DisruptionFrom(monitorapi.LocatorFrom(eventInterval))
 		if strings.HasSuffix(backend, "-api") {
 			disruptLocators.Insert(eventInterval.Locator)
 		}
@@ -132,7 +132,7 @@ func testAllIngressBackendsForDisruption(events monitorapi.Intervals, jobRunDura
 	disruptLocators := sets.String{}
 	allDisruptionEventsIntervals := events.Filter(monitorapi.IsDisruptionEvent)
 	for _, eventInterval := range allDisruptionEventsIntervals {
-		backend := monitorapi.DisruptionFrom(monitorapi.LocatorParts(eventInterval.Locator))
+		backend := monitorapi.DisruptionFrom(monitorapi.LocatorFrom(eventInterval))
 		if strings.HasPrefix(backend, "ingress-") {
 			disruptLocators.Insert(eventInterval.Locator)
 		}
@@ -151,7 +151,7 @@ func testExternalBackendsForDisruption(events monitorapi.Intervals, jobRunDurati
 	disruptLocators := sets.String{}
 	allDisruptionEventsIntervals := events.Filter(monitorapi.IsDisruptionEvent)
 	for _, eventInterval := range allDisruptionEventsIntervals {
-		backend := monitorapi.DisruptionFrom(monitorapi.LocatorParts(eventInterval.Locator))
+		backend := monitorapi.DisruptionFrom(monitorapi.LocatorFrom(eventInterval))
 		if backend == externalservice.LivenessProbeBackend {
 			disruptLocators.Insert(eventInterval.Locator)
 		}
@@ -172,7 +172,7 @@ func testMultipleSingleSecondDisruptions(events monitorapi.Intervals) []*junitap
 	allServers := sets.String{}
 	allDisruptionEventsIntervals := events.Filter(monitorapi.IsDisruptionEvent)
 	for _, eventInterval := range allDisruptionEventsIntervals {
-		backend := monitorapi.DisruptionFrom(monitorapi.LocatorParts(eventInterval.Locator))
+		backend := monitorapi.DisruptionFrom(monitorapi.LocatorFrom(eventInterval))
 		switch {
 		case strings.HasPrefix(backend, "ingress-"):
 			allServers.Insert(eventInterval.Locator)
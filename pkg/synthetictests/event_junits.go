@@ -78,6 +78,7 @@ func SystemUpgradeEventInvariants(events monitorapi.Intervals, duration time.Dur
 	tests = append(tests, testPodSandboxCreation(events, kubeClientConfig)...)
 	tests = append(tests, testOvnNodeReadinessProbe(events, kubeClientConfig)...)
 	tests = append(tests, testNodeUpgradeTransitions(events, kubeClientConfig)...)
+	tests = append(tests, testNodeRebootsDuringPoolRollout(events)...)
 	tests = append(tests, testUpgradeOperatorStateTransitions(events)...)
 	tests = append(tests, testDuplicatedEventForUpgrade(events, kubeClientConfig, testSuite)...)
 	tests = append(tests, testStaticPodLifecycleFailure(events, kubeClientConfig, testSuite)...)
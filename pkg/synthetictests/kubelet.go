@@ -48,6 +48,57 @@ func testKubeletToAPIServerGracefulTermination(events monitorapi.Intervals) []*j
 	return tests
 }
 
+// testNodeRebootsDuringPoolRollout checks that the machine config operator only reboots a node while the upgrade
+// test sees the pool of one of its roles rolling out.  Runs that recorded no pool phases are not checked.
+func testNodeRebootsDuringPoolRollout(events monitorapi.Intervals) []*junitapi.JUnitTestCase {
+	const testName = "[sig-mco] nodes should only reboot while their machine config pool rolls out"
+
+	poolPhases := events.Filter(func(eventInterval monitorapi.EventInterval) bool {
+		phase, ok := monitorapi.UpgradePhaseFromLocator(eventInterval.Locator)
+		return ok && strings.HasPrefix(phase, monitorapi.UpgradePoolPhase(""))
+	})
+	if len(poolPhases) == 0 {
+		return nil
+	}
+
+	inPoolPhase := map[string]monitorapi.EventIntervalMatchesFunc{}
+	var failures []string
+	for _, event := range events {
+		if _, ok := monitorapi.NodeFromLocator(event.Locator); !ok {
+			continue
+		}
+		if monitorapi.AnnotationFrom(event, monitorapi.AnnotationReason) != "NodeUpdate" || monitorapi.AnnotationFrom(event, monitorapi.AnnotationPhase) != "Reboot" {
+			continue
+		}
+		duringRollout := false
+		for _, role := range strings.Split(monitorapi.GetNodeRoles(event), ",") {
+			matches, ok := inPoolPhase[role]
+			if !ok {
+				matches = monitorapi.InUpgradePhase(poolPhases, monitorapi.UpgradePoolPhase(role))
+				inPoolPhase[role] = matches
+			}
+			duringRollout = duringRollout || matches(event)
+		}
+		if !duringRollout {
+			failures = append(failures, event.String())
+		}
+	}
+
+	success := &junitapi.JUnitTestCase{Name: testName}
+	if len(failures) == 0 {
+		return []*junitapi.JUnitTestCase{success}
+	}
+	failure := &junitapi.JUnitTestCase{
+		Name:      testName,
+		SystemOut: strings.Join(failures, "\n"),
+		FailureOutput: &junitapi.FailureOutput{
+			Output: fmt.Sprintf("%d nodes rebooted outside of the rollout of their machine config pool:\n\n%s", len(failures), strings.Join(failures, "\n")),
+		},
+	}
+	// new test, mark as flake until it is known to be reliable
+	return []*junitapi.JUnitTestCase{failure, success}
+}
+
 func testHttpConnectionLost(events monitorapi.Intervals) []*junitapi.JUnitTestCase {
 	const testName = "[sig-node] kubelet logs do not contain http client connection lost errors"
 	success := &junitapi.JUnitTestCase{Name: testName}
@@ -200,7 +251,7 @@ func testDeleteGracePeriodZero(events monitorapi.Intervals) []*junitapi.JUnitTes
 		if !strings.Contains(event.Locator, "ns/openshift-") {
 			continue
 		}
-		if strings.Contains(event.Message, "mirrored/true") {
+		if monitorapi.AnnotationFrom(event, monitorapi.AnnotationMirrored) == "true" {
 			continue
 		}
 		if strings.Contains(event.Message, "node/ ") {
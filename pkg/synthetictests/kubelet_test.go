@@ -0,0 +1,60 @@
+package synthetictests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func Test_testNodeRebootsDuringPoolRollout(t *testing.T) {
+	start := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+	reboot := func(node, roles string, from, to int) monitorapi.EventInterval {
+		return monitorapi.EventInterval{
+			Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: monitorapi.NodeLocator(node), Message: "reason/NodeUpdate phase/Reboot roles/" + roles + " rebooted and kubelet started"},
+			From:      at(from),
+			To:        at(to),
+		}
+	}
+	workerPhase := monitorapi.EventInterval{
+		Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: monitorapi.UpgradePhaseLocator(monitorapi.UpgradePoolPhase("worker"))},
+		From:      at(30),
+		To:        at(60),
+	}
+
+	tests := []struct {
+		name   string
+		events monitorapi.Intervals
+		want   int
+	}{
+		{
+			name:   "no pool phases",
+			events: monitorapi.Intervals{reboot("master-0", "master", 10, 12)},
+			want:   0,
+		},
+		{
+			name:   "during the rollout of a pool of the node",
+			events: monitorapi.Intervals{workerPhase, reboot("worker-0", "worker", 40, 42), reboot("infra-0", "infra,worker", 50, 52)},
+			want:   1,
+		},
+		{
+			name:   "outside the rollout",
+			events: monitorapi.Intervals{workerPhase, reboot("master-0", "master", 40, 42)},
+			want:   2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := testNodeRebootsDuringPoolRollout(tt.events)
+			if len(got) != tt.want {
+				t.Fatalf("expected %d test cases, got %d", tt.want, len(got))
+			}
+			if tt.want == 2 && got[0].FailureOutput == nil {
+				t.Errorf("expected a failure followed by a pass, got %#v", got)
+			}
+		})
+	}
+}
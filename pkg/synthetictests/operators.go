@@ -41,7 +41,7 @@ func testOperatorStateTransitions(events monitorapi.Intervals, conditionTypes []
 
 	knownOperators := allOperators(events)
 	eventsByOperator := getEventsByOperator(events)
-	e2eEventIntervals := monitor.E2ETestEventIntervals(events)
+	e2eEventIntervals := monitorapi.NewIntervalTree(monitor.E2ETestEventIntervals(events))
 	for _, condition := range conditionTypes {
 		for _, operatorName := range knownOperators.List() {
 			bzComponent := platformidentification.GetBugzillaComponentForOperator(operatorName)
@@ -286,7 +286,7 @@ func getEventsByOperator(events monitorapi.Intervals) map[string]monitorapi.Inte
 	return eventsByClusterOperator
 }
 
-func testOperatorState(interestingCondition configv1.ClusterStatusConditionType, eventIntervals monitorapi.Intervals, e2eEventIntervals monitorapi.Intervals) []string {
+func testOperatorState(interestingCondition configv1.ClusterStatusConditionType, eventIntervals monitorapi.Intervals, e2eEventIntervals *monitorapi.IntervalTree) []string {
 	failures := []string{}
 
 	for _, eventInterval := range eventIntervals {
@@ -301,7 +301,7 @@ func testOperatorState(interestingCondition configv1.ClusterStatusConditionType,
 		// if there was any switch, it was wrong/unexpected at some point
 		failures = append(failures, fmt.Sprintf("%v", eventInterval))
 
-		overlappingE2EIntervals := monitor.FindOverlap(e2eEventIntervals, eventInterval.From, eventInterval.From)
+		overlappingE2EIntervals := e2eEventIntervals.Overlapping(eventInterval.From, eventInterval.From)
 		concurrentE2E := []string{}
 		for _, overlap := range overlappingE2EIntervals {
 			if overlap.Level == monitorapi.Info {
@@ -42,6 +42,9 @@ func CloneJobType(in JobType) JobType {
 
 // GetJobType returns information that can be used to identify a job
 func GetJobType(ctx context.Context, clientConfig *rest.Config) (*JobType, error) {
+	if clientConfig == nil {
+		return nil, errors.New("no cluster configuration to identify the job type from")
+	}
 	configClient, err := configclient.NewForConfig(clientConfig)
 	if err != nil {
 		return nil, err
@@ -48,6 +48,16 @@ func testStaticPodLifecycleFailure(events monitorapi.Intervals, kubeClientConfig
 	const testName = `[sig-node] static pods should start after being created`
 	failures := []string{}
 
+	// this test reads events directly from the cluster, so it cannot run against recorded events alone
+	if kubeClientConfig == nil {
+		return []*junitapi.JUnitTestCase{
+			{
+				Name:        testName,
+				SkipMessage: &junitapi.SkipMessage{Message: "no cluster to read events from"},
+			},
+		}
+	}
+
 	kubeClient, err := kubernetes.NewForConfig(kubeClientConfig)
 	if err != nil {
 		return []*junitapi.JUnitTestCase{
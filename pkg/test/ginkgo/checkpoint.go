@@ -0,0 +1,177 @@
+package ginkgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// checkpointFilename is written to the --junit-dir of a run.
+const checkpointFilename = "openshift-tests-checkpoint.jsonl"
+
+// checkpointEntry is a test that finished, one JSON object per line of the checkpoint.
+type checkpointEntry struct {
+	Name   string    `json:"name"`
+	State  TestState `json:"state"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Output []byte    `json:"output,omitempty"`
+}
+
+// checkpoint appends every test that finishes to a file, so a run that is interrupted can be resumed without running
+// those tests again.  The results read back from an earlier run are handed out, by test name, to the tests the queue
+// is about to run.  A nil checkpoint records nothing and resumes nothing.
+type checkpoint struct {
+	lock sync.Mutex
+	file *os.File
+	// completed are the results of the earlier run that have not been handed out yet, by test name, in the order
+	// they finished.  A test run several times with --count has an entry per run.
+	completed map[string][]checkpointEntry
+}
+
+// openCheckpoint starts a new checkpoint at filename, or when resuming, reads the tests that finished in the earlier
+// run from it and adds to it, so the checkpoint keeps covering every finished test if the resumed run is interrupted
+// too.  A line cut short by the earlier run being killed is ignored.
+func openCheckpoint(filename string, resume bool) (*checkpoint, error) {
+	c := &checkpoint{
+		completed: map[string][]checkpointEntry{},
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	cutShort := false
+	if resume {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		var err error
+		cutShort, err = c.read(filename)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("unable to read checkpoint: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(filename, flags, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if cutShort {
+		if _, err := file.Write([]byte("\n")); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	c.file = file
+	return c, nil
+}
+
+// read loads the tests that finished from filename.  It returns whether the file ends in a line that was cut short,
+// which has to be terminated before more tests are added.
+func (c *checkpoint) read(filename string) (bool, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return false, err
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		entry := checkpointEntry{}
+		if err := json.Unmarshal(line, &entry); err != nil || len(entry.Name) == 0 {
+			continue
+		}
+		switch entry.State {
+		case TestSucceeded, TestFailed, TestFailedTimeout, TestFlaked, TestSkipped:
+		default:
+			continue
+		}
+		c.completed[entry.Name] = append(c.completed[entry.Name], entry)
+	}
+	return len(data) > 0 && data[len(data)-1] != '\n', nil
+}
+
+// Len returns the number of results of the earlier run that have not been handed out.
+func (c *checkpoint) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	count := 0
+	for _, entries := range c.completed {
+		count += len(entries)
+	}
+	return count
+}
+
+// Record appends a test that finished to the checkpoint.
+func (c *checkpoint) Record(result *testRunResult) error {
+	if c == nil {
+		return nil
+	}
+	data, err := json.Marshal(checkpointEntry{
+		Name:   result.name,
+		State:  result.testState,
+		Start:  result.start,
+		End:    result.end,
+		Output: result.testOutputBytes,
+	})
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, err := c.file.Write(data); err != nil {
+		return err
+	}
+	return c.file.Sync()
+}
+
+// Resume fills in the results of the tests that finished in the earlier run and returns the tests that still have to
+// run.  The resumed tests are recorded in the monitor with the times they ran, so they show up in the timelines.
+func (c *checkpoint) Resume(tests []*testCase, monitorRecorder monitor.Recorder) []*testCase {
+	if c == nil {
+		return tests
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	remaining := make([]*testCase, 0, len(tests))
+	for _, test := range tests {
+		entries := c.completed[test.name]
+		if len(entries) == 0 {
+			remaining = append(remaining, test)
+			continue
+		}
+		entry := entries[0]
+		c.completed[test.name] = entries[1:]
+
+		result := &testRunResult{
+			name:            entry.Name,
+			start:           entry.Start,
+			end:             entry.End,
+			testState:       entry.State,
+			testOutputBytes: entry.Output,
+		}
+		mutateTestCaseWithResults(test, &testRunResultHandle{testRunResult: result})
+		if monitorRecorder != nil {
+			monitorRecorder.RecordAt(entry.Start, monitorapi.Condition{
+				Level:   monitorapi.Info,
+				Locator: monitorapi.E2ETestLocator(test.name),
+				Message: "started",
+			})
+			monitorRecorder.RecordAt(entry.End, testResultCondition(result))
+		}
+	}
+	return remaining
+}
+
+func (c *checkpoint) Close() error {
+	if c == nil {
+		return nil
+	}
+	return c.file.Close()
+}
@@ -0,0 +1,106 @@
+package ginkgo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), checkpointFilename)
+	start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	first, err := openCheckpoint(filename, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := []*testRunResult{
+		{name: "passed", testState: TestSucceeded, start: start, end: start.Add(time.Minute), testOutputBytes: []byte("ok")},
+		{name: "failed", testState: TestFailed, start: start, end: start.Add(2 * time.Minute), testOutputBytes: []byte("fail [boom]")},
+		{name: "repeated", testState: TestSucceeded, start: start, end: start.Add(time.Second)},
+		{name: "unknown", testState: TestUnknown, start: start, end: start.Add(time.Second)},
+	}
+	for _, result := range results {
+		if err := first.Record(result); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// the run was killed while writing a line
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write([]byte(`{"name":"cut short","sta`))
+	file.Close()
+
+	resumed, err := openCheckpoint(filename, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Len() != 3 {
+		t.Errorf("expected 3 finished tests, got %d", resumed.Len())
+	}
+
+	tests := []*testCase{
+		{name: "passed"},
+		{name: "failed"},
+		{name: "repeated"},
+		{name: "repeated"},
+		{name: "unknown"},
+		{name: "cut short"},
+		{name: "not run"},
+	}
+	remaining := resumed.Resume(tests, nil)
+	if got, want := testNames(remaining), []string{"repeated", "unknown", "cut short", "not run"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v to remain, got %v", want, got)
+	}
+	if !tests[0].success || tests[0].duration != time.Minute || string(tests[0].testOutputBytes) != "ok" {
+		t.Errorf("unexpected resumed result %#v", tests[0])
+	}
+	if !tests[1].failed || tests[1].duration != 2*time.Minute {
+		t.Errorf("unexpected resumed result %#v", tests[1])
+	}
+	if tests[3].success {
+		t.Errorf("expected the second run of a test to run again")
+	}
+
+	// the resumed run adds to the checkpoint after the line that was cut short
+	if err := resumed.Record(&testRunResult{name: "not run", testState: TestSkipped, start: start, end: start}); err != nil {
+		t.Fatal(err)
+	}
+	resumed.Close()
+	again, err := openCheckpoint(filename, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer again.Close()
+	if again.Len() != 4 {
+		t.Errorf("expected 4 finished tests, got %d", again.Len())
+	}
+
+	// a run that does not resume starts a new checkpoint
+	fresh, err := openCheckpoint(filename, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh.Close()
+	if info, err := os.Stat(filename); err != nil || info.Size() != 0 {
+		t.Errorf("expected an empty checkpoint, got %v %v", info, err)
+	}
+}
+
+func TestCheckpointNil(t *testing.T) {
+	var c *checkpoint
+	tests := []*testCase{{name: "test"}}
+	if remaining := c.Resume(tests, nil); !reflect.DeepEqual(remaining, tests) {
+		t.Errorf("expected every test to remain, got %v", remaining)
+	}
+	if err := c.Record(&testRunResult{name: "test", testState: TestSucceeded}); err != nil {
+		t.Error(err)
+	}
+}
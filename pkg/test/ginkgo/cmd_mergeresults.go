@@ -0,0 +1,194 @@
+package ginkgo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openshift/origin/pkg/riskanalysis"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// shardProperty is the JUnit suite property that records the shard of a run.
+const shardProperty = "Shard"
+
+// MergeResultsOptions combines the results of the shards of a suite, each in its own directory, into one suite
+// result.
+type MergeResultsOptions struct {
+	// JUnitDir is where the merged results are written.  It must not be one of the shard directories.
+	JUnitDir string
+
+	Out, ErrOut io.Writer
+}
+
+func (opt *MergeResultsOptions) Run(dirs []string) error {
+	if len(opt.JUnitDir) == 0 {
+		return fmt.Errorf("--junit-dir is required")
+	}
+	if len(dirs) == 0 {
+		return fmt.Errorf("at least one directory of results is required")
+	}
+	for _, dir := range dirs {
+		if filepath.Clean(dir) == filepath.Clean(opt.JUnitDir) {
+			return fmt.Errorf("--junit-dir must not be one of the directories that are merged")
+		}
+	}
+	if err := os.MkdirAll(opt.JUnitDir, 0755); err != nil {
+		return err
+	}
+
+	suites := []*junitapi.JUnitTestSuite{}
+	for _, dir := range dirs {
+		files, err := filepath.Glob(filepath.Join(dir, "junit_e2e_*.xml"))
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("%s contains no junit_e2e_*.xml results", dir)
+		}
+		for _, file := range files {
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			suite := &junitapi.JUnitTestSuite{}
+			if err := xml.Unmarshal(data, suite); err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+			suites = append(suites, suite)
+		}
+	}
+
+	merged, err := mergeJUnitSuites(suites)
+	if err != nil {
+		return err
+	}
+	timeSuffix := "_merged"
+	if err := writeJUnitReport(merged, "junit_e2e", timeSuffix, opt.JUnitDir, opt.ErrOut); err != nil {
+		return fmt.Errorf("unable to write merged JUnit xml results: %w", err)
+	}
+	// the summaries of the shards would count the tests reported by every shard once per shard
+	if err := riskanalysis.WriteJobRunTestFailureSummary(opt.JUnitDir, timeSuffix, merged); err != nil {
+		return fmt.Errorf("unable to write the merged job run failures summary: %w", err)
+	}
+
+	fmt.Fprintf(opt.Out, "%d tests, %d failures, %d skipped from %d results\n", merged.NumTests, merged.NumFailed, merged.NumSkipped, len(suites))
+	return nil
+}
+
+// mergeJUnitSuites combines the results of the shards of a suite.  The shards ran at the same time, so the suite takes
+// as long as the longest shard.  Every shard runs its own tests, so a test reported by several shards is one that every
+// shard synthesizes, like the invariants checked by its monitor.  Those are kept once, from the shard with the worst
+// result, so that a pass in one shard and a failure in another is not read as a flake.
+func mergeJUnitSuites(suites []*junitapi.JUnitTestSuite) (*junitapi.JUnitTestSuite, error) {
+	if len(suites) == 0 {
+		return nil, fmt.Errorf("no results to merge")
+	}
+	merged := &junitapi.JUnitTestSuite{
+		Name: suites[0].Name,
+	}
+	reporter := worstReporters(suites)
+	shards := []string{}
+	for i, suite := range suites {
+		if suite.Name != merged.Name {
+			return nil, fmt.Errorf("cannot merge the results of different suites, %q != %q", merged.Name, suite.Name)
+		}
+		merged.NumTests += suite.NumTests
+		merged.NumSkipped += suite.NumSkipped
+		merged.NumFailed += suite.NumFailed
+		if suite.Duration > merged.Duration {
+			merged.Duration = suite.Duration
+		}
+		for _, property := range suite.Properties {
+			if property.Name == shardProperty {
+				shards = append(shards, property.Value)
+				continue
+			}
+			if !hasProperty(merged.Properties, property.Name) {
+				merged.Properties = append(merged.Properties, &junitapi.TestSuiteProperty{Name: property.Name, Value: property.Value})
+			}
+		}
+		for _, testCase := range suite.TestCases {
+			if reporter[testCase.Name] != i {
+				merged.NumTests--
+				switch {
+				case testCase.SkipMessage != nil:
+					merged.NumSkipped--
+				case testCase.FailureOutput != nil:
+					merged.NumFailed--
+				}
+				continue
+			}
+			merged.TestCases = append(merged.TestCases, testCase)
+		}
+		merged.Children = append(merged.Children, suite.Children...)
+	}
+	if len(shards) > 0 {
+		merged.Properties = append(merged.Properties, &junitapi.TestSuiteProperty{Name: shardProperty, Value: strings.Join(shards, ",")})
+	}
+	return merged, nil
+}
+
+// caseResult orders the results of a test in one shard from best to worst.
+type caseResult int
+
+const (
+	caseSkipped caseResult = iota
+	casePassed
+	caseFlaked
+	caseFailed
+)
+
+// worstReporters returns the index of the suite whose test cases are kept for every test: the suite with the worst
+// result, or the first of them.
+func worstReporters(suites []*junitapi.JUnitTestSuite) map[string]int {
+	reporter := map[string]int{}
+	worst := map[string]caseResult{}
+	for i, suite := range suites {
+		results := map[string]caseResult{}
+		for _, testCase := range suite.TestCases {
+			result, seen := results[testCase.Name]
+			switch {
+			case testCase.SkipMessage != nil:
+				if !seen {
+					result = caseSkipped
+				}
+			case testCase.FailureOutput != nil:
+				if seen && result == casePassed {
+					result = caseFlaked
+				} else if !seen || result == caseSkipped {
+					result = caseFailed
+				}
+			default:
+				if seen && result == caseFailed {
+					result = caseFlaked
+				} else if !seen || result == caseSkipped {
+					result = casePassed
+				}
+			}
+			results[testCase.Name] = result
+		}
+		for _, testCase := range suite.TestCases {
+			name := testCase.Name
+			if current, ok := reporter[name]; ok && (current == i || worst[name] >= results[name]) {
+				continue
+			}
+			reporter[name] = i
+			worst[name] = results[name]
+		}
+	}
+	return reporter
+}
+
+func hasProperty(properties []*junitapi.TestSuiteProperty, name string) bool {
+	for _, property := range properties {
+		if property.Name == name {
+			return true
+		}
+	}
+	return false
+}
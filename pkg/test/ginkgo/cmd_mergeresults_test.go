@@ -0,0 +1,195 @@
+package ginkgo
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/origin/pkg/riskanalysis"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+func Test_mergeJUnitSuites(t *testing.T) {
+	shard := func(index string, duration float64, cases ...*junitapi.JUnitTestCase) *junitapi.JUnitTestSuite {
+		suite := &junitapi.JUnitTestSuite{
+			Name:     "openshift-tests",
+			Duration: duration,
+			Properties: []*junitapi.TestSuiteProperty{
+				{Name: "TestVersion", Value: "v4.14.0"},
+				{Name: shardProperty, Value: index},
+			},
+			TestCases: cases,
+		}
+		for _, testCase := range cases {
+			suite.NumTests++
+			if testCase.FailureOutput != nil {
+				suite.NumFailed++
+			}
+			if testCase.SkipMessage != nil {
+				suite.NumSkipped++
+			}
+		}
+		return suite
+	}
+	passed := &junitapi.JUnitTestCase{Name: "passed"}
+	failed := &junitapi.JUnitTestCase{Name: "failed", FailureOutput: &junitapi.FailureOutput{Output: "fail"}}
+	skipped := &junitapi.JUnitTestCase{Name: "skipped", SkipMessage: &junitapi.SkipMessage{Message: "skip"}}
+
+	merged, err := mergeJUnitSuites([]*junitapi.JUnitTestSuite{
+		shard("1/2", 100, passed, failed),
+		shard("2/2", 300, skipped),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &junitapi.JUnitTestSuite{
+		Name:       "openshift-tests",
+		NumTests:   3,
+		NumFailed:  1,
+		NumSkipped: 1,
+		Duration:   300,
+		Properties: []*junitapi.TestSuiteProperty{
+			{Name: "TestVersion", Value: "v4.14.0"},
+			{Name: shardProperty, Value: "1/2,2/2"},
+		},
+		TestCases: []*junitapi.JUnitTestCase{passed, failed, skipped},
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("unexpected merged suite %#v", merged)
+	}
+
+	other := shard("2/2", 1)
+	other.Name = "other"
+	if _, err := mergeJUnitSuites([]*junitapi.JUnitTestSuite{shard("1/2", 1), other}); err == nil {
+		t.Errorf("expected an error merging different suites")
+	}
+}
+
+func Test_mergeJUnitSuitesKeepsSyntheticTestsOnce(t *testing.T) {
+	suite := func(cases ...*junitapi.JUnitTestCase) *junitapi.JUnitTestSuite {
+		suite := &junitapi.JUnitTestSuite{Name: "openshift-tests", TestCases: cases}
+		for _, testCase := range cases {
+			suite.NumTests++
+			if testCase.FailureOutput != nil {
+				suite.NumFailed++
+			}
+		}
+		return suite
+	}
+	pass := func(name string) *junitapi.JUnitTestCase {
+		return &junitapi.JUnitTestCase{Name: name}
+	}
+	fail := func(name, output string) *junitapi.JUnitTestCase {
+		return &junitapi.JUnitTestCase{Name: name, FailureOutput: &junitapi.FailureOutput{Output: output}}
+	}
+	shard1 := suite(pass("test 1"), pass("invariant"), pass("flaky invariant"), fail("flaky invariant", "shard 1"), fail("failed invariant", "shard 1"))
+	shard2 := suite(pass("test 2"), fail("invariant", "shard 2"), fail("flaky invariant", "shard 2"), pass("flaky invariant"), fail("failed invariant", "shard 2"))
+
+	merged, err := mergeJUnitSuites([]*junitapi.JUnitTestSuite{shard1, shard2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the failure of a shard is kept over a pass, a flake of the first shard over one of the second
+	want := []*junitapi.JUnitTestCase{
+		shard1.TestCases[0], shard1.TestCases[2], shard1.TestCases[3], shard1.TestCases[4],
+		shard2.TestCases[0], shard2.TestCases[1],
+	}
+	if !reflect.DeepEqual(merged.TestCases, want) {
+		t.Errorf("unexpected test cases %#v", merged.TestCases)
+	}
+	if merged.NumTests != 6 || merged.NumFailed != 3 {
+		t.Errorf("expected 6 tests and 3 failures, got %d and %d", merged.NumTests, merged.NumFailed)
+	}
+}
+
+func TestMergeResultsReadsProperties(t *testing.T) {
+	dir := t.TempDir()
+	for i, index := range []string{"1/2", "2/2"} {
+		suite := &junitapi.JUnitTestSuite{
+			Name:       "openshift-tests",
+			NumTests:   1,
+			Properties: []*junitapi.TestSuiteProperty{{Name: "TestVersion", Value: "v4.14.0"}, {Name: shardProperty, Value: index}},
+			TestCases:  []*junitapi.JUnitTestCase{{Name: index}},
+		}
+		shardDir := filepath.Join(dir, fmt.Sprintf("shard-%d", i+1))
+		if err := os.MkdirAll(shardDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeJUnitReport(suite, "junit_e2e", "_20230101-100000", shardDir, ioutil.Discard); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := filepath.Join(dir, "merged")
+	opt := &MergeResultsOptions{JUnitDir: out, Out: ioutil.Discard, ErrOut: ioutil.Discard}
+	if err := opt.Run([]string{filepath.Join(dir, "shard-1"), filepath.Join(dir, "shard-2")}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(out, "junit_e2e__merged.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := &junitapi.JUnitTestSuite{}
+	if err := xml.Unmarshal(data, merged); err != nil {
+		t.Fatal(err)
+	}
+	want := []*junitapi.TestSuiteProperty{
+		{XMLName: xml.Name{Local: "property"}, Name: "TestVersion", Value: "v4.14.0"},
+		{XMLName: xml.Name{Local: "property"}, Name: shardProperty, Value: "1/2,2/2"},
+	}
+	if !reflect.DeepEqual(merged.Properties, want) || merged.NumTests != 2 {
+		t.Errorf("unexpected merged suite %#v", merged)
+	}
+}
+
+func TestMergeResultsSummarizesFailuresOnce(t *testing.T) {
+	dir := t.TempDir()
+	dirs := []string{}
+	for i := 1; i <= 2; i++ {
+		suite := &junitapi.JUnitTestSuite{
+			Name:     "openshift-tests",
+			NumTests: 2,
+			TestCases: []*junitapi.JUnitTestCase{
+				{Name: fmt.Sprintf("test %d", i)},
+				{Name: "failed invariant", FailureOutput: &junitapi.FailureOutput{Output: fmt.Sprintf("shard %d", i)}},
+			},
+		}
+		shardDir := filepath.Join(dir, fmt.Sprintf("shard-%d", i))
+		if err := os.MkdirAll(shardDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeJUnitReport(suite, "junit_e2e", "_20230101-100000", shardDir, ioutil.Discard); err != nil {
+			t.Fatal(err)
+		}
+		if err := riskanalysis.WriteJobRunTestFailureSummary(shardDir, "_20230101-100000", suite); err != nil {
+			t.Fatal(err)
+		}
+		dirs = append(dirs, shardDir)
+	}
+
+	out := filepath.Join(dir, "merged")
+	opt := &MergeResultsOptions{JUnitDir: out, Out: ioutil.Discard, ErrOut: ioutil.Discard}
+	if err := opt.Run(dirs); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(out, "test-failures-summary_merged.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := &riskanalysis.ProwJobRun{}
+	if err := json.Unmarshal(data, summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.TestCount != 3 {
+		t.Errorf("expected 3 tests, got %d", summary.TestCount)
+	}
+	if len(summary.Tests) != 1 || summary.Tests[0].Test.Name != "failed invariant" {
+		t.Errorf("expected the invariant to fail once, got %#v", summary.Tests)
+	}
+}
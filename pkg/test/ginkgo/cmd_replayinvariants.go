@@ -0,0 +1,101 @@
+package ginkgo
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/intervalcreation"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+)
+
+// ReplayInvariantsOptions reruns the synthetic tests of a suite against the intervals and resources recorded by an
+// earlier run, without a cluster.  Synthetic tests that need to talk to the cluster are skipped or fail to identify
+// the platform.
+type ReplayInvariantsOptions struct {
+	// EventsFilename is an e2e-events or e2e-events-journal file.
+	EventsFilename string
+	// ResourceFilenames are resource-*.zip files.
+	ResourceFilenames []string
+	// RecalculateIntervals adds the calculated intervals, which are missing from journals and from
+	// e2e-events files written before they were calculated.
+	RecalculateIntervals bool
+
+	JUnitDir string
+
+	Out, ErrOut io.Writer
+}
+
+func (opt *ReplayInvariantsOptions) Run(suite *TestSuite) error {
+	if len(opt.EventsFilename) == 0 {
+		return fmt.Errorf("an events file is required")
+	}
+	if len(opt.JUnitDir) > 0 {
+		if err := os.MkdirAll(opt.JUnitDir, 0755); err != nil {
+			return err
+		}
+	}
+
+	events, err := monitorserialization.EventsFromFile(opt.EventsFilename)
+	if err != nil {
+		return fmt.Errorf("unable to read events: %w", err)
+	}
+	recordedResources, err := monitorserialization.ResourcesMapFromFiles(opt.ResourceFilenames...)
+	if err != nil {
+		return fmt.Errorf("unable to read resources: %w", err)
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("%s contains no events", opt.EventsFilename)
+	}
+
+	from, to := replayTimeRange(events)
+	if opt.RecalculateIntervals {
+		events = intervalcreation.InsertCalculatedIntervals(events, recordedResources, from, to)
+	}
+	duration := to.Sub(from)
+
+	syntheticTestResults, _, _ := createSyntheticTestsFromMonitor(events, duration)
+	syntheticTestResults = append(syntheticTestResults, JUnitsForAllEvents{suite.SyntheticEventTests}.JUnitsForEvents(events, duration, nil, suite.Name, &recordedResources)...)
+
+	failingSyntheticTestNames, flakySyntheticTestNames := failingAndFlakySyntheticTests(syntheticTestResults)
+	if failingSyntheticTestNames.Len() > 0 {
+		fmt.Fprintf(opt.Out, "Failing invariants:\n\n%s\n\n", strings.Join(failingSyntheticTestNames.List(), "\n"))
+	}
+	if flakySyntheticTestNames.Len() > 0 {
+		fmt.Fprintf(opt.Out, "Flaky invariants:\n\n%s\n\n", strings.Join(flakySyntheticTestNames.List(), "\n"))
+	}
+
+	if len(opt.JUnitDir) > 0 {
+		timeSuffix := fmt.Sprintf("_%s", from.UTC().Format("20060102-150405"))
+		finalSuiteResults := generateJUnitTestSuiteResults(suite.Name, duration, nil, syntheticTestResults...)
+		if err := writeJUnitReport(finalSuiteResults, "junit_replay", timeSuffix, opt.JUnitDir, opt.ErrOut); err != nil {
+			return fmt.Errorf("unable to write replay JUnit xml results: %w", err)
+		}
+	}
+
+	if failingSyntheticTestNames.Len() > 0 {
+		return fmt.Errorf("failed because %d invariants were violated (%s)", failingSyntheticTestNames.Len(), duration)
+	}
+	fmt.Fprintf(opt.Out, "%d invariants evaluated, %d flaky (%s)\n", len(syntheticTestResults), flakySyntheticTestNames.Len(), duration)
+	return nil
+}
+
+// replayTimeRange returns the span covered by the recorded events.
+func replayTimeRange(events monitorapi.Intervals) (time.Time, time.Time) {
+	var from, to time.Time
+	for _, event := range events {
+		if from.IsZero() || event.From.Before(from) {
+			from = event.From
+		}
+		if event.From.After(to) {
+			to = event.From
+		}
+		if event.To.After(to) {
+			to = event.To
+		}
+	}
+	return from, to
+}
@@ -0,0 +1,84 @@
+package ginkgo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+type replayedInvariant struct {
+	kubeClientConfig *rest.Config
+	events           monitorapi.Intervals
+}
+
+func (r *replayedInvariant) JUnitsForEvents(events monitorapi.Intervals, _ time.Duration, kubeClientConfig *rest.Config, _ string, _ *monitorapi.ResourcesMap) []*junitapi.JUnitTestCase {
+	r.kubeClientConfig = kubeClientConfig
+	r.events = events
+	return []*junitapi.JUnitTestCase{
+		{
+			Name:          "[sig-arch] replayed invariant",
+			FailureOutput: &junitapi.FailureOutput{Output: "always fails"},
+		},
+	}
+}
+
+func TestReplayInvariantsOptions_Run(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replay-invariants")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := monitorapi.Intervals{
+		{
+			Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "node/a", Message: "reason/NodeReady"},
+			From:      start,
+			To:        start.Add(time.Minute),
+		},
+	}
+	eventsFilename := filepath.Join(dir, "e2e-events.json")
+	if err := monitorserialization.EventsToFile(eventsFilename, events); err != nil {
+		t.Fatal(err)
+	}
+
+	invariant := &replayedInvariant{kubeClientConfig: &rest.Config{}}
+	out := &bytes.Buffer{}
+	opt := &ReplayInvariantsOptions{
+		EventsFilename: eventsFilename,
+		JUnitDir:       filepath.Join(dir, "junit"),
+		Out:            out,
+		ErrOut:         out,
+	}
+	err = opt.Run(&TestSuite{Name: "replay", SyntheticEventTests: invariant})
+	if err == nil {
+		t.Fatalf("expected the failing invariant to fail the replay")
+	}
+	if invariant.kubeClientConfig != nil {
+		t.Errorf("expected a nil rest.Config")
+	}
+	if len(invariant.events) != 1 || invariant.events[0].Locator != "node/a" {
+		t.Errorf("unexpected events: %v", invariant.events.Strings())
+	}
+	if !strings.Contains(out.String(), "[sig-arch] replayed invariant") {
+		t.Errorf("expected the failing invariant in the output:\n%s", out.String())
+	}
+
+	junitFiles, err := filepath.Glob(filepath.Join(dir, "junit", "junit_replay_*.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(junitFiles) != 1 {
+		t.Errorf("expected one junit file, got %v", junitFiles)
+	}
+}
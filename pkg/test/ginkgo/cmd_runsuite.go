@@ -41,6 +41,15 @@ type Options struct {
 	JUnitDir    string
 	TestFile    string
 	OutFile     string
+	// TestDurationsFile is the JUnit XML of an earlier run, or JSON that maps test names to seconds, used to start
+	// the longest tests first.  See LoadTestDurations.
+	TestDurationsFile string
+	// Resume skips the tests that finished in an earlier run into the same JUnitDir, and reports their results as if
+	// they ran in this one.  Every run checkpoints the tests that finish to JUnitDir.  The monitor only records this
+	// run, so the intervals and invariants do not cover the earlier run, whose event journal is left in JUnitDir.
+	Resume bool
+	// Shard, as index/count, runs only the tests of one of count processes that split the suite.  See Shard.
+	Shard string
 
 	// Regex allows a selection of a subset of tests
 	Regex string
@@ -53,6 +62,11 @@ type Options struct {
 
 	MonitorEventsOptions *MonitorEventsOptions
 
+	// OTLPTraceFile is where the run is written as an OTLP/JSON trace, if set.
+	OTLPTraceFile string
+	// OTLPEndpoint is the OTLP/HTTP collector the run is sent to as a trace, if set.
+	OTLPEndpoint string
+
 	IncludeSuccessOutput bool
 
 	CommandEnv []string
@@ -192,6 +206,24 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 		return fmt.Errorf("suite %q does not contain any tests", suite.Name)
 	}
 
+	var durations TestDurations
+	if len(opt.TestDurationsFile) > 0 {
+		durations, err = LoadTestDurations(opt.TestDurationsFile)
+		if err != nil {
+			return fmt.Errorf("could not read --test-durations: %v", err)
+		}
+	}
+
+	var shard Shard
+	if len(opt.Shard) > 0 {
+		shard, err = ParseShard(opt.Shard)
+		if err != nil {
+			return err
+		}
+		tests = shard.Select(tests, durations)
+		fmt.Fprintf(opt.ErrOut, "Running %d tests of shard %s\n", len(tests), shard)
+	}
+
 	count := opt.Count
 	if count == 0 {
 		count = suite.Count
@@ -213,7 +245,7 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 	testRunnerContext := newCommandContext(opt.AsEnv(), timeout)
 
 	if opt.PrintCommands {
-		newParallelTestQueue(testRunnerContext).OutputCommands(ctx, tests, opt.Out)
+		newParallelTestQueue(testRunnerContext, nil).OutputCommands(ctx, tests, opt.Out)
 		return nil
 	}
 	if opt.DryRun {
@@ -234,6 +266,20 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 		}
 	}
 
+	var testCheckpoint *checkpoint
+	if len(opt.JUnitDir) > 0 {
+		testCheckpoint, err = openCheckpoint(filepath.Join(opt.JUnitDir, checkpointFilename), opt.Resume)
+		if err != nil {
+			return err
+		}
+		defer testCheckpoint.Close()
+		if opt.Resume {
+			fmt.Fprintf(opt.Out, "Resuming a run that finished %d tests\n", testCheckpoint.Len())
+		}
+	} else if opt.Resume {
+		return fmt.Errorf("--resume requires the --junit-dir of the run to resume")
+	}
+
 	parallelism := opt.Parallelism
 	if parallelism == 0 {
 		parallelism = suite.Parallelism
@@ -264,10 +310,11 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 	if err != nil {
 		return err
 	}
-	monitorEventRecorder, err := opt.MonitorEventsOptions.Start(ctx, restConfig)
+	monitorEventRecorder, err := opt.MonitorEventsOptions.StartWithDefaults(ctx, restConfig, opt.JUnitDir, suite.RecorderSelectors, suite.TrackedResources)
 	if err != nil {
 		return err
 	}
+	fmt.Fprintf(opt.Out, "Started monitors: %s\n", strings.Join(opt.MonitorEventsOptions.GetStartedRecorders(), ", "))
 
 	pc, err := SetupNewPodCollector(ctx)
 	if err != nil {
@@ -285,6 +332,9 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 	testOutputLock := &sync.Mutex{}
 	testOutputConfig := newTestOutputConfig(testOutputLock, opt.Out, monitorEventRecorder, includeSuccess)
 
+	// tests of a concurrency class share a semaphore in the queue, instead of a bucket of their own
+	assignConcurrencyClasses(tests, defaultConcurrencyClasses, parallelism)
+
 	early, notEarly := splitTests(tests, func(t *testCase) bool {
 		return strings.Contains(t.name, "[Early]")
 	})
@@ -293,34 +343,16 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 		return strings.Contains(t.name, "[Late]")
 	})
 
-	kubeTests, openshiftTests := splitTests(primaryTests, func(t *testCase) bool {
-		return strings.Contains(t.name, "[Suite:k8s]")
-	})
-
-	storageTests, kubeTests := splitTests(kubeTests, func(t *testCase) bool {
-		return strings.Contains(t.name, "[sig-storage]")
-	})
-
-	mustGatherTests, openshiftTests := splitTests(openshiftTests, func(t *testCase) bool {
-		return strings.Contains(t.name, "[sig-cli] oc adm must-gather")
-	})
-
-	// If user specifies a count, duplicate the kube and openshift tests that many times.
+	// If user specifies a count, duplicate the primary tests that many times.
 	expectedTestCount := len(early) + len(late)
 	if count != -1 {
-		originalKube := kubeTests
-		originalOpenshift := openshiftTests
-		originalStorage := storageTests
-		originalMustGather := mustGatherTests
+		originalPrimary := primaryTests
 
 		for i := 1; i < count; i++ {
-			kubeTests = append(kubeTests, copyTests(originalKube)...)
-			openshiftTests = append(openshiftTests, copyTests(originalOpenshift)...)
-			storageTests = append(storageTests, copyTests(originalStorage)...)
-			mustGatherTests = append(mustGatherTests, copyTests(originalMustGather)...)
+			primaryTests = append(primaryTests, copyTests(originalPrimary)...)
 		}
 	}
-	expectedTestCount += len(openshiftTests) + len(kubeTests) + len(storageTests) + len(mustGatherTests)
+	expectedTestCount += len(primaryTests)
 
 	abortFn := neverAbort
 	testCtx := ctx
@@ -330,8 +362,14 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 
 	tests = nil
 
+	retryPolicy := suite.retryPolicy()
+	q := newParallelTestQueue(testRunnerContext, durations)
+	q.checkpoint = testCheckpoint
+	if retryPolicy.Immediate && retryPolicy.enabled() {
+		q.retries = newImmediateRetries(retryPolicy)
+	}
+
 	// run our Early tests
-	q := newParallelTestQueue(testRunnerContext)
 	q.Execute(testCtx, early, parallelism, testOutputConfig, abortFn)
 	tests = append(tests, early...)
 
@@ -341,23 +379,11 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 	// Run kube, storage, openshift, and must-gather tests. If user specified a count of -1,
 	// we loop indefinitely.
 	for i := 0; (i < 1 || count == -1) && testCtx.Err() == nil; i++ {
-		kubeTestsCopy := copyTests(kubeTests)
-		q.Execute(testCtx, kubeTestsCopy, parallelism, testOutputConfig, abortFn)
-		tests = append(tests, kubeTestsCopy...)
-
-		// I thought about randomizing the order of the kube, storage, and openshift tests, but storage dominates our e2e runs, so it doesn't help much.
-		storageTestsCopy := copyTests(storageTests)
-		q.Execute(testCtx, storageTestsCopy, max(1, parallelism/2), testOutputConfig, abortFn) // storage tests only run at half the parallelism, so we can avoid cloud provider quota problems.
-		tests = append(tests, storageTestsCopy...)
-
-		openshiftTestsCopy := copyTests(openshiftTests)
-		q.Execute(testCtx, openshiftTestsCopy, parallelism, testOutputConfig, abortFn)
-		tests = append(tests, openshiftTestsCopy...)
-
-		// run the must-gather tests after parallel tests to reduce resource contention
-		mustGatherTestsCopy := copyTests(mustGatherTests)
-		q.Execute(testCtx, mustGatherTestsCopy, parallelism, testOutputConfig, abortFn)
-		tests = append(tests, mustGatherTestsCopy...)
+		// concurrency classes keep contended tests, like storage, from using the full parallelism, and must-gather tests
+		// from running alongside any other test.
+		primaryTestsCopy := copyTests(primaryTests)
+		q.Execute(testCtx, primaryTestsCopy, parallelism, testOutputConfig, abortFn)
+		tests = append(tests, primaryTestsCopy...)
 	}
 
 	// TODO: will move to the monitor
@@ -396,69 +422,28 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 	pass, fail, skip, failing := summarizeTests(tests)
 
 	// attempt to retry failures to do flake detection
-	if fail > 0 && fail <= suite.MaximumAllowedFlakes {
-		var retries []*testCase
-
-		// Make a copy of the all failing tests (subject to the max allowed flakes) so we can have
-		// a list of tests to retry.
-		for _, test := range failing {
-			retry := test.Retry()
-			retries = append(retries, retry)
-			if len(retries) > suite.MaximumAllowedFlakes {
-				break
-			}
-		}
-
-		fmt.Fprintf(opt.Out, "Retry count: %d\n", len(retries))
-
-		// Run the tests in the retries list.
-		q := newParallelTestQueue(testRunnerContext)
-		q.Execute(testCtx, retries, parallelism, testOutputConfig, abortFn)
+	attempts := q.retries.Attempts()
+	if retried := retryPolicy.retriedAtEnd(failing, attempts); len(retried) > 0 && retryPolicy.allowsRetries(fail) {
+		fmt.Fprintf(opt.Out, "Retry count: %d\n", len(retried))
 
+		// Run the retries of the failing tests until the policy decides each of them.
+		retryQueue := q.forRetries()
+		attempts = attempts.add(retryAtEnd(testCtx, retryPolicy, retried, func(ctx context.Context, retries []*testCase) {
+			retryQueue.Execute(ctx, retries, parallelism, testOutputConfig, abortFn)
+		}))
+	}
+	if len(attempts) > 0 {
 		var flaky, skipped []string
-		var repeatFailures []*testCase
-		for _, test := range retries {
-			if test.success {
-				flaky = append(flaky, test.name)
-			} else if test.skipped {
-				skipped = append(skipped, test.name)
-			} else {
-				repeatFailures = append(repeatFailures, test)
-			}
-		}
-
-		// Add the list of retries into the list of all tests.
-		for _, retry := range retries {
-			if retry.flake {
-				// Retry tests that flaked are omitted so that the original test is counted as a failure.
-				fmt.Fprintf(opt.Out, "Ignoring retry that returned a flake, original failure is authoritative for test: %s\n", retry.name)
-				continue
-			}
-			tests = append(tests, retry)
-		}
+		tests, failing, flaky, skipped = applyRetries(opt.Out, retryPolicy, tests, failing, attempts)
 		if len(flaky) > 0 {
-			failing = repeatFailures
 			sort.Strings(flaky)
 			fmt.Fprintf(opt.Out, "Flaky tests:\n\n%s\n\n", strings.Join(flaky, "\n"))
 		}
 		if len(skipped) > 0 {
 			// If a retry test got skipped, it means we very likely failed a precondition in the first failure, so
-			// we need to remove the failure case.
-			var withoutPreconditionFailures []*testCase
-		testLoop:
-			for _, t := range tests {
-				for _, st := range skipped {
-					if t.name == st && t.failed {
-						continue testLoop
-					}
-					withoutPreconditionFailures = append(withoutPreconditionFailures, t)
-				}
-			}
-			tests = withoutPreconditionFailures
-			failing = repeatFailures
+			// the failure case was removed.
 			sort.Strings(skipped)
 			fmt.Fprintf(opt.Out, "Skipped tests that failed a precondition:\n\n%s\n\n", strings.Join(skipped, "\n"))
-
 		}
 	}
 
@@ -468,6 +453,9 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 
 	timeSuffix := fmt.Sprintf("_%s", opt.MonitorEventsOptions.GetStartTime().
 		UTC().Format("20060102-150405"))
+	if shard.Count > 0 {
+		timeSuffix += shard.fileSuffix()
+	}
 
 	if err := opt.MonitorEventsOptions.End(ctx, restConfig, opt.JUnitDir); err != nil {
 		return err
@@ -486,23 +474,7 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 		syntheticTestResults = append(syntheticTestResults, testCases...)
 
 		if len(syntheticTestResults) > 0 {
-			// mark any failures by name
-			failingSyntheticTestNames, flakySyntheticTestNames := sets.NewString(), sets.NewString()
-			for _, test := range syntheticTestResults {
-				if test.FailureOutput != nil {
-					failingSyntheticTestNames.Insert(test.Name)
-				}
-			}
-			// if a test has both a pass and a failure, flag it
-			// as a flake
-			for _, test := range syntheticTestResults {
-				if test.FailureOutput == nil {
-					if failingSyntheticTestNames.Has(test.Name) {
-						flakySyntheticTestNames.Insert(test.Name)
-					}
-				}
-			}
-			failingSyntheticTestNames = failingSyntheticTestNames.Difference(flakySyntheticTestNames)
+			failingSyntheticTestNames, flakySyntheticTestNames := failingAndFlakySyntheticTests(syntheticTestResults)
 			if failingSyntheticTestNames.Len() > 0 {
 				fmt.Fprintf(buf, "Failing invariants:\n\n%s\n\n", strings.Join(failingSyntheticTestNames.List(), "\n"))
 				syntheticFailure = true
@@ -521,6 +493,20 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 		}
 	}
 
+	if len(opt.OTLPTraceFile) > 0 || len(opt.OTLPEndpoint) > 0 {
+		trace, err := newRunTrace(junitSuiteName, *opt.MonitorEventsOptions.GetStartTime(), time.Now(), tests, opt.MonitorEventsOptions.GetEvents())
+		if err == nil {
+			err = exportRunTrace(trace, opt.OTLPTraceFile, opt.OTLPEndpoint)
+		}
+		if err != nil {
+			fmt.Fprintf(opt.ErrOut, "error: Unable to export the run as a trace: %v\n", err)
+		}
+	}
+
+	if summary := q.WallTimeSummary(); len(summary) > 0 {
+		fmt.Fprintf(opt.Out, "%s\n", summary)
+	}
+
 	// report the outcome of the test
 	if len(failing) > 0 {
 		names := sets.NewString(testNames(failing)...).List()
@@ -529,6 +515,9 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 
 	if len(opt.JUnitDir) > 0 {
 		finalSuiteResults := generateJUnitTestSuiteResults(junitSuiteName, duration, tests, syntheticTestResults...)
+		if shard.Count > 0 {
+			finalSuiteResults.Properties = append(finalSuiteResults.Properties, &junitapi.TestSuiteProperty{Name: shardProperty, Value: shard.String()})
+		}
 		if err := writeJUnitReport(finalSuiteResults, "junit_e2e", timeSuffix, opt.JUnitDir, opt.ErrOut); err != nil {
 			fmt.Fprintf(opt.Out, "error: Unable to write e2e JUnit xml results: %v", err)
 		}
@@ -539,7 +528,7 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 	}
 
 	if fail > 0 {
-		if len(failing) > 0 || suite.MaximumAllowedFlakes == 0 {
+		if len(failing) > 0 || !retryPolicy.enabled() {
 			return fmt.Errorf("%d fail, %d pass, %d skip (%s)", fail, pass, skip, duration)
 		}
 		fmt.Fprintf(opt.Out, "%d flakes detected, suite allows passing with only flakes\n\n", fail)
@@ -79,7 +79,7 @@ func (opt *TestOptions) Run(args []string) error {
 		return err
 	}
 	if opt.EnableMonitor {
-		_, err = opt.MonitorEventsOptions.Start(ctx, restConfig)
+		_, err = opt.MonitorEventsOptions.Start(ctx, restConfig, "")
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,226 @@
+package ginkgo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	concurrencyRe = regexp.MustCompile(`\[Concurrency:([^=\]]+)=([^\]]*)\]`)
+	exclusiveRe   = regexp.MustCompile(`\[Exclusive:([^\]]+)\]`)
+)
+
+// parseConcurrency reads the concurrency classes and the exclusion of a test from its name.  [Concurrency:<class>=<limit>]
+// puts the test in a class of which at most limit tests run at once, so tests that share a cloud quota can be kept
+// from exhausting it.  [Exclusive:<name>] keeps the test from running alongside any other test with the same exclusion,
+// for tests that use a singleton of the cluster.  A [Concurrency:] whose limit is not a positive number is ignored
+// with a warning, so one badly tagged test does not keep the suite from being listed.
+func parseConcurrency(name string) (map[string]int, string, error) {
+	var classes map[string]int
+	for _, match := range concurrencyRe.FindAllStringSubmatch(name, -1) {
+		limit, err := strconv.Atoi(match[2])
+		if err != nil || limit < 1 {
+			fmt.Fprintf(os.Stderr, "warning: ignoring %s of %q: the limit must be a positive number\n", match[0], name)
+			continue
+		}
+		if classes == nil {
+			classes = map[string]int{}
+		}
+		if curr, ok := classes[match[1]]; !ok || limit < curr {
+			classes[match[1]] = limit
+		}
+	}
+
+	var exclusion string
+	for _, match := range exclusiveRe.FindAllStringSubmatch(name, -1) {
+		if len(exclusion) > 0 && exclusion != match[1] {
+			return nil, "", fmt.Errorf("a test may only have one [Exclusive:], found %q and %q", exclusion, match[1])
+		}
+		exclusion = match[1]
+	}
+	return classes, exclusion, nil
+}
+
+// ConcurrencyClass puts tests in a concurrency class by name, for tests whose names cannot carry
+// [Concurrency:<class>=<limit>] because they are vendored.  A test that is tagged with the class keeps its own limit.
+type ConcurrencyClass struct {
+	Name string
+	// Matches selects the tests of the class.
+	Matches func(name string) bool
+	// Limit returns how many tests of the class may run at once for the parallelism of the run.
+	Limit func(parallelism int) int
+	// RunsAlone keeps every other test from running alongside a test of the class.  Such a test waits for the
+	// running tests to finish, so in practice it runs after the others.
+	RunsAlone bool
+}
+
+// defaultConcurrencyClasses apply to every suite.
+var defaultConcurrencyClasses = []ConcurrencyClass{
+	{
+		// storage tests only run at half the parallelism, so we can avoid cloud provider quota problems.
+		Name:    "storage",
+		Matches: func(name string) bool { return strings.Contains(name, "[sig-storage]") },
+		Limit:   func(parallelism int) int { return max(1, parallelism/2) },
+	},
+	{
+		// must-gather tests gather from the whole cluster, so they run alone, while no other test creates or deletes
+		// what they gather.
+		Name:      "must-gather",
+		Matches:   func(name string) bool { return strings.Contains(name, "[sig-cli] oc adm must-gather") },
+		Limit:     func(int) int { return 1 },
+		RunsAlone: true,
+	},
+}
+
+// assignConcurrencyClasses adds tests to the classes they match.
+func assignConcurrencyClasses(tests []*testCase, classes []ConcurrencyClass, parallelism int) {
+	for _, class := range classes {
+		limit := max(1, class.Limit(parallelism))
+		for _, test := range tests {
+			if !class.Matches(test.name) {
+				continue
+			}
+			if class.RunsAlone {
+				test.runsAlone = true
+			}
+			if _, ok := test.concurrencyClasses[class.Name]; ok {
+				continue
+			}
+			if test.concurrencyClasses == nil {
+				test.concurrencyClasses = map[string]int{}
+			}
+			test.concurrencyClasses[class.Name] = limit
+		}
+	}
+}
+
+// concurrencyLimits returns the number of tests that may hold each semaphore of the test at once.  Exclusions are
+// semaphores of one and live in their own namespace so they never share a semaphore with a class.
+func concurrencyLimits(test *testCase) map[string]int {
+	if len(test.concurrencyClasses) == 0 && len(test.testExclusion) == 0 {
+		return nil
+	}
+	limits := map[string]int{}
+	for class, limit := range test.concurrencyClasses {
+		limits["Concurrency:"+class] = limit
+	}
+	if len(test.testExclusion) > 0 {
+		limits["Exclusive:"+test.testExclusion] = 1
+	}
+	return limits
+}
+
+// pendingTests hands out tests to the workers of execute in order, skipping tests whose concurrency classes or
+// exclusion are full so a worker never sits idle behind them.  The counting semaphore of every class and exclusion is
+// guarded by the lock, since a test must take all of its semaphores at once.  When tests of a class disagree on its
+// limit, the lowest limit wins.  A test that runs alone only starts when no other test is running, and keeps any
+// other test from starting.
+type pendingTests struct {
+	lock    sync.Mutex
+	cond    *sync.Cond
+	tests   []*testCase
+	limits  map[string]int
+	running map[string]int
+
+	runningTests int
+	runningAlone bool
+}
+
+func newPendingTests(tests []*testCase) *pendingTests {
+	p := &pendingTests{
+		tests:   append([]*testCase{}, tests...),
+		limits:  map[string]int{},
+		running: map[string]int{},
+	}
+	p.cond = sync.NewCond(&p.lock)
+	for _, test := range tests {
+		for key, limit := range concurrencyLimits(test) {
+			if curr, ok := p.limits[key]; !ok || limit < curr {
+				p.limits[key] = limit
+			}
+		}
+	}
+	return p
+}
+
+// next returns the first test that can run now, waiting for a running test to finish if none can.  It returns false
+// when no tests remain or the context is finished.
+func (p *pendingTests) next(ctx context.Context) (*testCase, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for {
+		if ctx.Err() != nil || len(p.tests) == 0 {
+			return nil, false
+		}
+		if test, ok := p.take(); ok {
+			return test, true
+		}
+		// no test can run until one of the running tests finishes
+		p.cond.Wait()
+	}
+}
+
+// take removes the first test that can run now and takes its semaphores.  The caller holds the lock, unless the
+// pendingTests is its own, like in predictWallTime.
+func (p *pendingTests) take() (*testCase, bool) {
+	for i, test := range p.tests {
+		if !p.canRun(test) {
+			continue
+		}
+		p.tests = append(p.tests[:i], p.tests[i+1:]...)
+		for key := range concurrencyLimits(test) {
+			p.running[key]++
+		}
+		p.runningTests++
+		p.runningAlone = test.runsAlone
+		return test, true
+	}
+	return nil, false
+}
+
+func (p *pendingTests) canRun(test *testCase) bool {
+	if p.runningAlone || (test.runsAlone && p.runningTests > 0) {
+		return false
+	}
+	for key := range concurrencyLimits(test) {
+		if p.running[key] >= p.limits[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// done releases the semaphores of a test returned by next.
+func (p *pendingTests) done(test *testCase) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.release(test)
+	p.cond.Broadcast()
+}
+
+// release gives back the semaphores of a test returned by take.
+func (p *pendingTests) release(test *testCase) {
+	for key := range concurrencyLimits(test) {
+		p.running[key]--
+	}
+	p.runningTests--
+	if test.runsAlone {
+		p.runningAlone = false
+	}
+}
+
+// wakeOnDone wakes the waiting workers when the context is finished, until stop is closed.
+func (p *pendingTests) wakeOnDone(ctx context.Context, stop <-chan struct{}) {
+	select {
+	case <-ctx.Done():
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		p.cond.Broadcast()
+	case <-stop:
+	}
+}
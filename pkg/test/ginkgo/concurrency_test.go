@@ -0,0 +1,223 @@
+package ginkgo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_parseConcurrency(t *testing.T) {
+	tests := []struct {
+		name          string
+		testName      string
+		wantClasses   map[string]int
+		wantExclusion string
+		wantErr       bool
+	}{
+		{
+			name:     "untagged",
+			testName: "[sig-node] pods should run [Suite:openshift/conformance/parallel]",
+		},
+		{
+			name:        "class",
+			testName:    "[sig-storage] volumes should attach [Concurrency:cloud-disks=4] [Suite:k8s]",
+			wantClasses: map[string]int{"cloud-disks": 4},
+		},
+		{
+			name:        "several classes, lowest limit wins",
+			testName:    "test [Concurrency:cloud-disks=4] [Concurrency:load-balancers=2] [Concurrency:cloud-disks=3]",
+			wantClasses: map[string]int{"cloud-disks": 3, "load-balancers": 2},
+		},
+		{
+			name:          "exclusive",
+			testName:      "[sig-node] drain should evict pods [Exclusive:node-drain]",
+			wantExclusion: "node-drain",
+		},
+		{
+			name:          "class and exclusive",
+			testName:      "test [Exclusive:node-drain] [Concurrency:cloud-disks=1]",
+			wantClasses:   map[string]int{"cloud-disks": 1},
+			wantExclusion: "node-drain",
+		},
+		{
+			name:     "zero limit is ignored",
+			testName: "test [Concurrency:cloud-disks=0]",
+		},
+		{
+			name:        "missing limit is ignored",
+			testName:    "test [Concurrency:cloud-disks=] [Concurrency:load-balancers=2]",
+			wantClasses: map[string]int{"load-balancers": 2},
+		},
+		{
+			name:     "two exclusions",
+			testName: "test [Exclusive:node-drain] [Exclusive:registry]",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			classes, exclusion, err := parseConcurrency(tt.testName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(classes, tt.wantClasses) {
+				t.Errorf("expected classes %v, got %v", tt.wantClasses, classes)
+			}
+			if exclusion != tt.wantExclusion {
+				t.Errorf("expected exclusion %q, got %q", tt.wantExclusion, exclusion)
+			}
+		})
+	}
+}
+
+func Test_assignConcurrencyClasses(t *testing.T) {
+	storage := &testCase{name: "[sig-storage] volumes should attach [Suite:k8s]"}
+	tagged := &testCase{name: "[sig-storage] volumes should resize [Suite:k8s]", concurrencyClasses: map[string]int{"storage": 1}}
+	mustGather := &testCase{name: "[sig-cli] oc adm must-gather runs successfully [Suite:openshift/conformance/parallel]"}
+	other := &testCase{name: "[sig-node] pods should run [Suite:k8s]"}
+
+	assignConcurrencyClasses([]*testCase{storage, tagged, mustGather, other}, defaultConcurrencyClasses, 30)
+
+	if !reflect.DeepEqual(storage.concurrencyClasses, map[string]int{"storage": 15}) {
+		t.Errorf("expected storage class at half the parallelism, got %v", storage.concurrencyClasses)
+	}
+	if !reflect.DeepEqual(tagged.concurrencyClasses, map[string]int{"storage": 1}) {
+		t.Errorf("expected tagged limit to be kept, got %v", tagged.concurrencyClasses)
+	}
+	if !reflect.DeepEqual(mustGather.concurrencyClasses, map[string]int{"must-gather": 1}) {
+		t.Errorf("expected must-gather tests to run one at a time, got %v", mustGather.concurrencyClasses)
+	}
+	if !mustGather.runsAlone || storage.runsAlone || other.runsAlone {
+		t.Errorf("expected only must-gather tests to run alone")
+	}
+	if other.concurrencyClasses != nil {
+		t.Errorf("expected no class, got %v", other.concurrencyClasses)
+	}
+}
+
+// concurrencyTrackingRunner records the most tests of every semaphore that ran at once, and the tests that overlapped a
+// test that runs alone.
+type concurrencyTrackingRunner struct {
+	lock       sync.Mutex
+	running    map[string]int
+	maxRunning map[string]int
+	total      int
+	maxTotal   int
+	testsRun   int
+	alone      bool
+	overlapped []string
+}
+
+func newConcurrencyTrackingRunner() *concurrencyTrackingRunner {
+	return &concurrencyTrackingRunner{
+		running:    map[string]int{},
+		maxRunning: map[string]int{},
+	}
+}
+
+func (r *concurrencyTrackingRunner) RunOneTest(ctx context.Context, test *testCase) {
+	r.lock.Lock()
+	r.total++
+	if r.total > r.maxTotal {
+		r.maxTotal = r.total
+	}
+	if r.alone || (test.runsAlone && r.total > 1) {
+		r.overlapped = append(r.overlapped, test.name)
+	}
+	if test.runsAlone {
+		r.alone = true
+	}
+	for key := range concurrencyLimits(test) {
+		r.running[key]++
+		if r.running[key] > r.maxRunning[key] {
+			r.maxRunning[key] = r.running[key]
+		}
+	}
+	r.lock.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.total--
+	r.testsRun++
+	if test.runsAlone {
+		r.alone = false
+	}
+	for key := range concurrencyLimits(test) {
+		r.running[key]--
+	}
+}
+
+func Test_executeConcurrencyClasses(t *testing.T) {
+	tests := []*testCase{}
+	for i := 0; i < 20; i++ {
+		tests = append(tests, &testCase{name: fmt.Sprintf("disks %d", i), concurrencyClasses: map[string]int{"cloud-disks": 2}})
+	}
+	for i := 0; i < 5; i++ {
+		tests = append(tests, &testCase{name: fmt.Sprintf("drain %d", i), testExclusion: "node-drain"})
+	}
+	for i := 0; i < 20; i++ {
+		tests = append(tests, &testCase{name: fmt.Sprintf("plain %d", i)})
+	}
+
+	runner := newConcurrencyTrackingRunner()
+	execute(context.TODO(), runner, tests, 8)
+
+	if runner.testsRun != len(tests) {
+		t.Errorf("expected %d tests to run, got %d", len(tests), runner.testsRun)
+	}
+	if got := runner.maxRunning["Concurrency:cloud-disks"]; got != 2 {
+		t.Errorf("expected at most 2 cloud-disks tests at once, got %d", got)
+	}
+	if got := runner.maxRunning["Exclusive:node-drain"]; got != 1 {
+		t.Errorf("expected at most 1 node-drain test at once, got %d", got)
+	}
+	// the plain tests queued behind the classes must not wait for them
+	if runner.maxTotal <= 3 {
+		t.Errorf("expected the workers to run other tests while the classes are full, got at most %d at once", runner.maxTotal)
+	}
+}
+
+func Test_executeMustGatherRunsAlone(t *testing.T) {
+	tests := []*testCase{}
+	for i := 0; i < 20; i++ {
+		tests = append(tests, &testCase{name: fmt.Sprintf("[sig-node] plain %d", i)})
+		if i%5 == 0 {
+			tests = append(tests, &testCase{name: fmt.Sprintf("[sig-cli] oc adm must-gather %d", i)})
+		}
+	}
+	assignConcurrencyClasses(tests, defaultConcurrencyClasses, 8)
+
+	runner := newConcurrencyTrackingRunner()
+	execute(context.TODO(), runner, tests, 8)
+
+	if runner.testsRun != len(tests) {
+		t.Errorf("expected %d tests to run, got %d", len(tests), runner.testsRun)
+	}
+	if len(runner.overlapped) > 0 {
+		t.Errorf("expected no test to run alongside must-gather, got %v", runner.overlapped)
+	}
+	if runner.maxTotal <= 1 {
+		t.Errorf("expected the other tests to run in parallel, got at most %d at once", runner.maxTotal)
+	}
+}
+
+func Test_executeConcurrencyClassesCancelled(t *testing.T) {
+	tests := []*testCase{}
+	for i := 0; i < 10; i++ {
+		tests = append(tests, &testCase{name: fmt.Sprintf("drain %d", i), testExclusion: "node-drain"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 12*time.Millisecond)
+	defer cancel()
+	runner := newConcurrencyTrackingRunner()
+	execute(ctx, runner, tests, 4)
+
+	if runner.testsRun == len(tests) {
+		t.Errorf("expected the cancelled context to stop the waiting workers")
+	}
+}
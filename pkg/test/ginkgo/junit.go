@@ -36,9 +36,10 @@ func generateJUnitTestSuiteResults(
 			s.NumTests++
 			s.NumSkipped++
 			s.TestCases = append(s.TestCases, &junitapi.JUnitTestCase{
-				Name:      test.name,
-				SystemOut: string(test.testOutputBytes),
-				Duration:  test.duration.Seconds(),
+				Name:       test.name,
+				SystemOut:  string(test.testOutputBytes),
+				Duration:   test.duration.Seconds(),
+				Properties: attemptProperties(test),
 				SkipMessage: &junitapi.SkipMessage{
 					Message: lastLinesUntil(string(test.testOutputBytes), 100, "skip ["),
 				},
@@ -47,9 +48,10 @@ func generateJUnitTestSuiteResults(
 			s.NumTests++
 			s.NumFailed++
 			s.TestCases = append(s.TestCases, &junitapi.JUnitTestCase{
-				Name:      test.name,
-				SystemOut: string(test.testOutputBytes),
-				Duration:  test.duration.Seconds(),
+				Name:       test.name,
+				SystemOut:  string(test.testOutputBytes),
+				Duration:   test.duration.Seconds(),
+				Properties: attemptProperties(test),
 				FailureOutput: &junitapi.FailureOutput{
 					Output: lastLinesUntil(string(test.testOutputBytes), 100, "fail ["),
 				},
@@ -58,9 +60,10 @@ func generateJUnitTestSuiteResults(
 			s.NumTests++
 			s.NumFailed++
 			s.TestCases = append(s.TestCases, &junitapi.JUnitTestCase{
-				Name:      test.name,
-				SystemOut: string(test.testOutputBytes),
-				Duration:  test.duration.Seconds(),
+				Name:       test.name,
+				SystemOut:  string(test.testOutputBytes),
+				Duration:   test.duration.Seconds(),
+				Properties: attemptProperties(test),
 				FailureOutput: &junitapi.FailureOutput{
 					Output: lastLinesUntil(string(test.testOutputBytes), 100, "flake:"),
 				},
@@ -69,14 +72,16 @@ func generateJUnitTestSuiteResults(
 			// also add the successful junit result:
 			s.NumTests++
 			s.TestCases = append(s.TestCases, &junitapi.JUnitTestCase{
-				Name:     test.name,
-				Duration: test.duration.Seconds(),
+				Name:       test.name,
+				Duration:   test.duration.Seconds(),
+				Properties: attemptProperties(test),
 			})
 		case test.success:
 			s.NumTests++
 			s.TestCases = append(s.TestCases, &junitapi.JUnitTestCase{
-				Name:     test.name,
-				Duration: test.duration.Seconds(),
+				Name:       test.name,
+				Duration:   test.duration.Seconds(),
+				Properties: attemptProperties(test),
 			})
 		}
 	}
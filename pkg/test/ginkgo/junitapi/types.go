@@ -35,8 +35,9 @@ type JUnitTestSuite struct {
 	// Duration is the time taken in seconds to run all tests in the suite
 	Duration float64 `xml:"time,attr"`
 
-	// Properties holds other properties of the test suite as a mapping of name to value
-	Properties []*TestSuiteProperty `xml:"properties,omitempty"`
+	// Properties holds other properties of the test suite as a mapping of name to value.  They are written as
+	// property elements of the suite, and the tag must name them so they are read back.
+	Properties []*TestSuiteProperty `xml:"property,omitempty"`
 
 	// TestCases are the test cases contained in the test suite
 	TestCases []*JUnitTestCase `xml:"testcase"`
@@ -77,6 +78,9 @@ type JUnitTestCase struct {
 
 	// SystemErr is output written to stderr during the execution of this test case
 	SystemErr string `xml:"system-err,omitempty"`
+
+	// Properties holds other properties of the test case, such as which attempt of a retried test it is
+	Properties []*TestSuiteProperty `xml:"property,omitempty"`
 }
 
 // SkipMessage holds a message explaining why a test was skipped
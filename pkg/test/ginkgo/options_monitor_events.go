@@ -2,14 +2,19 @@ package ginkgo
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/fs"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
 
 	"github.com/openshift/origin/pkg/monitor"
@@ -17,7 +22,9 @@ import (
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
 	"github.com/openshift/origin/pkg/synthetictests/allowedalerts"
+	"github.com/openshift/origin/pkg/synthetictests/platformidentification"
 	"github.com/openshift/origin/test/extended/util/disruption/controlplane"
+	"github.com/openshift/origin/test/extended/util/disruption/externalservice"
 	"github.com/openshift/origin/test/extended/util/disruption/frontends"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 )
@@ -34,36 +41,49 @@ func (fn RunDataWriterFunc) WriteRunData(artifactDir string, recordedResources m
 
 type MonitorEventsOptions struct {
 	monitor   *monitor.Monitor
+	journal   *monitorserialization.JournalWriter
 	startTime *time.Time
 	endTime   *time.Time
 	// recordedEvents is written during End
 	recordedEvents monitorapi.Intervals
 	// recordedResource is written during End
 	recordedResources monitorapi.ResourcesMap
+	// runMetadata is written during End
+	runMetadata monitorserialization.RunMetadata
+	// recordedStats is written during End
+	recordedStats monitor.MonitorStats
+	// startedRecorders are the names of the recorders chosen during Start
+	startedRecorders []string
+	// trackedResources are parsed from TrackedResources during Start
+	trackedResources []schema.GroupVersionResource
+	// definitionWriters write the timelines of TimelineDefinitions, they are loaded during Start
+	definitionWriters []RunDataWriter
 
-	Recorders      []monitor.StartEventIntervalRecorderFunc
-	RunDataWriters []RunDataWriter
-	Out            io.Writer
-	ErrOut         io.Writer
+	// Recorders are the monitors that can be started, by name.
+	Recorders *monitor.RecorderRegistry
+	// RecorderSelectors choose which Recorders are started.  See monitor.RecorderRegistry.Select.
+	RecorderSelectors []string
+	// TrackedResources are additional resources, as resource.version.group, whose instances are watched by the
+	// "resources" recorder.  See monitor.NewResourceRecorder.
+	TrackedResources []string
+	// TimelineDefinitions are YAML files, or directories of them, describing more timelines to write for the run.
+	// See intervalcreation.TimelineDefinition.
+	TimelineDefinitions []string
+	RunDataWriters      []RunDataWriter
+	Out                 io.Writer
+	ErrOut              io.Writer
 }
 
 func NewMonitorEventsOptions(out io.Writer, errOut io.Writer) *MonitorEventsOptions {
-	return &MonitorEventsOptions{
-		Recorders: []monitor.StartEventIntervalRecorderFunc{
-			controlplane.StartAllAPIMonitoring,
-			frontends.StartAllIngressMonitoring,
-		},
+	recorders := monitor.NewDefaultRecorderRegistry()
+	recorders.Register("apiserver-disruption", true, controlplane.StartAllAPIMonitoring)
+	recorders.Register("ingress-disruption", true, frontends.StartAllIngressMonitoring)
+	recorders.Register("external-service-disruption", false, externalservice.StartExternalServiceMonitoring)
+
+	o := &MonitorEventsOptions{
+		Recorders: recorders,
 		RunDataWriters: []RunDataWriter{
-			// these produce the various intervals.  Different intervals focused on inspecting different problem spaces.
-			intervalcreation.NewSpyglassEventIntervalRenderer("everything", intervalcreation.BelongsInEverything),
-			intervalcreation.NewSpyglassEventIntervalRenderer("spyglass", intervalcreation.BelongsInSpyglass),
-			// TODO add visualization of individual apiserver containers and their readiness on this page
-			intervalcreation.NewSpyglassEventIntervalRenderer("kube-apiserver", intervalcreation.BelongsInKubeAPIServer),
-			intervalcreation.NewSpyglassEventIntervalRenderer("operators", intervalcreation.BelongsInOperatorRollout),
-			intervalcreation.NewPodEventIntervalRenderer(),
-			intervalcreation.NewIngressServicePodIntervalRenderer(),
-
-			RunDataWriterFunc(monitor.WriteEventsForJobRun),
+			RunDataWriterFunc(monitor.WriteCompactEventsForJobRun),
 			RunDataWriterFunc(monitor.WriteTrackedResourcesForJobRun),
 			RunDataWriterFunc(monitor.WriteBackendDisruptionForJobRun),
 			RunDataWriterFunc(allowedalerts.WriteAlertDataForJobRun),
@@ -71,22 +91,78 @@ func NewMonitorEventsOptions(out io.Writer, errOut io.Writer) *MonitorEventsOpti
 		Out:    out,
 		ErrOut: errOut,
 	}
+	for _, renderer := range intervalcreation.NewDefaultTimelineRenderers() {
+		o.RunDataWriters = append(o.RunDataWriters, renderer)
+	}
+	recorders.Register("resources", true, func(ctx context.Context, recorder monitor.Recorder, clusterConfig *rest.Config) error {
+		return monitor.NewResourceRecorder(o.trackedResources...)(ctx, recorder, clusterConfig)
+	})
+	o.RunDataWriters = append(o.RunDataWriters,
+		RunDataWriterFunc(func(artifactDir string, _ monitorapi.ResourcesMap, events monitorapi.Intervals, timeSuffix string) error {
+			if err := monitor.WriteEventsWithMetadataForJobRun(artifactDir, o.runMetadata, events, timeSuffix); err != nil {
+				return err
+			}
+			return o.removeJournal()
+		}),
+		RunDataWriterFunc(func(artifactDir string, _ monitorapi.ResourcesMap, _ monitorapi.Intervals, timeSuffix string) error {
+			return monitor.WriteMonitorStatsForJobRun(artifactDir, o.recordedStats, timeSuffix)
+		}),
+	)
+	return o
+}
+
+// Start begins monitoring the cluster.  If artifactDir is set, recorded intervals are streamed to an event journal
+// in that directory so they survive the process crashing, until WriteRunDataToArtifactsDir writes the events of the
+// run.  See JournalFilename.
+func (o *MonitorEventsOptions) Start(ctx context.Context, restConfig *rest.Config, artifactDir string) (monitor.Recorder, error) {
+	return o.StartWithDefaults(ctx, restConfig, artifactDir, nil, nil)
 }
 
-func (o *MonitorEventsOptions) Start(ctx context.Context, restConfig *rest.Config) (monitor.Recorder, error) {
+// StartWithDefaults is Start with the recorder selectors and tracked resources of a suite, which apply ahead of the
+// RecorderSelectors and TrackedResources of the options.  The options are left as they are.
+func (o *MonitorEventsOptions) StartWithDefaults(ctx context.Context, restConfig *rest.Config, artifactDir string, recorderSelectors, trackedResources []string) (monitor.Recorder, error) {
 	if o.monitor != nil {
 		return nil, fmt.Errorf("already started")
 	}
+	recorderSelectors = append(append([]string{}, recorderSelectors...), o.RecorderSelectors...)
+	trackedResources = append(append([]string{}, trackedResources...), o.TrackedResources...)
+
+	parsedResources, err := parseTrackedResources(trackedResources)
+	if err != nil {
+		return nil, err
+	}
+	o.trackedResources = parsedResources
+	if len(o.TimelineDefinitions) > 0 {
+		definitions, err := intervalcreation.LoadTimelineDefinitions(o.TimelineDefinitions...)
+		if err != nil {
+			return nil, err
+		}
+		for _, definition := range definitions {
+			o.definitionWriters = append(o.definitionWriters, intervalcreation.NewTimelineDefinitionRenderer(definition))
+		}
+	}
+	names, err := o.Recorders.Select(recorderSelectors)
+	if err != nil {
+		return nil, err
+	}
+	recorders, err := o.Recorders.Recorders(names)
+	if err != nil {
+		return nil, err
+	}
+
 	t := time.Now()
 	o.startTime = &t
+	o.startedRecorders = names
 
-	m, err := monitor.Start(ctx, restConfig,
-		[]monitor.StartEventIntervalRecorderFunc{
-			controlplane.StartAllAPIMonitoring,
-			frontends.StartAllIngressMonitoring,
-		},
-	)
-	if err != nil {
+	m := monitor.NewMonitorWithInterval(time.Second)
+	if len(artifactDir) > 0 {
+		o.journal, err = monitorserialization.NewJournalWriter(filepath.Join(artifactDir, JournalFilename(t)))
+		if err != nil {
+			return nil, err
+		}
+		m = monitor.NewMonitorWithJournal(time.Second, o.journal)
+	}
+	if err := monitor.StartRecorders(ctx, m, restConfig, recorders); err != nil {
 		return nil, err
 	}
 	o.monitor = m
@@ -105,10 +181,28 @@ func (o *MonitorEventsOptions) End(ctx context.Context, restConfig *rest.Config,
 	t := time.Now()
 	o.endTime = &t
 	o.recordedResources = o.monitor.CurrentResourceState()
+	o.recordedStats = o.monitor.Stats()
+	o.runMetadata = monitorserialization.RunMetadata{
+		StartTime: &metav1.Time{Time: *o.startTime},
+		EndTime:   &metav1.Time{Time: t},
+	}
+	if jobType, err := platformidentification.GetJobType(ctx, restConfig); err == nil {
+		o.runMetadata.JobType = jobType
+	} else {
+		fmt.Fprintf(o.ErrOut, "warning: unable to identify the job type for the event metadata: %v\n", err)
+	}
 
 	var err error
 	fromTime, endTime := time.Time{}, time.Time{}
 	events := o.monitor.Intervals(fromTime, endTime)
+	if o.journal != nil {
+		if err := o.monitor.JournalErr(); err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: event journal %s is incomplete: %v\n", o.journal.Filename(), err)
+		}
+		if err := o.journal.Close(); err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: unable to close event journal %s: %v\n", o.journal.Filename(), err)
+		}
+	}
 	// this happens before calculation because events collected here could be used to drive later calculations
 	events, err = intervalcreation.InsertIntervalsFromCluster(ctx, restConfig, events, o.recordedResources, fromTime, endTime)
 	if err != nil {
@@ -153,6 +247,36 @@ func (o *MonitorEventsOptions) End(ctx context.Context, restConfig *rest.Config,
 	return nil
 }
 
+func parseTrackedResources(resources []string) ([]schema.GroupVersionResource, error) {
+	ret := []schema.GroupVersionResource{}
+	for _, resource := range resources {
+		gvr, _ := schema.ParseResourceArg(resource)
+		if gvr == nil {
+			return nil, fmt.Errorf("tracked resource %q must be resource.version.group, for instance deployments.v1.apps, or configmaps.v1. in the core group", resource)
+		}
+		ret = append(ret, *gvr)
+	}
+	return ret, nil
+}
+
+// JournalFilename is the name of the event journal written for a run started at startTime.  The journal can be
+// read back with monitorserialization.EventsFromFile.  It is removed once the events of the run are written, so it is
+// only left behind by a run that did not finish.
+func JournalFilename(startTime time.Time) string {
+	return fmt.Sprintf("e2e-events-journal_%s.jsonl", startTime.UTC().Format("20060102-150405"))
+}
+
+// removeJournal removes the event journal once the events it holds are written to the e2e-events file.
+func (o *MonitorEventsOptions) removeJournal() error {
+	if o.journal == nil {
+		return nil
+	}
+	if err := os.Remove(o.journal.Filename()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("unable to remove the event journal: %w", err)
+	}
+	return nil
+}
+
 func (o *MonitorEventsOptions) GetEvents() monitorapi.Intervals {
 	return o.recordedEvents
 }
@@ -165,6 +289,11 @@ func (o *MonitorEventsOptions) GetStartTime() *time.Time {
 	return o.startTime
 }
 
+// GetStartedRecorders returns the names of the recorders that were started.
+func (o *MonitorEventsOptions) GetStartedRecorders() []string {
+	return o.startedRecorders
+}
+
 // WriteRunDataToArtifactsDir attempts to write useful run data to the specified directory.
 func (o *MonitorEventsOptions) WriteRunDataToArtifactsDir(artifactDir string, timeSuffix string) error {
 	if o.endTime == nil {
@@ -181,11 +310,32 @@ func (o *MonitorEventsOptions) WriteRunDataToArtifactsDir(artifactDir string, ti
 	}
 	sort.Stable(monitorapi.ByTimeWithNamespacedPods(events))
 
-	for _, writer := range o.RunDataWriters {
+	for _, writer := range append(append([]RunDataWriter{}, o.RunDataWriters...), o.definitionWriters...) {
 		currErr := writer.WriteRunData(artifactDir, o.recordedResources, events, timeSuffix)
 		if currErr != nil {
 			errs = append(errs, currErr)
 		}
 	}
+	if err := o.writeRecorders(artifactDir, timeSuffix); err != nil {
+		errs = append(errs, err)
+	}
 	return utilerrors.NewAggregate(errs)
 }
+
+// monitorRecorders records which recorders ran, so that an interval that is missing from the artifacts can be told
+// apart from one that was never watched for.
+type monitorRecorders struct {
+	Started   []string `json:"started"`
+	Available []string `json:"available"`
+}
+
+func (o *MonitorEventsOptions) writeRecorders(artifactDir, timeSuffix string) error {
+	data, err := json.MarshalIndent(monitorRecorders{
+		Started:   o.startedRecorders,
+		Available: o.Recorders.Names(),
+	}, "", "    ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(artifactDir, fmt.Sprintf("monitor-recorders%s.json", timeSuffix)), data, 0644)
+}
@@ -0,0 +1,268 @@
+package ginkgo
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/version"
+)
+
+// otlpExportTimeout bounds sending a run trace to a collector, so an unreachable collector cannot hold up a run.
+const otlpExportTimeout = time.Minute
+
+// runTraceBuilder turns a run into a single OTLP trace.  Span IDs only have to be unique within the trace, so they
+// are handed out in order.
+type runTraceBuilder struct {
+	traceID    []byte
+	lastSpanID uint64
+	spans      []*tracepb.Span
+}
+
+// newRunTrace builds a trace with the suite as the root span, a child span for every test that ran and, under a
+// "monitor" span, a span for every interval selected by isTracedInterval.
+func newRunTrace(suiteName string, start, end time.Time, tests []*testCase, events monitorapi.Intervals) (*coltracepb.ExportTraceServiceRequest, error) {
+	traceID := make([]byte, 16)
+	if _, err := rand.Read(traceID); err != nil {
+		return nil, err
+	}
+	b := &runTraceBuilder{traceID: traceID}
+
+	failed := 0
+	for _, test := range tests {
+		if test.failed {
+			failed++
+		}
+	}
+	suiteStatus := okStatus()
+	if failed > 0 {
+		suiteStatus = errorStatus(fmt.Sprintf("%d tests failed", failed))
+	}
+	suiteSpanID := b.addSpan(nil, suiteName, start, end, suiteStatus, stringAttribute("test.suite", suiteName))
+
+	for _, test := range tests {
+		if test.start.IsZero() {
+			continue
+		}
+		result, status := "passed", okStatus()
+		switch {
+		case test.skipped:
+			result = "skipped"
+		case test.timedOut:
+			result, status = "timedout", errorStatus("timed out")
+		case test.failed:
+			result, status = "failed", errorStatus("failed")
+		case test.flake:
+			result = "flaked"
+		}
+		b.addSpan(suiteSpanID, test.name, test.start, test.end, status,
+			stringAttribute("test.name", test.name),
+			stringAttribute("test.result", result),
+		)
+	}
+
+	tracedEvents := events.Filter(isTracedInterval)
+	if len(tracedEvents) > 0 {
+		monitorSpanID := b.addSpan(suiteSpanID, "monitor", start, end, unsetStatus())
+		for _, event := range tracedEvents {
+			status := unsetStatus()
+			if event.Level == monitorapi.Error {
+				status = errorStatus(event.Message)
+			}
+			b.addSpan(monitorSpanID, event.Locator, event.From, event.To, status,
+				stringAttribute("monitor.level", event.Level.String()),
+				stringAttribute("monitor.locator", event.Locator),
+				stringAttribute("monitor.message", event.Message),
+			)
+		}
+	}
+
+	return &coltracepb.ExportTraceServiceRequest{
+		ResourceSpans: []*tracepb.ResourceSpans{
+			{
+				Resource: &resourcepb.Resource{
+					Attributes: []*commonpb.KeyValue{
+						stringAttribute("service.name", "openshift-tests"),
+						stringAttribute("service.version", version.Get().String()),
+					},
+				},
+				InstrumentationLibrarySpans: []*tracepb.InstrumentationLibrarySpans{
+					{
+						InstrumentationLibrary: &commonpb.InstrumentationLibrary{Name: "github.com/openshift/origin/pkg/test/ginkgo"},
+						Spans:                  b.spans,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// isTracedInterval selects the monitor intervals worth a span: disruption, clusteroperators in an abnormal
+// condition and node updates and reboots.
+func isTracedInterval(eventInterval monitorapi.EventInterval) bool {
+	if !eventInterval.To.After(eventInterval.From) {
+		return false
+	}
+	switch {
+	case monitorapi.IsDisruptionEvent(eventInterval):
+		return true
+	case monitorapi.IsOperator(eventInterval.Locator):
+		condition := monitorapi.GetOperatorConditionStatus(eventInterval.Message)
+		return condition != nil && monitorapi.IsAbnormalOperatorCondition(string(condition.Type), string(condition.Status))
+	case monitorapi.IsNode(eventInterval.Locator):
+		return monitorapi.MessageFrom(eventInterval).Annotations[monitorapi.AnnotationReason] == "NodeUpdate"
+	}
+	return false
+}
+
+func (b *runTraceBuilder) addSpan(parentSpanID []byte, name string, start, end time.Time, status *tracepb.Status, attributes ...*commonpb.KeyValue) []byte {
+	b.lastSpanID++
+	spanID := make([]byte, 8)
+	binary.BigEndian.PutUint64(spanID, b.lastSpanID)
+	if end.Before(start) {
+		end = start
+	}
+	b.spans = append(b.spans, &tracepb.Span{
+		TraceId:           b.traceID,
+		SpanId:            spanID,
+		ParentSpanId:      parentSpanID,
+		Name:              name,
+		Kind:              tracepb.Span_SPAN_KIND_INTERNAL,
+		StartTimeUnixNano: uint64(start.UnixNano()),
+		EndTimeUnixNano:   uint64(end.UnixNano()),
+		Attributes:        attributes,
+		Status:            status,
+	})
+	return spanID
+}
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+func unsetStatus() *tracepb.Status {
+	return &tracepb.Status{Code: tracepb.Status_STATUS_CODE_UNSET}
+}
+
+func okStatus() *tracepb.Status {
+	return &tracepb.Status{Code: tracepb.Status_STATUS_CODE_OK}
+}
+
+func errorStatus(message string) *tracepb.Status {
+	// collectors that predate status codes read the deprecated code
+	return &tracepb.Status{
+		Code:           tracepb.Status_STATUS_CODE_ERROR,
+		DeprecatedCode: tracepb.Status_DEPRECATED_STATUS_CODE_UNKNOWN_ERROR,
+		Message:        message,
+	}
+}
+
+// exportRunTrace writes trace to filename and sends it to endpoint, each if set.
+func exportRunTrace(trace *coltracepb.ExportTraceServiceRequest, filename, endpoint string) error {
+	if len(filename) > 0 {
+		data, err := otlpJSON(trace)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+			return err
+		}
+	}
+	if len(endpoint) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), otlpExportTimeout)
+		defer cancel()
+		if err := sendOTLPTrace(ctx, endpoint, trace); err != nil {
+			return fmt.Errorf("unable to send trace to %s: %w", endpoint, err)
+		}
+	}
+	return nil
+}
+
+// otlpJSON encodes trace as OTLP/JSON, which differs from the canonical protobuf JSON mapping in encoding enums as
+// numbers and trace and span IDs as hex.
+func otlpJSON(trace *coltracepb.ExportTraceServiceRequest) ([]byte, error) {
+	data, err := protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(trace)
+	if err != nil {
+		return nil, err
+	}
+	generic := map[string]interface{}{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	for _, resourceSpans := range jsonList(generic["resourceSpans"]) {
+		for _, librarySpans := range jsonList(resourceSpans["instrumentationLibrarySpans"]) {
+			for _, span := range jsonList(librarySpans["spans"]) {
+				for _, key := range []string{"traceId", "spanId", "parentSpanId"} {
+					encoded, ok := span[key].(string)
+					if !ok {
+						continue
+					}
+					id, err := base64.StdEncoding.DecodeString(encoded)
+					if err != nil {
+						return nil, err
+					}
+					span[key] = hex.EncodeToString(id)
+				}
+			}
+		}
+	}
+	return json.MarshalIndent(generic, "", "    ")
+}
+
+func jsonList(value interface{}) []map[string]interface{} {
+	items, _ := value.([]interface{})
+	ret := []map[string]interface{}{}
+	for _, item := range items {
+		if object, ok := item.(map[string]interface{}); ok {
+			ret = append(ret, object)
+		}
+	}
+	return ret
+}
+
+// sendOTLPTrace sends trace to an OTLP/HTTP collector.  The traces path is added to endpoint if it is missing.
+func sendOTLPTrace(ctx context.Context, endpoint string, trace *coltracepb.ExportTraceServiceRequest) error {
+	data, err := proto.Marshal(trace)
+	if err != nil {
+		return err
+	}
+	url := endpoint
+	if !strings.HasSuffix(url, "/v1/traces") {
+		url = strings.TrimSuffix(url, "/") + "/v1/traces"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("collector responded %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package ginkgo
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestNewRunTrace(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []*testCase{
+		{name: "passes", start: start, end: start.Add(time.Minute), success: true},
+		{name: "fails", start: start, end: start.Add(2 * time.Minute), failed: true},
+		{name: "never ran"},
+	}
+	events := monitorapi.Intervals{
+		{Condition: monitorapi.Condition{Level: monitorapi.Error, Locator: "disruption/kube-api connection/new", Message: "reason/DisruptionBegan stopped responding"}, From: start, To: start.Add(time.Second)},
+		{Condition: monitorapi.Condition{Level: monitorapi.Warning, Locator: "clusteroperator/etcd", Message: "condition/Degraded status/True reason/Unhealthy changed: unhealthy"}, From: start, To: start.Add(time.Minute)},
+		{Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "clusteroperator/etcd", Message: "condition/Available status/True reason/AsExpected changed: fine"}, From: start, To: start.Add(time.Minute)},
+		{Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "node/a", Message: "reason/NodeUpdate phase/Reboot roles/worker rebooted and kubelet started"}, From: start, To: start.Add(time.Minute)},
+		{Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "node/a", Message: "reason/NodeUpdate instant"}, From: start, To: start},
+	}
+
+	trace, err := newRunTrace("openshift/conformance", start, start.Add(time.Hour), tests, events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spans := trace.ResourceSpans[0].InstrumentationLibrarySpans[0].Spans
+	type span struct {
+		name   string
+		parent string
+		status tracepb.Status_StatusCode
+	}
+	names := map[string]string{}
+	got := []span{}
+	for _, s := range spans {
+		if !reflect.DeepEqual(s.TraceId, spans[0].TraceId) {
+			t.Errorf("span %s is in another trace", s.Name)
+		}
+		names[string(s.SpanId)] = s.Name
+		got = append(got, span{name: s.Name, parent: names[string(s.ParentSpanId)], status: s.Status.Code})
+	}
+	want := []span{
+		{name: "openshift/conformance", status: tracepb.Status_STATUS_CODE_ERROR},
+		{name: "passes", parent: "openshift/conformance", status: tracepb.Status_STATUS_CODE_OK},
+		{name: "fails", parent: "openshift/conformance", status: tracepb.Status_STATUS_CODE_ERROR},
+		{name: "monitor", parent: "openshift/conformance"},
+		{name: "disruption/kube-api connection/new", parent: "monitor", status: tracepb.Status_STATUS_CODE_ERROR},
+		{name: "clusteroperator/etcd", parent: "monitor"},
+		{name: "node/a", parent: "monitor"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected spans\n%#v\nwant\n%#v", got, want)
+	}
+
+	filename := filepath.Join(t.TempDir(), "trace.json")
+	if err := exportRunTrace(trace, filename, ""); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otlpJSON := struct {
+		ResourceSpans []struct {
+			InstrumentationLibrarySpans []struct {
+				Spans []struct {
+					TraceID string `json:"traceId"`
+					SpanID  string `json:"spanId"`
+					Kind    int    `json:"kind"`
+				} `json:"spans"`
+			} `json:"instrumentationLibrarySpans"`
+		} `json:"resourceSpans"`
+	}{}
+	if err := json.Unmarshal(data, &otlpJSON); err != nil {
+		t.Fatal(err)
+	}
+	if s := otlpJSON.ResourceSpans[0].InstrumentationLibrarySpans[0].Spans[0]; len(s.TraceID) != 32 || s.SpanID != "0000000000000001" || s.Kind != int(tracepb.Span_SPAN_KIND_INTERNAL) {
+		t.Errorf("unexpected OTLP/JSON span %#v", s)
+	}
+
+	var received *coltracepb.ExportTraceServiceRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		received = &coltracepb.ExportTraceServiceRequest{}
+		if err := proto.Unmarshal(body, received); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	if err := exportRunTrace(trace, "", server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(trace, received) {
+		t.Errorf("collector received a different trace")
+	}
+}
@@ -6,22 +6,50 @@ import (
 	"io"
 	"strings"
 	"sync"
+	"time"
 )
 
 // parallelByFileTestQueue runs tests in parallel unless they have
 // the `[Serial]` tag on their name or if another test with the
-// testExclusion field is currently running. Serial tests are
-// defered until all other tests are completed.
+// testExclusion field is currently running, and never runs more
+// tests of a concurrency class at once than its limit. Serial
+// tests are defered until all other tests are completed.  When durations
+// from an earlier run are known, the longest tests start first.
 type parallelByFileTestQueue struct {
 	commandContext *commandContext
+	// checkpoint is nil unless the results are checkpointed, and
+	// then tests that finished in an earlier run are not run again.
+	checkpoint *checkpoint
+	// retries is nil unless failing tests are retried as soon as
+	// they fail.
+	retries *immediateRetries
+	// scheduler is nil when no durations are known, in which case
+	// tests run in the order they are given.
+	scheduler *durationScheduler
+	// wallTime is shared with the queue of the retries, see forRetries.
+	wallTime *wallTimeStats
+}
+
+// wallTimeStats add up the expected and the actual wall time of every
+// Execute, known and total count the tests.
+type wallTimeStats struct {
+	predicted time.Duration
+	elapsed   time.Duration
+	known     int
+	total     int
 }
 
 type TestFunc func(ctx context.Context, test *testCase)
 
-func newParallelTestQueue(commandContext *commandContext) *parallelByFileTestQueue {
-	return &parallelByFileTestQueue{
+func newParallelTestQueue(commandContext *commandContext, durations TestDurations) *parallelByFileTestQueue {
+	q := &parallelByFileTestQueue{
 		commandContext: commandContext,
+		wallTime:       &wallTimeStats{},
 	}
+	if len(durations) > 0 {
+		q.scheduler = newDurationScheduler(durations)
+	}
+	return q
 }
 
 // OutputCommand prints to stdout what would have been executed.
@@ -54,49 +82,63 @@ func abortOnFailure(parentContext context.Context) (testAbortFunc, context.Conte
 	}, testCtx
 }
 
-// queueAllTests writes all the tests to the channel and closes it when all are finished
-// even with buffering, this can take a while since we don't infinitely buffer.
-func queueAllTests(remainingParallelTests chan *testCase, tests []*testCase) {
-	for i := range tests {
-		curr := tests[i]
-		remainingParallelTests <- curr
-	}
-
-	close(remainingParallelTests)
-}
-
-// runTestsUntilChannelEmpty reads from the channel to consume tests, run them, and return when the channel is closed.
-func runTestsUntilChannelEmpty(ctx context.Context, remainingParallelTests chan *testCase, testSuiteRunner testSuiteRunner) {
+// runTestsUntilNoneRemain takes tests from pending, runs them, and returns when no tests remain.
+func runTestsUntilNoneRemain(ctx context.Context, pending *pendingTests, testSuiteRunner testSuiteRunner) {
 	for {
-		select {
-		// if the context is finished, simply return
-		case <-ctx.Done():
+		test, ok := pending.next(ctx)
+		if !ok {
 			return
-
-		case test, ok := <-remainingParallelTests:
-			if !ok { // channel closed, then we're done
-				return
-			}
-			// if the context is finished, simply return
-			if ctx.Err() != nil {
-				return
-			}
-			testSuiteRunner.RunOneTest(ctx, test)
 		}
+		testSuiteRunner.RunOneTest(ctx, test)
+		pending.done(test)
 	}
 }
 
 // tests are currently being mutated during the run process.
 func (q *parallelByFileTestQueue) Execute(ctx context.Context, tests []*testCase, parallelism int, testOutput testOutputConfig, maybeAbortOnFailureFn testAbortFunc) {
+	tests = q.checkpoint.Resume(tests, testOutput.monitorRecorder)
+
 	testSuiteProgress := newTestSuiteProgress(len(tests))
 	testSuiteRunner := &testSuiteRunnerImpl{
 		commandContext:        q.commandContext,
 		testOutput:            testOutput,
 		testSuiteProgress:     testSuiteProgress,
 		maybeAbortOnFailureFn: maybeAbortOnFailureFn,
+		checkpoint:            q.checkpoint,
 	}
 
-	execute(ctx, testSuiteRunner, tests, parallelism)
+	if q.scheduler != nil {
+		tests = q.scheduler.longestFirst(tests)
+		predicted, known := q.scheduler.predictWallTime(tests, parallelism)
+		q.wallTime.predicted += predicted
+		q.wallTime.known += known
+		q.wallTime.total += len(tests)
+	}
+	start := time.Now()
+	execute(ctx, q.retries.wrap(testSuiteRunner), tests, parallelism)
+	q.wallTime.elapsed += time.Since(start)
+}
+
+// forRetries returns a queue for retrying the failing tests after every test
+// has run.  Its tests count toward the WallTimeSummary of q, but are neither
+// resumed from nor recorded in the checkpoint.
+func (q *parallelByFileTestQueue) forRetries() *parallelByFileTestQueue {
+	return &parallelByFileTestQueue{
+		commandContext: q.commandContext,
+		scheduler:      q.scheduler,
+		wallTime:       q.wallTime,
+	}
+}
+
+// WallTimeSummary compares the predicted wall time of the tests run so far,
+// including retries, to the actual one, or returns "" if no durations are
+// known.  Retries run as soon as a test fails take time that was not
+// predicted.
+func (q *parallelByFileTestQueue) WallTimeSummary() string {
+	if q.scheduler == nil {
+		return ""
+	}
+	return fmt.Sprintf("Predicted %s of tests from the durations of %d of %d tests in an earlier run, took %s", q.wallTime.predicted.Round(time.Second), q.wallTime.known, q.wallTime.total, q.wallTime.elapsed.Round(time.Second))
 }
 
 // execute is a convenience for unit testing
@@ -107,15 +149,17 @@ func execute(ctx context.Context, testSuiteRunner testSuiteRunner, tests []*test
 
 	serial, parallel := splitTests(tests, isSerialTest)
 
-	remainingParallelTests := make(chan *testCase, 100)
-	go queueAllTests(remainingParallelTests, parallel)
+	pending := newPendingTests(parallel)
+	stop := make(chan struct{})
+	defer close(stop)
+	go pending.wakeOnDone(ctx, stop)
 
 	var wg sync.WaitGroup
 	for i := 0; i < parallelism; i++ {
 		wg.Add(1)
 		go func(ctx context.Context) {
 			defer wg.Done()
-			runTestsUntilChannelEmpty(ctx, remainingParallelTests, testSuiteRunner)
+			runTestsUntilNoneRemain(ctx, pending, testSuiteRunner)
 		}(ctx)
 	}
 	wg.Wait()
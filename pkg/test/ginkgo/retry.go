@@ -0,0 +1,350 @@
+package ginkgo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// RetryPolicy decides which failing tests of a suite run again, to tell flakes from failures.  A failing test that
+// passes enough of its retries is a flake, and a failing test whose retry is skipped failed a precondition, so its
+// failure is dropped.
+type RetryPolicy struct {
+	// MaxAttempts is the most times a failing test runs, counting the first run.  Tests are only retried when it is at
+	// least two.
+	MaxAttempts int
+	// RequiredPasses is how many retries of a failing test must pass for it to be a flake.  Defaults to one.
+	RequiredPasses int
+	// Matches selects the tests that may be retried.  Every test may be retried when it is nil.
+	Matches func(name string) bool
+	// MaximumFailures stops retrying once more tests than this have failed, since that many failures are unlikely to
+	// be flakes.  It applies to the failures of the whole run, so when more tests fail the retries that ran
+	// immediately are ignored too.  Zero is no limit.
+	MaximumFailures int
+	// Immediate retries a test as soon as it fails, on the same worker, instead of after every test has run.
+	Immediate bool
+	// Backoff is the wait before the first retry, and doubles for every retry after it.
+	Backoff time.Duration
+}
+
+// retryPolicy returns the retry policy of the suite.  Suites without one retry each failing test once when no more
+// than MaximumAllowedFlakes tests failed.
+func (s *TestSuite) retryPolicy() RetryPolicy {
+	if s.RetryPolicy != nil {
+		return *s.RetryPolicy
+	}
+	if s.MaximumAllowedFlakes == 0 {
+		return RetryPolicy{}
+	}
+	return RetryPolicy{
+		MaxAttempts:     2,
+		MaximumFailures: s.MaximumAllowedFlakes,
+	}
+}
+
+func (p RetryPolicy) enabled() bool {
+	return p.MaxAttempts > 1
+}
+
+func (p RetryPolicy) requiredPasses() int {
+	if p.RequiredPasses < 1 {
+		return 1
+	}
+	return p.RequiredPasses
+}
+
+// allowsRetries returns whether tests are retried when failures tests have failed.
+func (p RetryPolicy) allowsRetries(failures int) bool {
+	return p.enabled() && (p.MaximumFailures == 0 || failures <= p.MaximumFailures)
+}
+
+// retryable returns the failing tests the policy retries.
+func (p RetryPolicy) retryable(failing []*testCase) []*testCase {
+	var ret []*testCase
+	for _, test := range failing {
+		if p.Matches == nil || p.Matches(test.name) {
+			ret = append(ret, test)
+		}
+	}
+	return ret
+}
+
+// backoff returns the wait before the given retry, counting from one.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	if p.Backoff <= 0 || retry < 1 {
+		return 0
+	}
+	backoff := p.Backoff
+	for i := 1; i < retry && backoff < time.Hour; i++ {
+		backoff *= 2
+	}
+	return backoff
+}
+
+// retryOutcome is what the attempts of a failing test add up to.
+type retryOutcome int
+
+const (
+	// retryFailed is a test that did not pass enough of its retries.
+	retryFailed retryOutcome = iota
+	// retryFlaked is a test that passed enough of its retries.
+	retryFlaked
+	// retrySkipped is a test whose retry was skipped, because the first run failed a precondition.
+	retrySkipped
+)
+
+// outcome returns what the attempts of a failing test add up to, and whether more retries could change it.  The
+// first attempt is the run that failed.
+func (p RetryPolicy) outcome(attempts []*testCase) (retryOutcome, bool) {
+	passes := 0
+	for _, attempt := range attempts[1:] {
+		switch {
+		case attempt.skipped:
+			return retrySkipped, true
+		case attempt.success:
+			passes++
+		}
+	}
+	if passes >= p.requiredPasses() {
+		return retryFlaked, true
+	}
+	remaining := p.MaxAttempts - len(attempts)
+	return retryFailed, passes+remaining < p.requiredPasses()
+}
+
+// retryAttempts records the retries of every failing test by the run that failed.
+type retryAttempts map[*testCase][]*testCase
+
+// add returns the attempts with the retries of other added.
+func (a retryAttempts) add(other retryAttempts) retryAttempts {
+	if a == nil {
+		return other
+	}
+	for test, retries := range other {
+		a[test] = retries
+	}
+	return a
+}
+
+// retriedAtEnd returns the failing tests the policy retries after every test has run.  Immediate retries only cover
+// the tests this process ran, so the failures resumed from a checkpoint, which have no attempts, are retried at the
+// end too.
+func (p RetryPolicy) retriedAtEnd(failing []*testCase, attempts retryAttempts) []*testCase {
+	if !p.Immediate {
+		return p.retryable(failing)
+	}
+	var notRetried []*testCase
+	for _, test := range failing {
+		if _, ok := attempts[test]; !ok {
+			notRetried = append(notRetried, test)
+		}
+	}
+	return p.retryable(notRetried)
+}
+
+// retryAtEnd retries the failing tests after every test has run.  Each round runs one retry of every test the
+// policy has not decided yet, in parallel.
+func retryAtEnd(ctx context.Context, policy RetryPolicy, failing []*testCase, execute func(ctx context.Context, tests []*testCase)) retryAttempts {
+	attempts := retryAttempts{}
+	var pending []*testCase
+	for _, test := range policy.retryable(failing) {
+		// like the immediate retries, a test that no retry could decide is not retried
+		if _, decided := policy.outcome([]*testCase{test}); !decided {
+			pending = append(pending, test)
+		}
+	}
+	for retry := 1; len(pending) > 0; retry++ {
+		if !sleepUnlessDone(ctx, policy.backoff(retry)) {
+			break
+		}
+		retries := make([]*testCase, 0, len(pending))
+		for _, test := range pending {
+			retries = append(retries, test.Retry(len(attempts[test])+2))
+		}
+		execute(ctx, retries)
+
+		var undecided []*testCase
+		for i, test := range pending {
+			attempts[test] = append(attempts[test], retries[i])
+			if _, decided := policy.outcome(append([]*testCase{test}, attempts[test]...)); !decided {
+				undecided = append(undecided, test)
+			}
+		}
+		pending = undecided
+	}
+	return attempts
+}
+
+// immediateRetries retries every failing test as soon as it fails, on the worker that ran it.
+type immediateRetries struct {
+	policy RetryPolicy
+
+	lock     sync.Mutex
+	failures int
+	attempts retryAttempts
+}
+
+func newImmediateRetries(policy RetryPolicy) *immediateRetries {
+	return &immediateRetries{
+		policy:   policy,
+		attempts: retryAttempts{},
+	}
+}
+
+// Attempts returns the retries of every test that was retried.
+func (r *immediateRetries) Attempts() retryAttempts {
+	if r == nil {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	ret := make(retryAttempts, len(r.attempts))
+	for test, attempts := range r.attempts {
+		ret[test] = attempts
+	}
+	return ret
+}
+
+// wrap returns a runner that retries the failing tests run by testSuiteRunner.
+func (r *immediateRetries) wrap(testSuiteRunner testSuiteRunner) testSuiteRunner {
+	if r == nil {
+		return testSuiteRunner
+	}
+	return &retryingTestSuiteRunner{testSuiteRunner: testSuiteRunner, retries: r}
+}
+
+type retryingTestSuiteRunner struct {
+	testSuiteRunner
+	retries *immediateRetries
+}
+
+func (r *retryingTestSuiteRunner) RunOneTest(ctx context.Context, test *testCase) {
+	r.testSuiteRunner.RunOneTest(ctx, test)
+	if !test.failed || ctx.Err() != nil {
+		return
+	}
+	r.retries.lock.Lock()
+	r.retries.failures++
+	allowed := r.retries.policy.allowsRetries(r.retries.failures)
+	r.retries.lock.Unlock()
+	if !allowed || len(r.retries.policy.retryable([]*testCase{test})) == 0 {
+		return
+	}
+
+	attempts := []*testCase{test}
+	for retry := 1; ; retry++ {
+		if _, decided := r.retries.policy.outcome(attempts); decided {
+			break
+		}
+		if !sleepUnlessDone(ctx, r.retries.policy.backoff(retry)) {
+			break
+		}
+		attempt := test.Retry(len(attempts) + 1)
+		r.testSuiteRunner.RunOneTest(ctx, attempt)
+		attempts = append(attempts, attempt)
+	}
+
+	r.retries.lock.Lock()
+	defer r.retries.lock.Unlock()
+	r.retries.attempts[test] = attempts[1:]
+}
+
+// applyRetries adds the retries that ran to tests and returns the tests that still fail, with the names of the tests
+// that flaked and of those that failed a precondition.  The failures of tests that failed a precondition are dropped.
+// Retries that report a flake themselves are left out, so the failure of the first run stays authoritative.  When more
+// tests failed than the policy retries for, which immediate retries cannot know until the run is over, every retry is
+// left out.
+func applyRetries(out io.Writer, policy RetryPolicy, tests, failing []*testCase, attempts retryAttempts) ([]*testCase, []*testCase, []string, []string) {
+	if !policy.allowsRetries(len(failing)) {
+		fmt.Fprintf(out, "Ignoring the retries since %d tests failed, more than the %d that may be retried\n", len(failing), policy.MaximumFailures)
+		return tests, failing, nil, nil
+	}
+	var stillFailing []*testCase
+	var flaky, skipped []string
+	dropped := map[*testCase]bool{}
+	var added []*testCase
+	for _, test := range failing {
+		var ran []*testCase
+		for _, attempt := range attempts[test] {
+			if attempt.success || attempt.failed || attempt.skipped || attempt.flake {
+				ran = append(ran, attempt)
+			}
+		}
+		if len(ran) == 0 {
+			stillFailing = append(stillFailing, test)
+			continue
+		}
+
+		all := append([]*testCase{test}, ran...)
+		for i, attempt := range all {
+			attempt.attempt = i + 1
+			attempt.attempts = len(all)
+		}
+		outcome, _ := policy.outcome(all)
+		switch outcome {
+		case retrySkipped:
+			skipped = append(skipped, test.name)
+			dropped[test] = true
+			for _, attempt := range ran {
+				if !attempt.failed {
+					added = append(added, attempt)
+				}
+			}
+			continue
+		case retryFlaked:
+			flaky = append(flaky, test.name)
+		default:
+			stillFailing = append(stillFailing, test)
+		}
+		for _, attempt := range ran {
+			if attempt.flake {
+				// Retry tests that flaked are omitted so that the original test is counted as a failure.
+				fmt.Fprintf(out, "Ignoring retry that returned a flake, original failure is authoritative for test: %s\n", attempt.name)
+				continue
+			}
+			added = append(added, attempt)
+		}
+	}
+
+	ret := make([]*testCase, 0, len(tests)+len(added))
+	for _, test := range tests {
+		if !dropped[test] {
+			ret = append(ret, test)
+		}
+	}
+	return append(ret, added...), stillFailing, flaky, skipped
+}
+
+// attemptProperties records which run of a retried test a JUnit test case is.
+func attemptProperties(test *testCase) []*junitapi.TestSuiteProperty {
+	if test.attempts == 0 {
+		return nil
+	}
+	return []*junitapi.TestSuiteProperty{
+		{Name: "attempt", Value: strconv.Itoa(test.attempt)},
+		{Name: "attempts", Value: strconv.Itoa(test.attempts)},
+	}
+}
+
+// sleepUnlessDone waits for the duration and returns false if the context finished first.
+func sleepUnlessDone(ctx context.Context, duration time.Duration) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if duration <= 0 {
+		return true
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
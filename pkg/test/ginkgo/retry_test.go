@@ -0,0 +1,273 @@
+package ginkgo
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedSuiteRunner sets the result of every run of a test from a script, in order.
+type scriptedSuiteRunner struct {
+	lock    sync.Mutex
+	results map[string][]TestState
+	runs    []string
+}
+
+func (r *scriptedSuiteRunner) RunOneTest(ctx context.Context, test *testCase) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	state := TestSucceeded
+	if results := r.results[test.name]; len(results) > 0 {
+		state = results[0]
+		r.results[test.name] = results[1:]
+	}
+	r.runs = append(r.runs, test.name)
+	mutateTestCaseWithResults(test, &testRunResultHandle{testRunResult: &testRunResult{name: test.name, testState: state}})
+}
+
+func TestRetryPolicyOutcome(t *testing.T) {
+	attempt := func(state TestState) *testCase {
+		test := &testCase{name: "test"}
+		mutateTestCaseWithResults(test, &testRunResultHandle{testRunResult: &testRunResult{name: test.name, testState: state}})
+		return test
+	}
+	failed, passed, skipped := attempt(TestFailed), attempt(TestSucceeded), attempt(TestSkipped)
+
+	tests := []struct {
+		name        string
+		policy      RetryPolicy
+		attempts    []*testCase
+		wantOutcome retryOutcome
+		wantDecided bool
+	}{
+		{
+			name:        "not retried yet",
+			policy:      RetryPolicy{MaxAttempts: 2},
+			attempts:    []*testCase{failed},
+			wantOutcome: retryFailed,
+		},
+		{
+			name:        "passed the retry",
+			policy:      RetryPolicy{MaxAttempts: 2},
+			attempts:    []*testCase{failed, passed},
+			wantOutcome: retryFlaked,
+			wantDecided: true,
+		},
+		{
+			name:        "failed the last retry",
+			policy:      RetryPolicy{MaxAttempts: 2},
+			attempts:    []*testCase{failed, failed},
+			wantOutcome: retryFailed,
+			wantDecided: true,
+		},
+		{
+			name:        "skipped the retry",
+			policy:      RetryPolicy{MaxAttempts: 3},
+			attempts:    []*testCase{failed, skipped},
+			wantOutcome: retrySkipped,
+			wantDecided: true,
+		},
+		{
+			name:        "needs another pass",
+			policy:      RetryPolicy{MaxAttempts: 4, RequiredPasses: 2},
+			attempts:    []*testCase{failed, passed},
+			wantOutcome: retryFailed,
+		},
+		{
+			name:        "cannot pass enough",
+			policy:      RetryPolicy{MaxAttempts: 4, RequiredPasses: 2},
+			attempts:    []*testCase{failed, failed, failed},
+			wantOutcome: retryFailed,
+			wantDecided: true,
+		},
+		{
+			name:        "passed enough",
+			policy:      RetryPolicy{MaxAttempts: 4, RequiredPasses: 2},
+			attempts:    []*testCase{failed, passed, failed, passed},
+			wantOutcome: retryFlaked,
+			wantDecided: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome, decided := tt.policy.outcome(tt.attempts)
+			if outcome != tt.wantOutcome || decided != tt.wantDecided {
+				t.Errorf("expected %v %v, got %v %v", tt.wantOutcome, tt.wantDecided, outcome, decided)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{Backoff: time.Second}
+	for retry, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second} {
+		if got := policy.backoff(retry); got != want {
+			t.Errorf("retry %d: expected %s, got %s", retry, want, got)
+		}
+	}
+	if got := (RetryPolicy{}).backoff(3); got != 0 {
+		t.Errorf("expected no backoff, got %s", got)
+	}
+}
+
+func TestSuiteDefaultRetryPolicy(t *testing.T) {
+	if (&TestSuite{}).retryPolicy().enabled() {
+		t.Errorf("expected no retries for a suite that allows no flakes")
+	}
+	policy := (&TestSuite{MaximumAllowedFlakes: 3}).retryPolicy()
+	if !policy.allowsRetries(3) || policy.allowsRetries(4) {
+		t.Errorf("expected retries only up to the allowed flakes, got %#v", policy)
+	}
+}
+
+func TestRetryAtEnd(t *testing.T) {
+	runner := &scriptedSuiteRunner{results: map[string][]TestState{
+		"flaky":        {TestFailed, TestSucceeded, TestSucceeded},
+		"broken":       {TestFailed, TestFailed},
+		"precondition": {TestFailed, TestSkipped},
+		"excluded":     {TestFailed},
+		"passes":       {TestSucceeded},
+	}}
+	tests := []*testCase{{name: "flaky"}, {name: "broken"}, {name: "precondition"}, {name: "excluded"}, {name: "passes"}}
+	for _, test := range tests {
+		runner.RunOneTest(context.TODO(), test)
+	}
+	_, _, _, failing := summarizeTests(tests)
+
+	policy := RetryPolicy{
+		MaxAttempts:    3,
+		RequiredPasses: 2,
+		Matches:        func(name string) bool { return name != "excluded" },
+	}
+	attempts := retryAtEnd(context.TODO(), policy, failing, func(ctx context.Context, retries []*testCase) {
+		execute(ctx, runner, retries, 2)
+	})
+	if len(attempts[tests[0]]) != 2 || len(attempts[tests[1]]) != 1 || len(attempts[tests[2]]) != 1 || len(attempts[tests[3]]) != 0 {
+		t.Fatalf("unexpected attempts %v", attempts)
+	}
+
+	all, stillFailing, flaky, skipped := applyRetries(ioutil.Discard, policy, tests, failing, attempts)
+	if got, want := testNames(stillFailing), []string{"broken", "excluded"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v to fail, got %v", want, got)
+	}
+	if !reflect.DeepEqual(flaky, []string{"flaky"}) || !reflect.DeepEqual(skipped, []string{"precondition"}) {
+		t.Errorf("unexpected flaky %v and skipped %v", flaky, skipped)
+	}
+	// broken stops after one retry since it can no longer pass twice, the failure of the test that failed a
+	// precondition is dropped once, and every attempt is numbered
+	wantNames := []string{"flaky", "broken", "excluded", "passes", "flaky", "flaky", "broken", "precondition"}
+	if got := testNames(all); !reflect.DeepEqual(got, wantNames) {
+		t.Errorf("expected %v, got %v", wantNames, got)
+	}
+	if tests[0].attempt != 1 || tests[0].attempts != 3 || all[5].attempt != 3 {
+		t.Errorf("unexpected attempt numbers %d/%d and %d", tests[0].attempt, tests[0].attempts, all[5].attempt)
+	}
+	if properties := attemptProperties(all[5]); len(properties) != 2 || properties[0].Value != "3" || properties[1].Value != "3" {
+		t.Errorf("unexpected attempt properties %v", properties)
+	}
+	if attemptProperties(tests[4]) != nil {
+		t.Errorf("expected no attempt properties for a test that was not retried")
+	}
+}
+
+func TestImmediateRetries(t *testing.T) {
+	runner := &scriptedSuiteRunner{results: map[string][]TestState{
+		"flaky":  {TestFailed, TestSucceeded},
+		"broken": {TestFailed, TestFailed},
+		"later":  {TestFailed, TestSucceeded},
+	}}
+	retries := newImmediateRetries(RetryPolicy{MaxAttempts: 2, MaximumFailures: 2, Immediate: true})
+	tests := []*testCase{{name: "flaky"}, {name: "broken"}, {name: "later"}}
+	execute(context.TODO(), retries.wrap(runner), tests, 1)
+
+	// every retry runs right after the failure, and no more tests are retried once too many failed
+	if want := []string{"flaky", "flaky", "broken", "broken", "later"}; !reflect.DeepEqual(runner.runs, want) {
+		t.Errorf("expected runs %v, got %v", want, runner.runs)
+	}
+	attempts := retries.Attempts()
+	if len(attempts) != 2 || !attempts[tests[0]][0].success || !attempts[tests[1]][0].failed {
+		t.Errorf("unexpected attempts %v", attempts)
+	}
+
+	// more tests failed than are retried, so the retries that ran are ignored like they would be at the end
+	_, _, _, failing := summarizeTests(tests)
+	all, stillFailing, flaky, _ := applyRetries(ioutil.Discard, retries.policy, tests, failing, attempts)
+	if len(all) != len(tests) || len(stillFailing) != 3 || len(flaky) != 0 {
+		t.Errorf("expected the retries to be ignored, got %v failing and %v flaky", testNames(stillFailing), flaky)
+	}
+}
+
+func TestRetryAtEndOfUndecidablePolicy(t *testing.T) {
+	runner := &scriptedSuiteRunner{results: map[string][]TestState{
+		"broken": {TestFailed, TestSucceeded},
+	}}
+	tests := []*testCase{{name: "broken"}}
+	runner.RunOneTest(context.TODO(), tests[0])
+	_, _, _, failing := summarizeTests(tests)
+
+	// no retry could pass enough times, so the test is not retried, as it would not be immediately
+	policy := RetryPolicy{MaxAttempts: 2, RequiredPasses: 2}
+	attempts := retryAtEnd(context.TODO(), policy, failing, func(ctx context.Context, retries []*testCase) {
+		execute(ctx, runner, retries, 1)
+	})
+	if len(attempts) != 0 || len(runner.runs) != 1 {
+		t.Errorf("expected no retries, got attempts %v and runs %v", attempts, runner.runs)
+	}
+}
+
+func TestRetryKeepsTestSettings(t *testing.T) {
+	test := &testCase{name: "test", apigroups: []string{"config.openshift.io"}, testTimeout: time.Hour, testExclusion: "node-drain", runsAlone: true}
+	retry := test.Retry(2)
+	if !reflect.DeepEqual(retry.apigroups, test.apigroups) || retry.testTimeout != time.Hour || retry.testExclusion != "node-drain" || !retry.runsAlone || retry.attempt != 2 {
+		t.Errorf("unexpected retry %#v", retry)
+	}
+}
+
+func TestImmediateRetriesOfResumedFailures(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), checkpointFilename)
+	earlier, err := openCheckpoint(filename, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := earlier.Record(&testRunResult{name: "resumed", testState: TestFailed}); err != nil {
+		t.Fatal(err)
+	}
+	earlier.Close()
+	resumed, err := openCheckpoint(filename, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resumed.Close()
+
+	runner := &scriptedSuiteRunner{results: map[string][]TestState{
+		"resumed": {TestSucceeded},
+		"flaky":   {TestFailed, TestSucceeded},
+	}}
+	policy := RetryPolicy{MaxAttempts: 2, Immediate: true}
+	retries := newImmediateRetries(policy)
+	tests := []*testCase{{name: "resumed"}, {name: "flaky"}}
+	execute(context.TODO(), retries.wrap(runner), resumed.Resume(tests, nil), 1)
+	_, _, _, failing := summarizeTests(tests)
+
+	// the failure resumed from the checkpoint did not run in this process, so it is retried at the end
+	attempts := retries.Attempts()
+	retried := policy.retriedAtEnd(failing, attempts)
+	if got, want := testNames(retried), []string{"resumed"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v to be retried at the end, got %v", want, got)
+	}
+	attempts = attempts.add(retryAtEnd(context.TODO(), policy, retried, func(ctx context.Context, retries []*testCase) {
+		execute(ctx, runner, retries, 1)
+	}))
+	if want := []string{"flaky", "flaky", "resumed"}; !reflect.DeepEqual(runner.runs, want) {
+		t.Errorf("expected runs %v, got %v", want, runner.runs)
+	}
+
+	_, stillFailing, flaky, _ := applyRetries(ioutil.Discard, policy, tests, failing, attempts)
+	if len(stillFailing) != 0 || !reflect.DeepEqual(flaky, []string{"resumed", "flaky"}) {
+		t.Errorf("expected both tests to flake, got failing %v and flaky %v", testNames(stillFailing), flaky)
+	}
+}
@@ -0,0 +1,140 @@
+package ginkgo
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Shard is one of several openshift-tests processes that split the tests of a suite between them, so a long suite can
+// run across CI jobs.  Every process must be given the same tests and durations to agree on the split.
+type Shard struct {
+	// Index is the shard of this process, counting from 1.
+	Index int
+	// Count is the number of shards.
+	Count int
+}
+
+// ParseShard reads a shard written as index/count, like 2/5.
+func ParseShard(value string) (Shard, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 2 {
+		return Shard{}, fmt.Errorf("shard %q must be index/count, like 2/5", value)
+	}
+	index, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Shard{}, fmt.Errorf("shard %q must be index/count, like 2/5", value)
+	}
+	count, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Shard{}, fmt.Errorf("shard %q must be index/count, like 2/5", value)
+	}
+	if count < 1 || index < 1 || index > count {
+		return Shard{}, fmt.Errorf("shard %q must have an index from 1 to the count", value)
+	}
+	return Shard{Index: index, Count: count}, nil
+}
+
+func (s Shard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+// fileSuffix distinguishes the artifacts of the shard from those of the other shards.
+func (s Shard) fileSuffix() string {
+	return fmt.Sprintf("_shard-%d-of-%d", s.Index, s.Count)
+}
+
+// Select returns the tests of the shard.  [Early] and [Late] tests check the cluster before and after the other tests,
+// so they all run in the first shard, once for the whole suite: they only bracket the tests of that shard, since the
+// shards run at the same time.  Without durations any other test goes to the shard given by the hash of its name.
+// With durations the tests are dealt longest first to the shard with the least work so far, so the shards take about
+// the same time.
+func (s Shard) Select(tests []*testCase, durations TestDurations) []*testCase {
+	if s.Count <= 1 {
+		return tests
+	}
+	if len(durations) == 0 {
+		selected := make([]*testCase, 0, len(tests)/s.Count+1)
+		for _, test := range tests {
+			if shardByHash(test, s.Count) == s.Index {
+				selected = append(selected, test)
+			}
+		}
+		return selected
+	}
+
+	shards := balanceShards(tests, durations, s.Count)
+	selected := make([]*testCase, 0, len(tests)/s.Count+1)
+	for _, test := range tests {
+		if shards[test.name] == s.Index {
+			selected = append(selected, test)
+		}
+	}
+	return selected
+}
+
+// pinnedToFirstShard returns whether the test runs in the first shard whatever its name or duration.
+func pinnedToFirstShard(test *testCase) bool {
+	return strings.Contains(test.name, "[Early]") || strings.Contains(test.name, "[Late]")
+}
+
+func shardByHash(test *testCase, count int) int {
+	if pinnedToFirstShard(test) {
+		return 1
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(test.name))
+	return int(hash.Sum32()%uint32(count)) + 1
+}
+
+// balanceShards assigns every test name to a shard.  The order of the tests does not matter, so that processes that
+// list the tests differently still agree.
+func balanceShards(tests []*testCase, durations TestDurations, count int) map[string]int {
+	scheduler := newDurationScheduler(durations)
+	type estimatedTest struct {
+		name     string
+		estimate time.Duration
+	}
+	estimated := make([]estimatedTest, 0, len(tests))
+	shards := make(map[string]int, len(tests))
+	work := make([]time.Duration, count)
+	// counts are the number of tests of every shard, to spread the tests that took no time
+	counts := make([]int, count)
+	for _, test := range tests {
+		estimate, _ := scheduler.estimate(test)
+		if pinnedToFirstShard(test) {
+			if _, ok := shards[test.name]; !ok {
+				shards[test.name] = 1
+				work[0] += estimate
+				counts[0]++
+			}
+			continue
+		}
+		estimated = append(estimated, estimatedTest{name: test.name, estimate: estimate})
+	}
+	sort.Slice(estimated, func(i, j int) bool {
+		if estimated[i].estimate != estimated[j].estimate {
+			return estimated[i].estimate > estimated[j].estimate
+		}
+		return estimated[i].name < estimated[j].name
+	})
+
+	for _, test := range estimated {
+		if _, ok := shards[test.name]; ok {
+			continue
+		}
+		least := 0
+		for i := range work {
+			if work[i] < work[least] || (work[i] == work[least] && counts[i] < counts[least]) {
+				least = i
+			}
+		}
+		work[least] += test.estimate
+		counts[least]++
+		shards[test.name] = least + 1
+	}
+	return shards
+}
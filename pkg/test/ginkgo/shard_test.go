@@ -0,0 +1,132 @@
+package ginkgo
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseShard(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Shard
+		wantErr bool
+	}{
+		{value: "2/5", want: Shard{Index: 2, Count: 5}},
+		{value: "1/1", want: Shard{Index: 1, Count: 1}},
+		{value: "0/5", wantErr: true},
+		{value: "6/5", wantErr: true},
+		{value: "2", wantErr: true},
+		{value: "a/5", wantErr: true},
+		{value: "2/5/1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseShard(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func shardTestCases(count int) []*testCase {
+	tests := []*testCase{}
+	for i := 0; i < count; i++ {
+		tests = append(tests, &testCase{name: fmt.Sprintf("test %d", i)})
+	}
+	return tests
+}
+
+// selectAll returns the names of the tests of every shard, and fails if a test is in no shard or in more than one.
+func selectAll(t *testing.T, tests []*testCase, durations TestDurations, count int) [][]string {
+	seen := map[string]int{}
+	ret := [][]string{}
+	for index := 1; index <= count; index++ {
+		names := testNames(Shard{Index: index, Count: count}.Select(tests, durations))
+		for _, name := range names {
+			seen[name]++
+		}
+		ret = append(ret, names)
+	}
+	for _, test := range tests {
+		if seen[test.name] != 1 {
+			t.Errorf("expected %q to be in one shard, found in %d", test.name, seen[test.name])
+		}
+	}
+	return ret
+}
+
+func TestShardSelectByHash(t *testing.T) {
+	tests := shardTestCases(100)
+	shards := selectAll(t, tests, nil, 4)
+
+	// the same tests in another order go to the same shards
+	reversed := make([]*testCase, 0, len(tests))
+	for i := len(tests) - 1; i >= 0; i-- {
+		reversed = append(reversed, tests[i])
+	}
+	for i, names := range selectAll(t, reversed, nil, 4) {
+		if !reflect.DeepEqual(sortedNames(names), sortedNames(shards[i])) {
+			t.Errorf("shard %d differs when the tests are reordered", i+1)
+		}
+	}
+}
+
+func TestShardSelectByDuration(t *testing.T) {
+	tests := shardTestCases(6)
+	durations := TestDurations{
+		"test 0": 60 * time.Minute,
+		"test 1": 30 * time.Minute,
+		"test 2": 20 * time.Minute,
+		"test 3": 10 * time.Minute,
+		"test 4": 10 * time.Minute,
+		"test 5": 5 * time.Minute,
+	}
+	shards := selectAll(t, tests, durations, 2)
+
+	// test 4 goes to the shard with fewer tests when both have 60 minutes of work
+	want := [][]string{
+		{"test 0", "test 4"},
+		{"test 1", "test 2", "test 3", "test 5"},
+	}
+	if !reflect.DeepEqual(shards, want) {
+		t.Errorf("expected %v, got %v", want, shards)
+	}
+}
+
+func TestShardSelectSpreadsTestsWithoutTime(t *testing.T) {
+	tests := shardTestCases(9)
+	durations := TestDurations{"test 0": 0}
+	for _, names := range selectAll(t, tests, durations, 3) {
+		if len(names) != 3 {
+			t.Errorf("expected 3 tests in every shard, got %v", names)
+		}
+	}
+}
+
+func TestShardSelectPinsEarlyAndLateTests(t *testing.T) {
+	tests := append(shardTestCases(20), &testCase{name: "check [Early]"}, &testCase{name: "check [Late]"})
+	durations := TestDurations{"check [Early]": time.Minute, "check [Late]": 30 * time.Minute, "test 0": time.Minute}
+	for _, durations := range []TestDurations{nil, durations} {
+		shards := selectAll(t, tests, durations, 3)
+		first := map[string]bool{}
+		for _, name := range shards[0] {
+			first[name] = true
+		}
+		if !first["check [Early]"] || !first["check [Late]"] {
+			t.Errorf("expected the [Early] and [Late] tests in the first shard, got %v", shards)
+		}
+	}
+}
+
+func sortedNames(names []string) []string {
+	ret := append([]string{}, names...)
+	sort.Strings(ret)
+	return ret
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"k8s.io/client-go/rest"
 
@@ -94,3 +95,25 @@ func createSyntheticTestsFromMonitor(events monitorapi.Intervals, monitorDuratio
 
 	return syntheticTestResults, buf, errBuf
 }
+
+// failingAndFlakySyntheticTests returns the names of the synthetic tests that only failed, and of those that both
+// failed and passed.
+func failingAndFlakySyntheticTests(syntheticTestResults []*junitapi.JUnitTestCase) (sets.String, sets.String) {
+	// mark any failures by name
+	failingSyntheticTestNames, flakySyntheticTestNames := sets.NewString(), sets.NewString()
+	for _, test := range syntheticTestResults {
+		if test.FailureOutput != nil {
+			failingSyntheticTestNames.Insert(test.Name)
+		}
+	}
+	// if a test has both a pass and a failure, flag it
+	// as a flake
+	for _, test := range syntheticTestResults {
+		if test.FailureOutput == nil {
+			if failingSyntheticTestNames.Has(test.Name) {
+				flakySyntheticTestNames.Insert(test.Name)
+			}
+		}
+	}
+	return failingSyntheticTestNames.Difference(flakySyntheticTestNames), flakySyntheticTestNames
+}
@@ -19,6 +19,10 @@ type testCase struct {
 
 	// identifies which tests can be run in parallel (ginkgo runs suites linearly)
 	testExclusion string
+	// limits the number of tests of each class that run at once, by class
+	concurrencyClasses map[string]int
+	// keeps every other test from running alongside this one
+	runsAlone bool
 	// specific timeout for the current test. When set, it overrides the current
 	// suite timeout
 	testTimeout time.Duration
@@ -34,7 +38,10 @@ type testCase struct {
 	success  bool
 	timedOut bool
 
-	previous *testCase
+	// attempt counts the runs of a test that was retried, from one for the run that failed, and attempts is how
+	// many runs there were.  Both are zero for a test that was not retried.
+	attempt  int
+	attempts int
 }
 
 var re = regexp.MustCompile(`.*\[Timeout:(.[^\]]*)\]`)
@@ -64,17 +71,30 @@ func newTestCaseFromGinkgoSpec(spec types.TestSpec) (*testCase, error) {
 		tc.testTimeout = testTimeOut
 	}
 
+	concurrencyClasses, testExclusion, err := parseConcurrency(name)
+	if err != nil {
+		return nil, err
+	}
+	tc.concurrencyClasses = concurrencyClasses
+	tc.testExclusion = testExclusion
+
 	return tc, nil
 }
 
-func (t *testCase) Retry() *testCase {
+// Retry returns a copy of the test to run again as the given attempt, counting the run that failed as the first.
+func (t *testCase) Retry(attempt int) *testCase {
 	copied := &testCase{
 		name:          t.name,
 		spec:          t.spec,
 		locations:     t.locations,
+		apigroups:     t.apigroups,
 		testExclusion: t.testExclusion,
+		testTimeout:   t.testTimeout,
 
-		previous: t,
+		concurrencyClasses: t.concurrencyClasses,
+		runsAlone:          t.runsAlone,
+
+		attempt: attempt,
 	}
 	return copied
 }
@@ -91,10 +111,19 @@ type TestSuite struct {
 	Parallelism int
 	// The number of flakes that may occur before this test is marked as a failure.
 	MaximumAllowedFlakes int
+	// RetryPolicy decides which failing tests run again.  Without one, failing tests are retried once when no more
+	// than MaximumAllowedFlakes tests failed.
+	RetryPolicy *RetryPolicy
 
 	// SyntheticEventTests is a set of suite level synthetics applied
 	SyntheticEventTests JUnitsForEvents
 
+	// RecorderSelectors enable or disable monitors for this suite, ahead of any chosen on the command line.  See
+	// monitor.RecorderRegistry.Select.
+	RecorderSelectors []string
+	// TrackedResources are resources, as resource.version.group, the monitor should watch for this suite's invariants.
+	TrackedResources []string
+
 	TestTimeout time.Duration
 }
 
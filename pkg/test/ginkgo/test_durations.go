@@ -0,0 +1,198 @@
+package ginkgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// TestDurations are how long tests took in an earlier run, by name.  The queue uses them to start the longest tests
+// first, so a long test picked last does not stretch the whole bucket.
+type TestDurations map[string]time.Duration
+
+// LoadTestDurations reads test durations from the JUnit XML written by an earlier run, or from JSON that maps test
+// names to seconds.  If filename is a directory, every .xml and .json file in it is read.  A test listed more than
+// once keeps its longest duration, and skipped tests are ignored.
+func LoadTestDurations(filename string) (TestDurations, error) {
+	files := []string{filename}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		files = nil
+		for _, pattern := range []string{"*.xml", "*.json"} {
+			matches, err := filepath.Glob(filepath.Join(filename, pattern))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, matches...)
+		}
+	}
+
+	durations := TestDurations{}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := durations.add(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return durations, nil
+}
+
+func (d TestDurations) add(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("<")) {
+		suites := &junitapi.JUnitTestSuites{}
+		if err := xml.Unmarshal(data, suites); err != nil || len(suites.Suites) == 0 {
+			suite := &junitapi.JUnitTestSuite{}
+			if err := xml.Unmarshal(data, suite); err != nil {
+				return err
+			}
+			suites.Suites = []*junitapi.JUnitTestSuite{suite}
+		}
+		for _, suite := range suites.Suites {
+			d.addJUnitSuite(suite)
+		}
+		return nil
+	}
+
+	seconds := map[string]float64{}
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return err
+	}
+	for name, value := range seconds {
+		d.observe(name, time.Duration(value*float64(time.Second)))
+	}
+	return nil
+}
+
+func (d TestDurations) addJUnitSuite(suite *junitapi.JUnitTestSuite) {
+	for _, test := range suite.TestCases {
+		if test.SkipMessage != nil {
+			continue
+		}
+		d.observe(test.Name, time.Duration(test.Duration*float64(time.Second)))
+	}
+	for _, child := range suite.Children {
+		d.addJUnitSuite(child)
+	}
+}
+
+func (d TestDurations) observe(name string, duration time.Duration) {
+	if duration > d[name] {
+		d[name] = duration
+	}
+}
+
+// fallback is the duration assumed for tests that did not run before, the median of the known durations.
+func (d TestDurations) fallback() time.Duration {
+	if len(d) == 0 {
+		return 0
+	}
+	all := make([]time.Duration, 0, len(d))
+	for _, duration := range d {
+		all = append(all, duration)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+	return all[len(all)/2]
+}
+
+// durationScheduler orders tests by their durations in an earlier run and predicts how long they take.
+type durationScheduler struct {
+	durations TestDurations
+	fallback  time.Duration
+}
+
+func newDurationScheduler(durations TestDurations) *durationScheduler {
+	return &durationScheduler{
+		durations: durations,
+		fallback:  durations.fallback(),
+	}
+}
+
+func (s *durationScheduler) estimate(test *testCase) (time.Duration, bool) {
+	if duration, ok := s.durations[test.name]; ok {
+		return duration, true
+	}
+	return s.fallback, false
+}
+
+// longestFirst returns a copy of tests with the longest first.  Workers take the next test as soon as they are free,
+// so starting the longest tests first packs the tests across the workers.  Tests of the same duration keep their
+// order.
+func (s *durationScheduler) longestFirst(tests []*testCase) []*testCase {
+	ret := make([]*testCase, len(tests))
+	copy(ret, tests)
+	estimates := make(map[*testCase]time.Duration, len(ret))
+	for _, test := range ret {
+		estimates[test], _ = s.estimate(test)
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return estimates[ret[i]] > estimates[ret[j]]
+	})
+	return ret
+}
+
+// predictWallTime returns how long execute is expected to take for tests in the order they are given: parallel tests
+// go to the first of parallelism workers to be free, skipping tests whose concurrency classes or exclusion are full as
+// execute does, then serial tests run one after another.  It also returns how many of the tests ran before.
+func (s *durationScheduler) predictWallTime(tests []*testCase, parallelism int) (time.Duration, int) {
+	serial, parallel := splitTests(tests, isSerialTest)
+	known := 0
+	for _, test := range tests {
+		if _, ok := s.durations[test.name]; ok {
+			known++
+		}
+	}
+
+	type runningTest struct {
+		test   *testCase
+		finish time.Duration
+	}
+	pending := newPendingTests(parallel)
+	var running []runningTest
+	var now time.Duration
+	for len(pending.tests) > 0 || len(running) > 0 {
+		// start every test that can run on a free worker
+		for len(running) < max(1, parallelism) {
+			test, ok := pending.take()
+			if !ok {
+				break
+			}
+			estimate, _ := s.estimate(test)
+			running = append(running, runningTest{test: test, finish: now + estimate})
+		}
+		if len(running) == 0 {
+			break
+		}
+		// then wait for the first running test to finish
+		first := 0
+		for i := range running {
+			if running[i].finish < running[first].finish {
+				first = i
+			}
+		}
+		now = running[first].finish
+		pending.release(running[first].test)
+		running = append(running[:first], running[first+1:]...)
+	}
+
+	wallTime := now
+	for _, test := range serial {
+		estimate, _ := s.estimate(test)
+		wallTime += estimate
+	}
+	return wallTime, known
+}
@@ -0,0 +1,112 @@
+package ginkgo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadTestDurations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-durations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	junit := `<testsuites>
+  <testsuite name="openshift-tests" tests="3">
+    <testcase name="a" time="120.5"></testcase>
+    <testcase name="b" time="30"><skipped message="skipped"></skipped></testcase>
+    <testcase name="c" time="10"></testcase>
+  </testsuite>
+</testsuites>`
+	if err := ioutil.WriteFile(filepath.Join(dir, "junit_e2e_20220301-100000.xml"), []byte(junit), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// a retry took longer, the longest duration wins
+	if err := ioutil.WriteFile(filepath.Join(dir, "durations.json"), []byte(`{"c": 15, "d": 0.5}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	durations, err := LoadTestDurations(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := TestDurations{
+		"a": 120500 * time.Millisecond,
+		"c": 15 * time.Second,
+		"d": 500 * time.Millisecond,
+	}
+	if !reflect.DeepEqual(expected, durations) {
+		t.Errorf("expected %v, got %v", expected, durations)
+	}
+
+	single, err := LoadTestDurations(filepath.Join(dir, "junit_e2e_20220301-100000.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(single) != 2 {
+		t.Errorf("expected the durations of a and c, got %v", single)
+	}
+}
+
+func TestDurationScheduler(t *testing.T) {
+	scheduler := newDurationScheduler(TestDurations{
+		"short":  1 * time.Minute,
+		"medium": 5 * time.Minute,
+		"long":   20 * time.Minute,
+	})
+	tests := []*testCase{
+		{name: "short"},
+		{name: "unknown"},
+		{name: "long"},
+		{name: "medium"},
+		{name: "[Serial] unknown"},
+	}
+
+	ordered := []string{}
+	for _, test := range scheduler.longestFirst(tests) {
+		ordered = append(ordered, test.name)
+	}
+	// unknown tests are assumed to take the median duration
+	if expected := []string{"long", "unknown", "medium", "[Serial] unknown", "short"}; !reflect.DeepEqual(expected, ordered) {
+		t.Errorf("expected %v, got %v", expected, ordered)
+	}
+	if tests[0].name != "short" {
+		t.Errorf("the tests were reordered in place")
+	}
+
+	exclusive := copyTests(scheduler.longestFirst(tests))
+	for _, test := range exclusive {
+		if test.name == "long" || test.name == "medium" {
+			test.testExclusion = "quota"
+		}
+	}
+
+	for _, tt := range []struct {
+		name        string
+		tests       []*testCase
+		parallelism int
+		expected    time.Duration
+	}{
+		{name: "longest first", tests: scheduler.longestFirst(tests), parallelism: 2, expected: 20*time.Minute + 5*time.Minute},
+		// the long test picked last stretches the bucket
+		{name: "in order", tests: tests, parallelism: 2, expected: 1*time.Minute + 20*time.Minute + 5*time.Minute},
+		{name: "one worker", tests: tests, parallelism: 1, expected: 31*time.Minute + 5*time.Minute},
+		// the medium test waits for the long one, while the other worker runs out of tests
+		{name: "exclusive", tests: exclusive, parallelism: 2, expected: 20*time.Minute + 5*time.Minute + 5*time.Minute},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			predicted, known := scheduler.predictWallTime(tt.tests, tt.parallelism)
+			if predicted != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, predicted)
+			}
+			if known != 3 {
+				t.Errorf("expected 3 known tests, got %d", known)
+			}
+		})
+	}
+}
@@ -27,6 +27,8 @@ type testSuiteRunnerImpl struct {
 	testOutput            testOutputConfig
 	testSuiteProgress     *testSuiteProgress
 	maybeAbortOnFailureFn testAbortFunc
+	// checkpoint, if set, records every test that finishes so an interrupted run can be resumed
+	checkpoint *checkpoint
 }
 
 // RunOneTest runs a test, mutates the testCase with result, and reports the result
@@ -56,6 +58,16 @@ func (r *testSuiteRunnerImpl) RunOneTest(ctx context.Context, test *testCase) {
 
 	testRunResult.testRunResult = r.commandContext.RunTestInNewProcess(ctx, test)
 	mutateTestCaseWithResults(test, testRunResult)
+
+	// a test cut short by the run being interrupted has to run again when the run is resumed, and retries are
+	// decided again after resuming
+	if ctx.Err() == nil && test.attempt == 0 {
+		if err := r.checkpoint.Record(testRunResult.testRunResult); err != nil {
+			r.testOutput.testOutputLock.Lock()
+			fmt.Fprintf(r.testOutput.out, "error: Unable to checkpoint %q: %v\n", test.name, err)
+			r.testOutput.testOutputLock.Unlock()
+		}
+	}
 }
 
 func mutateTestCaseWithResults(test *testCase, testRunResult *testRunResultHandle) {
@@ -243,6 +255,11 @@ func recordTestResultInLog(testRunResult *testRunResultHandle, out io.Writer, in
 }
 
 func recordTestResultInMonitor(testRunResult *testRunResultHandle, monitorRecorder monitor.Recorder) {
+	monitorRecorder.Record(testResultCondition(testRunResult.testRunResult))
+}
+
+// testResultCondition is the condition recorded in the monitor when a test finishes.
+func testResultCondition(testRunResult *testRunResult) monitorapi.Condition {
 	eventMessage := "finishedStatus/Unknown reason/Unknown"
 	eventLevel := monitorapi.Warning
 
@@ -267,11 +284,11 @@ func recordTestResultInMonitor(testRunResult *testRunResultHandle, monitorRecord
 		eventLevel = monitorapi.Error
 	}
 
-	monitorRecorder.Record(monitorapi.Condition{
+	return monitorapi.Condition{
 		Level:   eventLevel,
 		Locator: monitorapi.E2ETestLocator(testRunResult.name),
 		Message: eventMessage,
-	})
+	}
 }
 
 // RunTestInNewProcess runs a test case in a different process and returns a result
@@ -13,6 +13,7 @@ import (
 	g "github.com/onsi/ginkgo/v2"
 	configv1 "github.com/openshift/api/config/v1"
 	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	"github.com/openshift/origin/pkg/synthetictests/platformidentification"
 	"github.com/openshift/origin/test/e2e/upgrade/adminack"
 	"github.com/openshift/origin/test/e2e/upgrade/alert"
@@ -379,6 +380,7 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 	clusterCompletesUpgradeTestName := "[sig-cluster-lifecycle] Cluster completes upgrade"
 
 	// trigger the update and record verification as an independent step
+	recordUpgradePhase(kubeClient, uid, monitorapi.UpgradePhaseStartedEventReason, monitorapi.UpgradePhaseAcknowledge)
 	if err := disruption.RecordJUnit(
 		f,
 		"[sig-cluster-lifecycle] Cluster version operator acknowledges upgrade",
@@ -450,11 +452,26 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 		recordClusterEvent(kubeClient, uid, "Upgrade", "UpgradeFailed", fmt.Sprintf("failed to acknowledge version: %v", err), true)
 		return err
 	}
+	recordUpgradePhase(kubeClient, uid, monitorapi.UpgradePhaseEndedEventReason, monitorapi.UpgradePhaseAcknowledge)
+	recordUpgradePhase(kubeClient, uid, monitorapi.UpgradePhaseStartedEventReason, monitorapi.UpgradePhaseControlPlane)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	go monitor.Disrupt(ctx, kubeClient, upgradeDisruptRebootPolicy)
 
+	// the pools start rolling while the control plane is still updating, so watch them from here on
+	poolCtx, poolCancel := context.WithCancel(ctx)
+	poolsDone := make(chan struct{})
+	go func() {
+		defer close(poolsDone)
+		recordPoolPhases(poolCtx, kubeClient, dc, uid)
+	}()
+	// nothing may be recorded once the upgrade has returned
+	defer func() {
+		poolCancel()
+		<-poolsDone
+	}()
+
 	// observe the upgrade, taking action as necessary
 	if err := disruption.RecordJUnit(
 		f,
@@ -511,6 +528,7 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 			}
 
 			framework.Logf("Completed %s to %s", action, versionString(desired))
+			recordUpgradePhase(kubeClient, uid, monitorapi.UpgradePhaseEndedEventReason, monitorapi.UpgradePhaseControlPlane)
 			recordClusterEvent(kubeClient, uid, "Upgrade", "UpgradeVersion", fmt.Sprintf("version/%s image/%s", updated.Status.Desired.Version, updated.Status.Desired.Version), false)
 
 			// record whether the cluster was fast or slow upgrading.  Don't fail the test, we still want signal on the actual tests themselves.
@@ -569,6 +587,9 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 		recordClusterEvent(kubeClient, uid, "Upgrade", "UpgradeFailed", fmt.Sprintf("failed to upgrade nodes: %v", err), true)
 		return err
 	}
+	// record the end of the pools that finished since the last check
+	poolCancel()
+	<-poolsDone
 
 	if errMasterUpdating != nil {
 		recordClusterEvent(kubeClient, uid, "Upgrade", "UpgradeFailed", fmt.Sprintf("master was updating after cluster version reached level: %v", errMasterUpdating), true)
@@ -622,6 +643,44 @@ func recordClusterEvent(client kubernetes.Interface, uid, action, reason, note s
 	}
 }
 
+// recordUpgradePhase records the start or the end of an upgrade phase.  The monitor builds an interval for every phase
+// from these events (see monitorapi.UpgradePhaseStartedEventReason).
+func recordUpgradePhase(client kubernetes.Interface, uid, reason, phase string) {
+	recordClusterEvent(client, uid, "Upgrade", reason, fmt.Sprintf("phase/%s", phase), false)
+}
+
+// recordPoolPhases records an upgrade phase for every MachineConfigPool from the time it is seen updating to the
+// time it is up to date again.  It checks the pools every ten seconds until ctx is done, and once more after that.
+func recordPoolPhases(ctx context.Context, client kubernetes.Interface, dc dynamic.Interface, uid string) {
+	mcps := dc.Resource(schema.GroupVersionResource{
+		Group:    "machineconfiguration.openshift.io",
+		Version:  "v1",
+		Resource: "machineconfigpools",
+	})
+	updating := map[string]bool{}
+	check := func() {
+		pools, err := mcps.List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			framework.Logf("error getting pools %v", err)
+			return
+		}
+		for _, p := range pools.Items {
+			name := p.GetName()
+			upToDate, requiresUpdate := IsPoolUpdated(mcps, name)
+			switch {
+			case !updating[name] && requiresUpdate && !upToDate:
+				updating[name] = true
+				recordUpgradePhase(client, uid, monitorapi.UpgradePhaseStartedEventReason, monitorapi.UpgradePoolPhase(name))
+			case updating[name] && upToDate:
+				delete(updating, name)
+				recordUpgradePhase(client, uid, monitorapi.UpgradePhaseEndedEventReason, monitorapi.UpgradePoolPhase(name))
+			}
+		}
+	}
+	wait.Until(check, 10*time.Second, ctx.Done())
+	check()
+}
+
 // TODO(runcom): drop this when MCO types are in openshift/api and we can use the typed client directly
 func IsPoolUpdated(dc dynamic.NamespaceableResourceInterface, name string) (poolUpToDate bool, poolIsUpdating bool) {
 	pool, err := dc.Get(context.Background(), name, metav1.GetOptions{})
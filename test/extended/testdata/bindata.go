@@ -49241,6 +49241,24 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
     <script src="https://maxcdn.bootstrapcdn.com/bootstrap/4.0.0/js/bootstrap.min.js"
             integrity="sha384-JZR6Spejh4U02d8jOt6vLEHfe/JQGiRRSQQxSfFWpi1MquVdAyjUar5+76PVCmYl"
             crossorigin="anonymous"></script>
+    <style>
+        #chart {
+            position: relative;
+        }
+
+        .upgrade-phase {
+            background: rgba(138, 111, 209, 0.08);
+            border-left: 1px dashed rgba(138, 111, 209, 0.6);
+            bottom: 0;
+            color: #6a52a8;
+            font-size: 10px;
+            overflow: hidden;
+            pointer-events: none;
+            position: absolute;
+            top: 0;
+            white-space: nowrap;
+        }
+    </style>
 </head>
 <body>
 
@@ -49401,6 +49419,13 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
         return false
     }
 
+    function isUpgradePhase(eventInterval) {
+        if (eventInterval.locator.startsWith("upgrade/")) {
+            return true
+        }
+        return false
+    }
+
     function isAlert(eventInterval) {
         if (eventInterval.locator.startsWith("alert/")) {
             return true
@@ -49575,6 +49600,31 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
         }
     }
 
+    // drawUpgradePhases shades the upgrade phases behind every row of the chart for the time range in zoomX.
+    function drawUpgradePhases(chart, zoomX) {
+        const el = document.querySelector('#chart');
+        el.querySelectorAll('.upgrade-phase').forEach((band) => band.remove());
+        const start = new Date(zoomX[0]).getTime();
+        const end = new Date(zoomX[1]).getTime();
+        const plotWidth = chart.width() - chart.leftMargin() - chart.rightMargin();
+        if (end <= start || plotWidth <= 0) {
+            return
+        }
+        eventIntervals.items.filter(isUpgradePhase).forEach((item) => {
+            const from = Math.max(new Date(item.from).getTime(), start);
+            const to = item.to ? Math.min(new Date(item.to).getTime(), end) : end;
+            if (from >= to) {
+                return
+            }
+            const band = document.createElement("div");
+            band.className = "upgrade-phase";
+            band.style.left = (chart.leftMargin() + (from - start) / (end - start) * plotWidth) + "px";
+            band.style.width = ((to - from) / (end - start) * plotWidth) + "px";
+            band.textContent = item.locator.substring("upgrade/".length);
+            el.appendChild(band);
+        });
+    }
+
     function renderChart(regex) {
         var loc = window.location.href;
 
@@ -49652,6 +49702,7 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
 
         const el = document.querySelector('#chart');
         const myChart = TimelinesChart();
+        const initialZoom = [new Date(eventIntervals.items[0].from), new Date(eventIntervals.items[eventIntervals.items.length - 1].to)];
         var ordinalScale = d3.scaleOrdinal()
             .domain([
                 'AlertInfo', 'AlertPending', 'AlertWarning', 'AlertCritical', // alerts
@@ -49677,13 +49728,18 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
         maxLineHeight(20).
         maxHeight(10000).
         zColorScale(ordinalScale).
-        zoomX([new Date(eventIntervals.items[0].from), new Date(eventIntervals.items[eventIntervals.items.length - 1].to)]).
+        zoomX(initialZoom).
+        onZoom((zoomX) => drawUpgradePhases(myChart, zoomX || initialZoom)).
         onSegmentClick(segmentFunc)
         (el);
+        drawUpgradePhases(myChart, initialZoom)
 
 
         // force a minimum width for smaller devices (which otherwise get an unusable display)
-        setTimeout(() => { if (myChart.width() < 3100) { myChart.width(3100) }}, 1)
+        setTimeout(() => {
+            if (myChart.width() < 3100) { myChart.width(3100) }
+            drawUpgradePhases(myChart, myChart.zoomX() || initialZoom)
+        }, 1)
     }
 
     renderChart(null)